@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/undercode99/article_service/config"
+	"github.com/undercode99/article_service/internal/app/article/articleimpl"
+	"github.com/undercode99/article_service/internal/caching"
+	"github.com/undercode99/article_service/internal/database"
+	"github.com/undercode99/article_service/internal/searching"
+)
+
+const usage = `Usage: cli <command> [flags]
+
+Commands:
+  reindex    rebuild the article index from the database and swap the alias to it
+  verify     check the article index and cache against the database
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	ctx := context.Background()
+	cfg := config.NewConfig()
+
+	// both commands work on the Elasticsearch index
+	if cfg.SearchBackendIsPostgres() {
+		log.Fatalf("%s requires the %s search backend, SEARCH_BACKEND is %s", os.Args[1], config.SearchBackendElasticsearch, cfg.SearchBackend)
+	}
+
+	switch os.Args[1] {
+	case "reindex":
+		runReindex(ctx, cfg, os.Args[2:])
+	case "verify":
+		runVerify(ctx, cfg, os.Args[2:])
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+}
+
+// runReindex runs the reindex command.
+//
+// It streams every article from the database into a new versioned index and
+// points the article alias at it once it is complete.
+func runReindex(ctx context.Context, cfg *config.Config, args []string) {
+	flags := flag.NewFlagSet("reindex", flag.ExitOnError)
+	batchSize := flags.Int("batch-size", 500, "number of articles read and written per batch")
+	deleteOld := flags.Bool("delete-old", false, "delete the indices the alias pointed at before")
+	flags.Parse(args)
+
+	db := database.NewDatabase(cfg)
+	elasticClient := searching.NewElasticClient(ctx, cfg)
+
+	reindexer := articleimpl.NewArticleReindexer(db, elasticClient, cfg)
+	result, err := reindexer.Reindex(ctx, *batchSize, *deleteOld)
+	if err != nil {
+		log.Fatalf("failed to reindex: %v", err)
+	}
+
+	log.Printf("Reindexed %d articles into %s (%d caught up, %d purged), previous indices: %v",
+		result.Indexed, result.Index, result.CaughtUp, result.Purged, result.PreviousIndices)
+}
+
+// runVerify runs the verify command.
+//
+// It prints the consistency report as JSON and exits with status 1 if problems
+// were found and not repaired.
+func runVerify(ctx context.Context, cfg *config.Config, args []string) {
+	flags := flag.NewFlagSet("verify", flag.ExitOnError)
+	batchSize := flags.Int("batch-size", 1000, "number of entries read per batch from each store")
+	repair := flags.Bool("repair", false, "re-index the broken index entries and evict the broken cache entries")
+	flags.Parse(args)
+
+	db := database.NewDatabase(cfg)
+	elasticClient := searching.NewElasticClient(ctx, cfg)
+	redisClient := caching.NewRedisCaching(ctx, cfg)
+
+	checker := articleimpl.NewArticleConsistencyChecker(db, elasticClient, redisClient, articleimpl.NewArticleOutboxRepository(db), cfg)
+	report, err := checker.Check(ctx, *batchSize, *repair)
+	if err != nil {
+		log.Fatalf("failed to verify: %v", err)
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(report); err != nil {
+		log.Fatalf("failed to write report: %v", err)
+	}
+
+	if !report.IsConsistent() && !report.Repaired {
+		os.Exit(1)
+	}
+}
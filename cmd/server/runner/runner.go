@@ -2,20 +2,27 @@ package runner
 
 import (
 	"context"
+	"errors"
 	"log"
+	"time"
 
 	"github.com/elastic/go-elasticsearch/v8"
+	"github.com/undercode99/article_service/config"
 	"github.com/undercode99/article_service/internal/api"
 	"github.com/undercode99/article_service/internal/app/article"
+	"github.com/undercode99/article_service/internal/app/article/articleimpl"
 	"github.com/undercode99/article_service/internal/database"
 	"github.com/undercode99/article_service/internal/searching"
 	"gorm.io/gorm"
 )
 
 type AppRunner struct {
-	db            *gorm.DB
-	apiService    *api.ApiService
-	elasticClient *elasticsearch.TypedClient
+	db                      *gorm.DB
+	apiService              *api.ApiService
+	elasticClient           *elasticsearch.TypedClient
+	articleService          article.ArticleService
+	articleOutboxDispatcher article.ArticleOutboxDispatcher
+	cfg                     *config.Config
 }
 
 // NewAppRunner initializes and returns an instance of the AppRunner struct.
@@ -24,21 +31,38 @@ type AppRunner struct {
 // - db: a pointer to a gorm.DB object, the database connection.
 // - apiService: a pointer to an api.ApiService object, the API service.
 // - elasticClient: a pointer to an elasticsearch.TypedClient object, the Elasticsearch client.
+// - articleService: an article.ArticleService, used by the background jobs.
+// - articleOutboxDispatcher: an article.ArticleOutboxDispatcher, propagates changes to the search index.
+// - cfg: a pointer to a config.Config object, the application configuration.
 //
 // Returns:
 // - a pointer to an AppRunner object.
-func NewAppRunner(db *gorm.DB, apiService *api.ApiService, elasticClient *elasticsearch.TypedClient) *AppRunner {
+func NewAppRunner(
+	db *gorm.DB,
+	apiService *api.ApiService,
+	elasticClient *elasticsearch.TypedClient,
+	articleService article.ArticleService,
+	articleOutboxDispatcher article.ArticleOutboxDispatcher,
+	cfg *config.Config,
+) *AppRunner {
 	return &AppRunner{
-		db:            db,
-		apiService:    apiService,
-		elasticClient: elasticClient,
+		db:                      db,
+		apiService:              apiService,
+		elasticClient:           elasticClient,
+		articleService:          articleService,
+		articleOutboxDispatcher: articleOutboxDispatcher,
+		cfg:                     cfg,
 	}
 }
 
 // Migrate migrates the database and creates an index in Elasticsearch.
 //
+// The full-text index of the database is always created, it serves the postgres
+// search backend and the fallback while Elasticsearch is unavailable. The index in
+// Elasticsearch is created in the background, retrying until Elasticsearch is reachable.
+//
 // ctx - The context of the function.
-// Returns an error if the migration or index creation fails.
+// Returns an error if the migration of the database fails.
 func (a *AppRunner) Migrate(ctx context.Context) {
 	log.Println("Migrating...")
 	err := database.MigrateDatabase(a.db)
@@ -46,17 +70,107 @@ func (a *AppRunner) Migrate(ctx context.Context) {
 		log.Fatalf("failed to migrate: %v", err)
 	}
 
-	// create index
-	err = searching.CreateIndexElastic(ctx, a.elasticClient, article.IndexName)
+	err = articleimpl.MigrateArticleSearchIndex(a.db, a.cfg)
+	if err != nil {
+		log.Fatalf("failed to create search index: %v", err)
+	}
+
+	err = articleimpl.MigrateArticleSlugs(a.db)
 	if err != nil {
-		log.Fatalf("failed to create index: %v", err)
+		log.Fatalf("failed to migrate article slugs: %v", err)
+	}
+
+	if a.cfg.SearchBackendIsPostgres() {
+		return
+	}
+
+	go a.migrateIndexElastic(ctx)
+}
+
+// migrateIndexElastic creates the index in Elasticsearch, retrying until it succeeds or the context is cancelled.
+//
+// If the index already exists but was created with another version of the mapping,
+// a warning is logged, the index has to be rebuilt with the reindex command.
+func (a *AppRunner) migrateIndexElastic(ctx context.Context) {
+	mapping := articleimpl.NewArticleIndexMapping(a.cfg.Article.SearchAnalyzer)
+
+	for {
+		err := searching.CreateIndexElastic(ctx, a.elasticClient, article.IndexName, mapping)
+		if err == nil {
+			err = searching.CheckIndexMapping(ctx, a.elasticClient, article.IndexName, mapping)
+			if errors.Is(err, searching.ErrIndexMappingMismatch) {
+				log.Printf("WARNING: %v, rebuild the index with `cli reindex`", err)
+				return
+			}
+		}
+		if err == nil {
+			return
+		}
+
+		log.Printf("failed to create index, retrying in %s: %v", a.cfg.Search.ConnectRetryInterval, err)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(a.cfg.Search.ConnectRetryInterval):
+		}
+	}
+}
+
+// PurgeDeletedArticles periodically purges the articles deleted longer than the retention ago.
+//
+// It blocks until the context is cancelled.
+func (a *AppRunner) PurgeDeletedArticles(ctx context.Context) {
+	ticker := time.NewTicker(a.cfg.Article.PurgeInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			purged, err := a.articleService.PurgeDeletedArticles(ctx, a.cfg.Article.DeletedRetention)
+			if err != nil {
+				log.Printf("failed to purge deleted articles: %v", err)
+				continue
+			}
+			if purged > 0 {
+				log.Printf("Purged %d deleted articles", purged)
+			}
+		}
+	}
+}
+
+// PublishScheduledArticles periodically publishes the articles whose scheduled publish time has come.
+//
+// It blocks until the context is cancelled.
+func (a *AppRunner) PublishScheduledArticles(ctx context.Context) {
+	ticker := time.NewTicker(a.cfg.Article.PublishInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			published, err := a.articleService.PublishScheduledArticles(ctx)
+			if err != nil {
+				log.Printf("failed to publish scheduled articles: %v", err)
+				continue
+			}
+			if published > 0 {
+				log.Printf("Published %d scheduled articles", published)
+			}
+		}
 	}
 }
 
 // Run runs the AppRunner.
 //
-// It migrates the context and runs the apiService.
+// It migrates the context, starts the background jobs and runs the apiService.
 func (a *AppRunner) Run(ctx context.Context) {
 	a.Migrate(ctx)
+	go a.PurgeDeletedArticles(ctx)
+	go a.PublishScheduledArticles(ctx)
+	go a.articleOutboxDispatcher.Run(ctx)
 	a.apiService.Run(ctx)
 }
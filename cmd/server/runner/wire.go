@@ -26,14 +26,19 @@ var appSet = wire.NewSet(
 
 var repositorySet = wire.NewSet(
 	appSet,
-	articleimpl.NewArticleQueryRepository,
+	articleimpl.NewArticleQueryRepositoryForBackend,
 	articleimpl.NewArticleCommandRepository,
-	articleimpl.NewArticleCachingRepository,
+	articleimpl.NewArticleTieredCachingRepository,
+	articleimpl.NewArticleOutboxRepository,
+	articleimpl.NewArticleRevisionRepository,
+	articleimpl.NewArticleSlugRepository,
+	articleimpl.NewArticleTaxonomyRepository,
 )
 
 var serviceSet = wire.NewSet(
 	repositorySet,
 	articleimpl.NewArticleService,
+	articleimpl.NewArticleOutboxDispatcher,
 )
 
 func InitializeApp(ctx context.Context) *AppRunner {
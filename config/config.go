@@ -3,14 +3,50 @@ package config
 import (
 	"os"
 	"strconv"
+	"time"
+)
+
+// SearchBackendElasticsearch and SearchBackendPostgres are the backends the articles can be searched with.
+const (
+	SearchBackendElasticsearch = "elasticsearch"
+	SearchBackendPostgres      = "postgres"
+)
+
+// CacheFormatJSON and CacheFormatMsgpack are the formats the cache entries can be serialized in.
+const (
+	CacheFormatJSON    = "json"
+	CacheFormatMsgpack = "msgpack"
+)
+
+// CacheCompressionNone, CacheCompressionGzip and CacheCompressionZstd are the compressions of the large cache entries.
+const (
+	CacheCompressionNone = "none"
+	CacheCompressionGzip = "gzip"
+	CacheCompressionZstd = "zstd"
 )
 
 type Config struct {
 	AppPort    string
 	AappMode   string
 	ElasticUrl string
-	Cache      *RedisConfig
-	Database   *DatabaseConfig
+	// SearchBackend is the backend the articles are searched with, SearchBackendElasticsearch
+	// or SearchBackendPostgres. Elasticsearch is not used at all with SearchBackendPostgres.
+	SearchBackend string
+	Search        *SearchConfig
+	Cache         *RedisConfig
+	LocalCache    *LocalCacheConfig
+	Database      *DatabaseConfig
+	Article       *ArticleConfig
+	Outbox        *OutboxConfig
+}
+
+type SearchConfig struct {
+	// BreakerThreshold is the number of consecutive Elasticsearch failures that open the circuit breaker.
+	BreakerThreshold int
+	// BreakerCooldown is how long the circuit breaker stays open before Elasticsearch is tried again.
+	BreakerCooldown time.Duration
+	// ConnectRetryInterval is how often the creation of the index is retried while Elasticsearch is unreachable.
+	ConnectRetryInterval time.Duration
 }
 
 type RedisConfig struct {
@@ -18,34 +54,132 @@ type RedisConfig struct {
 	Port     string
 	Password string
 	DB       int
+
+	// Namespace prefixes every key, so the environments or tenants sharing a Redis do not collide.
+	Namespace string
+	// ArticleTTL is how long an article is cached, NotFoundTTL how long a missing article is.
+	ArticleTTL  time.Duration
+	NotFoundTTL time.Duration
+	// RelatedTTL and ListTTL are how long the related articles and the lists of articles are cached.
+	RelatedTTL time.Duration
+	ListTTL    time.Duration
+	// Format is the serialization of the entries, CacheFormatJSON or CacheFormatMsgpack.
+	Format string
+	// Compression is the compression of the entries of at least CompressionMinSize bytes,
+	// CacheCompressionNone, CacheCompressionGzip or CacheCompressionZstd.
+	Compression        string
+	CompressionMinSize int
+}
+
+// LocalCacheConfig configures the in-process cache of the articles in front of Redis.
+type LocalCacheConfig struct {
+	// Size is the number of articles kept in each replica, 0 disables the in-process cache.
+	Size int
+	// TTL bounds how long a replica may serve an article changed elsewhere if it missed the invalidation.
+	TTL time.Duration
 }
 
 type DatabaseConfig struct {
 	Dsn string
 }
 
+type ArticleConfig struct {
+	// DeletedRetention is how long a deleted article stays recoverable before it is purged.
+	DeletedRetention time.Duration
+	// PurgeInterval is how often deleted articles past their retention are purged.
+	PurgeInterval time.Duration
+	// PublishInterval is how often the articles whose scheduled publish time has come are published.
+	PublishInterval time.Duration
+	// SearchAnalyzer is the Elasticsearch analyzer of the title and body, e.g. "standard" or "english".
+	SearchAnalyzer string
+	// SearchTextConfig is the Postgres text search configuration of the title and body, e.g. "simple" or "english".
+	SearchTextConfig string
+}
+
 func NewDatabaseConfig() *DatabaseConfig {
 	return &DatabaseConfig{
 		Dsn: getEnvString("DATABASE_DSN", ""),
 	}
 }
 
+func NewSearchConfig() *SearchConfig {
+	return &SearchConfig{
+		BreakerThreshold:     getEnvInt("SEARCH_BREAKER_THRESHOLD", 5),
+		BreakerCooldown:      getEnvDuration("SEARCH_BREAKER_COOLDOWN", 30*time.Second),
+		ConnectRetryInterval: getEnvDuration("SEARCH_CONNECT_RETRY_INTERVAL", 5*time.Second),
+	}
+}
+
 func NewRedisConfig() *RedisConfig {
 	return &RedisConfig{
 		Host:     getEnvString("REDIS_HOST", "localhost"),
 		Port:     getEnvString("REDIS_PORT", "6379"),
 		Password: getEnvString("REDIS_PASSWORD", ""),
 		DB:       getEnvInt("REDIS_DB", 0),
+
+		Namespace:          getEnvString("REDIS_NAMESPACE", ""),
+		ArticleTTL:         getEnvDuration("REDIS_ARTICLE_TTL", 20*time.Hour),
+		NotFoundTTL:        getEnvDuration("REDIS_NOT_FOUND_TTL", time.Minute),
+		RelatedTTL:         getEnvDuration("REDIS_RELATED_TTL", 10*time.Minute),
+		ListTTL:            getEnvDuration("REDIS_LIST_TTL", 30*time.Second),
+		Format:             getEnvString("REDIS_FORMAT", CacheFormatJSON),
+		Compression:        getEnvString("REDIS_COMPRESSION", CacheCompressionNone),
+		CompressionMinSize: getEnvInt("REDIS_COMPRESSION_MIN_SIZE", 1024),
+	}
+}
+
+func NewLocalCacheConfig() *LocalCacheConfig {
+	return &LocalCacheConfig{
+		Size: getEnvInt("LOCAL_CACHE_SIZE", 1000),
+		TTL:  getEnvDuration("LOCAL_CACHE_TTL", 30*time.Second),
+	}
+}
+
+type OutboxConfig struct {
+	// PollInterval is how often the dispatcher looks for pending outbox records.
+	PollInterval time.Duration
+	// BatchSize is the maximum number of records dispatched per poll.
+	BatchSize int
+	// MaxAttempts is the number of attempts before a record is moved to the dead-letter state.
+	MaxAttempts int
+	// RetryBackoff is the delay before the first retry, it doubles with every attempt.
+	RetryBackoff time.Duration
+	// RetryMaxBackoff caps the delay between retries.
+	RetryMaxBackoff time.Duration
+}
+
+func NewArticleConfig() *ArticleConfig {
+	return &ArticleConfig{
+		DeletedRetention: getEnvDuration("ARTICLE_DELETED_RETENTION", 30*24*time.Hour),
+		PurgeInterval:    getEnvDuration("ARTICLE_PURGE_INTERVAL", time.Hour),
+		PublishInterval:  getEnvDuration("ARTICLE_PUBLISH_INTERVAL", time.Minute),
+		SearchAnalyzer:   getEnvString("ARTICLE_SEARCH_ANALYZER", "standard"),
+		SearchTextConfig: getEnvString("ARTICLE_SEARCH_TEXT_CONFIG", "simple"),
+	}
+}
+
+func NewOutboxConfig() *OutboxConfig {
+	return &OutboxConfig{
+		PollInterval:    getEnvDuration("OUTBOX_POLL_INTERVAL", time.Second),
+		BatchSize:       getEnvInt("OUTBOX_BATCH_SIZE", 100),
+		MaxAttempts:     getEnvInt("OUTBOX_MAX_ATTEMPTS", 10),
+		RetryBackoff:    getEnvDuration("OUTBOX_RETRY_BACKOFF", time.Second),
+		RetryMaxBackoff: getEnvDuration("OUTBOX_RETRY_MAX_BACKOFF", 10*time.Minute),
 	}
 }
 
 func NewConfig() *Config {
 	return &Config{
-		AppPort:    getEnvString("APP_PORT", "8080"),
-		AappMode:   getEnvString("APP_MODE", "development"),
-		ElasticUrl: getEnvString("ELASTIC_URL", "http://localhost:9200"),
-		Cache:      NewRedisConfig(),
-		Database:   NewDatabaseConfig(),
+		AppPort:       getEnvString("APP_PORT", "8080"),
+		AappMode:      getEnvString("APP_MODE", "development"),
+		ElasticUrl:    getEnvString("ELASTIC_URL", "http://localhost:9200"),
+		SearchBackend: getEnvString("SEARCH_BACKEND", SearchBackendElasticsearch),
+		Search:        NewSearchConfig(),
+		Cache:         NewRedisConfig(),
+		LocalCache:    NewLocalCacheConfig(),
+		Database:      NewDatabaseConfig(),
+		Article:       NewArticleConfig(),
+		Outbox:        NewOutboxConfig(),
 	}
 }
 
@@ -53,6 +187,11 @@ func (c *Config) AppModeIsProduction() bool {
 	return c.AappMode == "production"
 }
 
+// SearchBackendIsPostgres reports whether the articles are searched in Postgres instead of Elasticsearch.
+func (c *Config) SearchBackendIsPostgres() bool {
+	return c.SearchBackend == SearchBackendPostgres
+}
+
 func getEnvString(key, fallback string) string {
 	if value, ok := os.LookupEnv(key); ok {
 		return value
@@ -70,3 +209,14 @@ func getEnvInt(key string, fallback int) int {
 	}
 	return fallback
 }
+
+func getEnvDuration(key string, fallback time.Duration) time.Duration {
+	if value, ok := os.LookupEnv(key); ok {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return fallback
+		}
+		return d
+	}
+	return fallback
+}
@@ -0,0 +1,53 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/undercode99/article_service/internal/app/article"
+)
+
+// RequireEditor lets only the editors through to the admin routes, the other requests are aborted.
+func (h *ApiHandler) RequireEditor(c *gin.Context) {
+	if !h.requireUser(c) {
+		c.Abort()
+		return
+	}
+	if !requestActor(c).IsEditor() {
+		h.withResponseErrorStatus(c, article.ErrOutboxForbidden, http.StatusForbidden)
+		c.Abort()
+		return
+	}
+	c.Next()
+}
+
+func (h *ApiHandler) GetOutboxStats(c *gin.Context) {
+	stats, err := h.articleService.GetOutboxStats(c)
+	if err != nil {
+		h.withResponseError(c, err)
+		return
+	}
+
+	h.withResponse(c, stats)
+}
+
+func (h *ApiHandler) RetryOutbox(c *gin.Context) {
+	idInt, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		h.withResponseErrorStatus(c, err, http.StatusBadRequest)
+		return
+	}
+
+	err = h.articleService.RetryOutbox(c, idInt)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if err == article.ErrOutboxNotFound {
+			status = http.StatusNotFound
+		}
+		h.withResponseErrorStatus(c, err, status)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
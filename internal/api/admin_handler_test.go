@@ -0,0 +1,98 @@
+package api_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/undercode99/article_service/internal/api"
+)
+
+func TestApiHandler_GetOutboxStats(t *testing.T) {
+	apiHandler := api.NewApiHandler(&mockArticleService{})
+
+	r := gin.Default()
+	r.GET("/v1/admin/outbox", apiHandler.RequireEditor, apiHandler.GetOutboxStats)
+
+	req, _ := http.NewRequest("GET", "/v1/admin/outbox", nil)
+	req.Header.Set("X-User", "jane")
+	req.Header.Set("X-User-Role", "admin")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	bodyResultMap := make(map[string]interface{})
+	json.Unmarshal(w.Body.Bytes(), &bodyResultMap)
+
+	assert.Equal(t, float64(3), bodyResultMap["pending"])
+	assert.Equal(t, float64(1), bodyResultMap["retrying"])
+	assert.Equal(t, float64(1), bodyResultMap["dead"])
+}
+
+func TestApiHandler_RetryOutbox(t *testing.T) {
+	apiHandler := api.NewApiHandler(&mockArticleService{})
+
+	r := gin.Default()
+	r.POST("/v1/admin/outbox/:id/retry", apiHandler.RequireEditor, apiHandler.RetryOutbox)
+
+	t.Run("Successful retry", func(t *testing.T) {
+		req, _ := http.NewRequest("POST", "/v1/admin/outbox/1/retry", nil)
+		req.Header.Set("X-User", "jane")
+		req.Header.Set("X-User-Role", "editor")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNoContent, w.Code)
+	})
+
+	t.Run("Record not found", func(t *testing.T) {
+		req, _ := http.NewRequest("POST", "/v1/admin/outbox/2/retry", nil)
+		req.Header.Set("X-User", "jane")
+		req.Header.Set("X-User-Role", "editor")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+// TestApiHandler_RequireEditor tests the admin routes are restricted to the editors.
+//
+// It checks a request without a user is unauthorized and a request of an author forbidden.
+func TestApiHandler_RequireEditor(t *testing.T) {
+	apiHandler := api.NewApiHandler(&mockArticleService{})
+
+	r := gin.Default()
+	r.GET("/v1/admin/outbox", apiHandler.RequireEditor, apiHandler.GetOutboxStats)
+	r.POST("/v1/admin/outbox/:id/retry", apiHandler.RequireEditor, apiHandler.RetryOutbox)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		user   string
+		role   string
+		status int
+	}{
+		{name: "Stats without user", method: "GET", path: "/v1/admin/outbox", status: http.StatusUnauthorized},
+		{name: "Stats of an author", method: "GET", path: "/v1/admin/outbox", user: "jhon", status: http.StatusForbidden},
+		{name: "Retry without user", method: "POST", path: "/v1/admin/outbox/1/retry", status: http.StatusUnauthorized},
+		{name: "Retry of an author", method: "POST", path: "/v1/admin/outbox/1/retry", user: "jhon", role: "author", status: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(tt.method, tt.path, nil)
+			req.Header.Set("X-User", tt.user)
+			req.Header.Set("X-User-Role", tt.role)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+		})
+	}
+}
@@ -36,8 +36,36 @@ func (a *ApiService) Run(ctx context.Context) {
 	v1 := r.Group("/v1")
 	{
 		v1.POST("/articles", a.apiHandler.CreateArticle)
+		v1.GET("/articles/suggest", a.apiHandler.SuggestArticles)
+		v1.GET("/articles/by-slug/:slug", a.apiHandler.GetArticleBySlug)
 		v1.GET("/articles/:id", a.apiHandler.GetArticleByID)
+		v1.GET("/articles/:id/related", a.apiHandler.GetRelatedArticles)
+		v1.PUT("/articles/:id", a.apiHandler.UpdateArticle)
+		v1.DELETE("/articles/:id", a.apiHandler.DeleteArticle)
+		v1.POST("/articles/:id/restore", a.apiHandler.RestoreArticle)
+		v1.DELETE("/articles/:id/purge", a.apiHandler.PurgeArticle)
+		v1.GET("/articles/:id/revisions", a.apiHandler.GetArticleRevisions)
+		v1.GET("/articles/:id/revisions/:rev", a.apiHandler.GetArticleRevision)
+		v1.GET("/articles/:id/revisions/:rev/diff", a.apiHandler.GetArticleRevisionDiff)
+		v1.POST("/articles/:id/revisions/:rev/revert", a.apiHandler.RevertArticle)
+		v1.POST("/articles/:id/transitions/:transition", a.apiHandler.TransitionArticle)
 		v1.GET("/articles", a.apiHandler.GetListArticles)
+		v1.GET("/tags", a.apiHandler.GetTags)
+		v1.POST("/tags", a.apiHandler.CreateTag)
+		v1.PUT("/tags/:id", a.apiHandler.UpdateTag)
+		v1.DELETE("/tags/:id", a.apiHandler.DeleteTag)
+		v1.POST("/tags/:id/merge", a.apiHandler.MergeTag)
+		v1.GET("/categories", a.apiHandler.GetCategories)
+		v1.POST("/categories", a.apiHandler.CreateCategory)
+		v1.PUT("/categories/:id", a.apiHandler.UpdateCategory)
+		v1.DELETE("/categories/:id", a.apiHandler.DeleteCategory)
+	}
+
+	// admin routes, restricted to the editors
+	admin := v1.Group("/admin", a.apiHandler.RequireEditor)
+	{
+		admin.GET("/outbox", a.apiHandler.GetOutboxStats)
+		admin.POST("/outbox/:id/retry", a.apiHandler.RetryOutbox)
 	}
 
 	log.Printf("Starting server on port %s", a.cfg.AppPort)
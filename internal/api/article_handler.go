@@ -2,6 +2,8 @@ package api
 
 import (
 	"net/http"
+	"net/url"
+	"path"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
@@ -15,6 +17,7 @@ func (h *ApiHandler) CreateArticle(c *gin.Context) {
 		h.withResponseError(c, err)
 		return
 	}
+	createCmd.Editor = requestEditor(c)
 
 	if err := createCmd.Validate(); err != nil {
 		h.withResponseErrorStatus(c, err, http.StatusBadRequest)
@@ -23,13 +26,153 @@ func (h *ApiHandler) CreateArticle(c *gin.Context) {
 
 	createdArticle, err := h.articleService.CreateArticle(c, &createCmd)
 	if err != nil {
+		if err == article.ErrCategoryNotFound {
+			h.withResponseErrorStatus(c, err, http.StatusBadRequest)
+			return
+		}
 		h.withResponseError(c, err)
 		return
 	}
 
+	h.withETag(c, createdArticle)
 	h.withResponse(c, createdArticle, http.StatusCreated)
 }
 
+func (h *ApiHandler) UpdateArticle(c *gin.Context) {
+	idInt, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		h.withResponseErrorStatus(c, err, http.StatusBadRequest)
+		return
+	}
+
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		h.withResponseErrorStatus(c, err, preconditionStatus(err))
+		return
+	}
+
+	var updateCmd article.ArticleUpdateCommand
+	if err := c.BindJSON(&updateCmd); err != nil {
+		h.withResponseError(c, err)
+		return
+	}
+	updateCmd.ID = idInt
+	updateCmd.Version = version
+	updateCmd.Actor = requestActor(c)
+	updateCmd.Editor = requestEditor(c)
+
+	if err := updateCmd.Validate(); err != nil {
+		h.withResponseErrorStatus(c, err, http.StatusBadRequest)
+		return
+	}
+
+	updatedArticle, err := h.articleService.UpdateArticle(c, &updateCmd)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch err {
+		case article.ErrArticleNotFound:
+			status = http.StatusNotFound
+		case article.ErrArticleVersionMismatch:
+			status = http.StatusPreconditionFailed
+		case article.ErrCategoryNotFound:
+			status = http.StatusBadRequest
+		case article.ErrEditForbidden, article.ErrAuthorForbidden:
+			status = http.StatusForbidden
+		}
+		h.withResponseErrorStatus(c, err, status)
+		return
+	}
+
+	h.withETag(c, updatedArticle)
+	h.withResponse(c, updatedArticle)
+}
+
+func (h *ApiHandler) DeleteArticle(c *gin.Context) {
+	idInt, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		h.withResponseErrorStatus(c, err, http.StatusBadRequest)
+		return
+	}
+
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		h.withResponseErrorStatus(c, err, preconditionStatus(err))
+		return
+	}
+
+	err = h.articleService.DeleteArticle(c, &article.ArticleDeleteCommand{ID: idInt, Actor: requestActor(c), Version: version})
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch err {
+		case article.ErrArticleNotFound:
+			status = http.StatusNotFound
+		case article.ErrArticleVersionMismatch:
+			status = http.StatusPreconditionFailed
+		case article.ErrDeleteForbidden:
+			status = http.StatusForbidden
+		}
+		h.withResponseErrorStatus(c, err, status)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *ApiHandler) RestoreArticle(c *gin.Context) {
+	idInt, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		h.withResponseErrorStatus(c, err, http.StatusBadRequest)
+		return
+	}
+
+	if !h.requireUser(c) {
+		return
+	}
+
+	restoredArticle, err := h.articleService.RestoreArticle(c, &article.ArticleRestoreCommand{ID: idInt, Actor: requestActor(c)})
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch err {
+		case article.ErrArticleNotFound:
+			status = http.StatusNotFound
+		case article.ErrRestoreForbidden:
+			status = http.StatusForbidden
+		}
+		h.withResponseErrorStatus(c, err, status)
+		return
+	}
+
+	h.withETag(c, restoredArticle)
+	h.withResponse(c, restoredArticle)
+}
+
+func (h *ApiHandler) PurgeArticle(c *gin.Context) {
+	idInt, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		h.withResponseErrorStatus(c, err, http.StatusBadRequest)
+		return
+	}
+
+	if !h.requireUser(c) {
+		return
+	}
+
+	err = h.articleService.PurgeArticle(c, &article.ArticlePurgeCommand{ID: idInt, Actor: requestActor(c)})
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch err {
+		case article.ErrArticleNotFound:
+			status = http.StatusNotFound
+		case article.ErrPurgeForbidden:
+			status = http.StatusForbidden
+		}
+		h.withResponseErrorStatus(c, err, status)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
 func (h *ApiHandler) GetArticleByID(c *gin.Context) {
 	id := c.Param("id")
 
@@ -39,7 +182,7 @@ func (h *ApiHandler) GetArticleByID(c *gin.Context) {
 		return
 	}
 
-	articleItem, err := h.articleService.GetArticleByID(c, idInt)
+	articleItem, err := h.getVisibleArticle(c, idInt)
 	if err != nil {
 		status := http.StatusInternalServerError
 		if err == article.ErrArticleNotFound {
@@ -49,7 +192,33 @@ func (h *ApiHandler) GetArticleByID(c *gin.Context) {
 		return
 	}
 
-	h.withResponse(c, articleItem)
+	h.withArticle(c, articleItem)
+}
+
+// GetArticleBySlug responds with the article of the slug, a former slug of an
+// article is permanently redirected to its current slug.
+func (h *ApiHandler) GetArticleBySlug(c *gin.Context) {
+	slug := c.Param("slug")
+
+	articleItem, err := h.articleService.GetArticleBySlug(c, slug)
+	if err == nil && !requestActor(c).CanView(articleItem) {
+		err = article.ErrArticleNotFound
+	}
+	if err != nil {
+		h.withResponseErrorStatus(c, err, articleErrorStatus(err))
+		return
+	}
+
+	if articleItem.Slug != slug {
+		location := url.URL{
+			Path:     path.Join(path.Dir(c.Request.URL.Path), articleItem.Slug),
+			RawQuery: c.Request.URL.RawQuery,
+		}
+		c.Redirect(http.StatusMovedPermanently, location.String())
+		return
+	}
+
+	h.withArticle(c, articleItem)
 }
 
 func (h *ApiHandler) GetListArticles(c *gin.Context) {
@@ -58,12 +227,128 @@ func (h *ApiHandler) GetListArticles(c *gin.Context) {
 		h.withResponseError(c, err)
 		return
 	}
+	qry.UseCursor = c.Request.URL.Query().Has("cursor")
+
+	if err := qry.Validate(); err != nil {
+		h.withResponseErrorStatus(c, err, http.StatusBadRequest)
+		return
+	}
+
+	if !requestActor(c).CanList(&qry) {
+		h.withResponseErrorStatus(c, article.ErrStatusForbidden, http.StatusForbidden)
+		return
+	}
 
 	articles, err := h.articleService.GetListArticles(c, &qry)
 	if err != nil {
-		h.withResponseError(c, err)
+		status := http.StatusInternalServerError
+		switch err {
+		case article.ErrInvalidCursor:
+			status = http.StatusBadRequest
+		case article.ErrCursorExpired:
+			status = http.StatusGone
+		}
+		h.withResponseErrorStatus(c, err, status)
 		return
 	}
 
+	if qry.UseCursor {
+		h.withCursorLinks(c, articles.NextCursor)
+	} else {
+		h.withPaginationLinks(c, articles.Page, articles.TotalPages)
+	}
+	h.withDegraded(c, articles.Degraded)
 	h.withResponse(c, articles)
 }
+
+func (h *ApiHandler) SuggestArticles(c *gin.Context) {
+	var qry article.ArticleSuggestQuery
+	if err := c.BindQuery(&qry); err != nil {
+		h.withResponseError(c, err)
+		return
+	}
+
+	if err := qry.Validate(); err != nil {
+		h.withResponseErrorStatus(c, err, http.StatusBadRequest)
+		return
+	}
+
+	suggestions, err := h.articleService.GetArticleSuggestions(c, &qry)
+	if err != nil {
+		h.withResponseError(c, err)
+		return
+	}
+
+	h.withDegraded(c, suggestions.Degraded)
+	h.withResponse(c, suggestions)
+}
+
+func (h *ApiHandler) GetRelatedArticles(c *gin.Context) {
+	idInt, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		h.withResponseErrorStatus(c, err, http.StatusBadRequest)
+		return
+	}
+
+	var qry article.ArticleRelatedQuery
+	if err := c.BindQuery(&qry); err != nil {
+		h.withResponseError(c, err)
+		return
+	}
+	qry.ID = idInt
+
+	if _, err := h.getVisibleArticle(c, idInt); err != nil {
+		h.withResponseErrorStatus(c, err, articleErrorStatus(err))
+		return
+	}
+
+	related, err := h.articleService.GetRelatedArticles(c, &qry)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if err == article.ErrArticleNotFound {
+			status = http.StatusNotFound
+		}
+		h.withResponseErrorStatus(c, err, status)
+		return
+	}
+
+	h.withDegraded(c, related.Degraded)
+	h.withResponse(c, related)
+}
+
+// getVisibleArticle returns the article if the user making the request may read it.
+//
+// article.ErrArticleNotFound is returned for an unpublished article of another author,
+// so its existence is not disclosed.
+func (h *ApiHandler) getVisibleArticle(c *gin.Context, id int) (*article.Article, error) {
+	item, err := h.articleService.GetArticleByID(c, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !requestActor(c).CanView(item) {
+		return nil, article.ErrArticleNotFound
+	}
+	return item, nil
+}
+
+// withArticle responds with the article and its ETag, or with 304 Not Modified
+// if the request already has the current version.
+func (h *ApiHandler) withArticle(c *gin.Context, item *article.Article) {
+	// the article may come from the cache, a revalidation does not touch the database
+	h.withETag(c, item)
+	if ifNoneMatch(c, item.ETag()) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	h.withResponse(c, item)
+}
+
+// articleErrorStatus returns the status of an error reading an article.
+func articleErrorStatus(err error) int {
+	if err == article.ErrArticleNotFound {
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
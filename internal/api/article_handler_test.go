@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
@@ -27,32 +28,310 @@ func (m *mockArticleService) CreateArticle(ctx context.Context, cmd *article.Art
 	}, nil
 }
 
+func (m *mockArticleService) UpdateArticle(ctx context.Context, cmd *article.ArticleUpdateCommand) (*article.Article, error) {
+	if cmd.ID != 1 {
+		return nil, article.ErrArticleNotFound
+	}
+	if cmd.Version != 0 && cmd.Version != 2 {
+		return nil, article.ErrArticleVersionMismatch
+	}
+	// the article is a draft of jhon
+	if cmd.Actor.User != "jhon" && !cmd.Actor.IsEditor() {
+		return nil, article.ErrEditForbidden
+	}
+
+	return &article.Article{
+		ID:      cmd.ID,
+		Title:   cmd.Title,
+		Body:    cmd.Body,
+		Author:  cmd.Author,
+		Version: 3,
+	}, nil
+}
+
+func (m *mockArticleService) DeleteArticle(ctx context.Context, cmd *article.ArticleDeleteCommand) error {
+	if cmd.ID != 1 {
+		return article.ErrArticleNotFound
+	}
+	if cmd.Version != 0 && cmd.Version != 2 {
+		return article.ErrArticleVersionMismatch
+	}
+	if cmd.Actor.User == "Jim Doe" {
+		return article.ErrDeleteForbidden
+	}
+	return nil
+}
+
+func (m *mockArticleService) RestoreArticle(ctx context.Context, cmd *article.ArticleRestoreCommand) (*article.Article, error) {
+	if cmd.ID != 1 {
+		return nil, article.ErrArticleNotFound
+	}
+	if cmd.Actor.User == "Jim Doe" {
+		return nil, article.ErrRestoreForbidden
+	}
+	return &article.Article{ID: 1, Title: "Test Article"}, nil
+}
+
+func (m *mockArticleService) RevertArticle(ctx context.Context, cmd *article.ArticleRevertCommand) (*article.Article, error) {
+	if cmd.ID != 1 {
+		return nil, article.ErrArticleNotFound
+	}
+	if cmd.Revision != 1 {
+		return nil, article.ErrRevisionNotFound
+	}
+	if cmd.Version != 0 && cmd.Version != 2 {
+		return nil, article.ErrArticleVersionMismatch
+	}
+	return &article.Article{ID: 1, Title: "Test Article", Body: cmd.Editor + ": " + cmd.Note, Version: 3}, nil
+}
+
+func (m *mockArticleService) GetArticleRevisions(ctx context.Context, id int) (*article.ArticleRevisionsDTO, error) {
+	if id != 1 {
+		return nil, article.ErrArticleNotFound
+	}
+	return &article.ArticleRevisionsDTO{Revisions: []article.ArticleRevision{
+		{ArticleID: 1, Revision: 2, Title: "Test Article", Editor: "jane"},
+		{ArticleID: 1, Revision: 1, Title: "Test", Editor: "john"},
+	}}, nil
+}
+
+func (m *mockArticleService) GetArticleRevision(ctx context.Context, id, revision int) (*article.ArticleRevision, error) {
+	if id != 1 {
+		return nil, article.ErrArticleNotFound
+	}
+	if revision != 1 {
+		return nil, article.ErrRevisionNotFound
+	}
+	return &article.ArticleRevision{ArticleID: 1, Revision: 1, Title: "Test", Body: "Body", Editor: "john"}, nil
+}
+
+func (m *mockArticleService) GetArticleRevisionDiff(ctx context.Context, query *article.ArticleRevisionDiffQuery) (*article.ArticleRevisionDiffDTO, error) {
+	if query.ID != 1 {
+		return nil, article.ErrArticleNotFound
+	}
+	return article.NewArticleRevisionDiffDTO(
+		&article.ArticleRevision{Revision: query.From, Title: "Test"},
+		&article.ArticleRevision{Revision: query.Revision, Title: "Test Article"},
+	), nil
+}
+
+func (m *mockArticleService) PurgeArticle(ctx context.Context, cmd *article.ArticlePurgeCommand) error {
+	if cmd.ID != 1 {
+		return article.ErrArticleNotFound
+	}
+	if !cmd.Actor.IsEditor() {
+		return article.ErrPurgeForbidden
+	}
+	return nil
+}
+
+func (m *mockArticleService) PurgeDeletedArticles(ctx context.Context, retention time.Duration) (int, error) {
+	return 0, nil
+}
+
+func (m *mockArticleService) GetOutboxStats(ctx context.Context) (*article.ArticleOutboxStatsDTO, error) {
+	return &article.ArticleOutboxStatsDTO{Pending: 3, Retrying: 1, Dead: 1}, nil
+}
+
+func (m *mockArticleService) RetryOutbox(ctx context.Context, id int) error {
+	if id != 1 {
+		return article.ErrOutboxNotFound
+	}
+	return nil
+}
+
+func (m *mockArticleService) TransitionArticle(ctx context.Context, cmd *article.ArticleTransitionCommand) (*article.Article, error) {
+	if cmd.ID != 1 {
+		return nil, article.ErrArticleNotFound
+	}
+	if cmd.Version != 0 && cmd.Version != 2 {
+		return nil, article.ErrArticleVersionMismatch
+	}
+
+	item := &article.Article{ID: 1, Title: "Test Article", Author: "Lorem ipsum dolor sit amet", Status: article.StatusInReview, Version: 2}
+	if err := item.Transition(cmd); err != nil {
+		return nil, err
+	}
+	item.Version = 3
+	return item, nil
+}
+
+func (m *mockArticleService) PublishScheduledArticles(ctx context.Context) (int, error) {
+	return 0, nil
+}
+
 func (m *mockArticleService) GetArticleByID(ctx context.Context, id int) (*article.Article, error) {
+	if id == 3 {
+		return &article.Article{ID: 3, Title: "Draft Article", Author: "John Doe", Status: article.StatusDraft, Version: 1}, nil
+	}
 	if id != 1 {
 		return nil, article.ErrArticleNotFound
 	}
 
 	return &article.Article{
-		ID:     1,
-		Title:  "Test Article",
-		Body:   "This is a test article",
-		Author: "Lorem ipsum dolor sit amet",
+		ID:      1,
+		Title:   "Test Article",
+		Body:    "This is a test article",
+		Author:  "Lorem ipsum dolor sit amet",
+		Version: 2,
+		Status:  article.StatusPublished,
+	}, nil
+}
+
+func (m *mockArticleService) GetArticleBySlug(ctx context.Context, slug string) (*article.Article, error) {
+	switch slug {
+	case "test-article", "old-test-article":
+		item, err := m.GetArticleByID(ctx, 1)
+		if err != nil {
+			return nil, err
+		}
+		item.Slug = "test-article"
+		return item, nil
+	case "draft-article":
+		return m.GetArticleByID(ctx, 3)
+	}
+	return nil, article.ErrArticleNotFound
+}
+
+func (m *mockArticleService) GetTags(ctx context.Context) (*article.TagsDTO, error) {
+	return &article.TagsDTO{Tags: []article.Tag{{ID: 1, Name: "golang"}}}, nil
+}
+
+func (m *mockArticleService) CreateTag(ctx context.Context, cmd *article.TagCommand) (*article.Tag, error) {
+	if err := cmd.Validate(); err != nil {
+		return nil, err
+	}
+	tag := article.NewTag(cmd.Name)
+	if tag.Name == "golang" {
+		return nil, article.ErrTagExists
+	}
+	tag.ID = 2
+	return tag, nil
+}
+
+func (m *mockArticleService) UpdateTag(ctx context.Context, cmd *article.TagCommand) (*article.Tag, error) {
+	if err := cmd.Validate(); err != nil {
+		return nil, err
+	}
+	if cmd.ID != 1 {
+		return nil, article.ErrTagNotFound
+	}
+	return &article.Tag{ID: cmd.ID, Name: article.NormalizeTag(cmd.Name)}, nil
+}
+
+func (m *mockArticleService) MergeTag(ctx context.Context, cmd *article.TagMergeCommand) (*article.Tag, error) {
+	if err := cmd.Validate(); err != nil {
+		return nil, err
+	}
+	if cmd.ID != 2 || cmd.Into != 1 {
+		return nil, article.ErrTagNotFound
+	}
+	return &article.Tag{ID: 1, Name: "golang"}, nil
+}
+
+func (m *mockArticleService) DeleteTag(ctx context.Context, id int) error {
+	if id != 1 {
+		return article.ErrTagNotFound
+	}
+	return nil
+}
+
+func (m *mockArticleService) GetCategories(ctx context.Context) (*article.CategoriesDTO, error) {
+	techID := 1
+	return &article.CategoriesDTO{Categories: article.NewCategoryTree([]article.Category{
+		{ID: 1, Name: "Tech", Path: "tech"},
+		{ID: 2, ParentID: &techID, Name: "Golang", Path: "tech/golang"},
+	})}, nil
+}
+
+func (m *mockArticleService) CreateCategory(ctx context.Context, cmd *article.CategoryCommand) (*article.Category, error) {
+	if err := cmd.Validate(); err != nil {
+		return nil, err
+	}
+	var parent *article.Category
+	if cmd.ParentID != nil {
+		if *cmd.ParentID != 1 {
+			return nil, article.ErrCategoryParentNotFound
+		}
+		parent = &article.Category{ID: 1, Name: "Tech", Path: "tech"}
+	}
+	category := article.NewCategory(cmd)
+	if err := category.MoveTo(cmd.Name, parent); err != nil {
+		return nil, err
+	}
+	category.ID = 3
+	return category, nil
+}
+
+func (m *mockArticleService) UpdateCategory(ctx context.Context, cmd *article.CategoryCommand) (*article.Category, error) {
+	if err := cmd.Validate(); err != nil {
+		return nil, err
+	}
+	if cmd.ID != 1 {
+		return nil, article.ErrCategoryNotFound
+	}
+	category := &article.Category{ID: 1, Name: "Tech", Path: "tech"}
+	var parent *article.Category
+	if cmd.ParentID != nil {
+		parent = &article.Category{ID: *cmd.ParentID, Path: "tech/golang"}
+	}
+	if err := category.MoveTo(cmd.Name, parent); err != nil {
+		return nil, err
+	}
+	return category, nil
+}
+
+func (m *mockArticleService) DeleteCategory(ctx context.Context, id int) error {
+	switch id {
+	case 1:
+		return article.ErrCategoryNotEmpty
+	case 2:
+		return nil
+	}
+	return article.ErrCategoryNotFound
+}
+
+func (m *mockArticleService) GetArticleSuggestions(ctx context.Context, query *article.ArticleSuggestQuery) (*article.ArticleSuggestionsDTO, error) {
+	return &article.ArticleSuggestionsDTO{
+		Titles:  []article.TitleSuggestionDTO{{ID: 1, Title: "Test Article"}},
+		Authors: []string{},
+	}, nil
+}
+
+func (m *mockArticleService) GetRelatedArticles(ctx context.Context, query *article.ArticleRelatedQuery) (*article.RelatedArticlesDTO, error) {
+	if query.ID != 1 {
+		return nil, article.ErrArticleNotFound
+	}
+	return &article.RelatedArticlesDTO{
+		Articles: []article.Article{{ID: 2, Title: "Related Article", Author: "Lorem ipsum"}},
 	}, nil
 }
 
 func (m *mockArticleService) GetListArticles(ctx context.Context, query *article.ArticleQuery) (*article.ListArticleDTO, error) {
-	return &article.ListArticleDTO{
-		Articles: []article.Article{
-			{
-				ID:     1,
-				Title:  "Test Article",
-				Body:   "This is a test article",
-				Author: "Lorem ipsum dolor sit amet",
-			},
+	if query.UseCursor {
+		switch query.Cursor {
+		case "":
+			return &article.ListArticleDTO{Limit: 10, Total: 25, HasNext: true, NextCursor: "next"}, nil
+		case "next":
+			return &article.ListArticleDTO{Limit: 10, Total: 25}, nil
+		}
+		return nil, article.ErrInvalidCursor
+	}
+
+	if query.Search == "degraded" {
+		result := article.NewListArticleDTO([]article.Article{}, query.GetPage(), query.GetLimit(), 0)
+		result.Degraded = true
+		return result, nil
+	}
+
+	return article.NewListArticleDTO([]article.Article{
+		{
+			ID:     1,
+			Title:  "Test Article",
+			Body:   "This is a test article",
+			Author: "Lorem ipsum dolor sit amet",
 		},
-		Page:  1,
-		Limit: 10,
-	}, nil
+	}, query.GetPage(), query.GetLimit(), 25), nil
 }
 
 func TestApiHandler_CreateArticle(t *testing.T) {
@@ -103,6 +382,173 @@ func TestApiHandler_CreateArticle(t *testing.T) {
 	})
 }
 
+func TestApiHandler_UpdateArticle(t *testing.T) {
+	// Test case: successful update of an article
+	t.Run("Successful update", func(t *testing.T) {
+		apiHandler := api.NewApiHandler(&mockArticleService{})
+
+		r := gin.Default()
+		r.PUT("/v1/articles/:id", apiHandler.UpdateArticle)
+
+		payload := `{"title": "New Title", "body": "New body", "author": "jhon"}`
+		req, _ := http.NewRequest("PUT", "/v1/articles/1", bytes.NewBufferString(payload))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("If-Match", `"2"`)
+		req.Header.Set("X-User", "jhon")
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, `"3"`, w.Header().Get("ETag"))
+
+		bodyResultMap := make(map[string]interface{})
+		json.Unmarshal(w.Body.Bytes(), &bodyResultMap)
+
+		assert.Equal(t, float64(1), bodyResultMap["id"])
+		assert.Equal(t, float64(3), bodyResultMap["version"])
+		assert.Equal(t, "New Title", bodyResultMap["title"])
+		assert.Equal(t, "New body", bodyResultMap["body"])
+	})
+
+	t.Run("Invalid payload", func(t *testing.T) {
+		apiHandler := api.NewApiHandler(&mockArticleService{})
+
+		r := gin.Default()
+		r.PUT("/v1/articles/:id", apiHandler.UpdateArticle)
+
+		payload := `{"title": "New Title", "author": "jhon"}`
+		req, _ := http.NewRequest("PUT", "/v1/articles/1", bytes.NewBufferString(payload))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("If-Match", `"2"`)
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Article not found", func(t *testing.T) {
+		apiHandler := api.NewApiHandler(&mockArticleService{})
+
+		r := gin.Default()
+		r.PUT("/v1/articles/:id", apiHandler.UpdateArticle)
+
+		payload := `{"title": "New Title", "body": "New body", "author": "jhon"}`
+		req, _ := http.NewRequest("PUT", "/v1/articles/2", bytes.NewBufferString(payload))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("If-Match", "*")
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("Preconditions", func(t *testing.T) {
+		apiHandler := api.NewApiHandler(&mockArticleService{})
+
+		r := gin.Default()
+		r.PUT("/v1/articles/:id", apiHandler.UpdateArticle)
+
+		tests := []struct {
+			name    string
+			ifMatch string
+			status  int
+		}{
+			{name: "Missing If-Match", ifMatch: "", status: http.StatusPreconditionRequired},
+			{name: "Version mismatch", ifMatch: `"1"`, status: http.StatusPreconditionFailed},
+			{name: "Weak ETag", ifMatch: `W/"2"`, status: http.StatusPreconditionFailed},
+			{name: "Any version", ifMatch: "*", status: http.StatusOK},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				payload := `{"title": "New Title", "body": "New body", "author": "jhon"}`
+				req, _ := http.NewRequest("PUT", "/v1/articles/1", bytes.NewBufferString(payload))
+				req.Header.Set("Content-Type", "application/json")
+				req.Header.Set("X-User", "jhon")
+				if tt.ifMatch != "" {
+					req.Header.Set("If-Match", tt.ifMatch)
+				}
+
+				w := httptest.NewRecorder()
+				r.ServeHTTP(w, req)
+
+				assert.Equal(t, tt.status, w.Code)
+			})
+		}
+	})
+
+	t.Run("Update forbidden", func(t *testing.T) {
+		apiHandler := api.NewApiHandler(&mockArticleService{})
+
+		r := gin.Default()
+		r.PUT("/v1/articles/:id", apiHandler.UpdateArticle)
+
+		payload := `{"title": "New Title", "body": "New body", "author": "jhon"}`
+		req, _ := http.NewRequest("PUT", "/v1/articles/1", bytes.NewBufferString(payload))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("If-Match", `"2"`)
+		req.Header.Set("X-User", "Jim Doe")
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
+
+func TestApiHandler_DeleteArticle(t *testing.T) {
+	apiHandler := api.NewApiHandler(&mockArticleService{})
+
+	r := gin.Default()
+	r.DELETE("/v1/articles/:id", apiHandler.DeleteArticle)
+	r.POST("/v1/articles/:id/restore", apiHandler.RestoreArticle)
+	r.DELETE("/v1/articles/:id/purge", apiHandler.PurgeArticle)
+
+	tests := []struct {
+		name    string
+		method  string
+		path    string
+		ifMatch string
+		user    string
+		role    string
+		status  int
+	}{
+		{name: "Successful delete", method: "DELETE", path: "/v1/articles/1", ifMatch: `"2"`, status: http.StatusNoContent},
+		{name: "Delete not found", method: "DELETE", path: "/v1/articles/2", ifMatch: "*", status: http.StatusNotFound},
+		{name: "Delete invalid id", method: "DELETE", path: "/v1/articles/abc", ifMatch: "*", status: http.StatusBadRequest},
+		{name: "Delete without If-Match", method: "DELETE", path: "/v1/articles/1", status: http.StatusPreconditionRequired},
+		{name: "Delete version mismatch", method: "DELETE", path: "/v1/articles/1", ifMatch: `"1"`, status: http.StatusPreconditionFailed},
+		{name: "Delete forbidden", method: "DELETE", path: "/v1/articles/1", ifMatch: `"2"`, user: "Jim Doe", status: http.StatusForbidden},
+		{name: "Successful restore", method: "POST", path: "/v1/articles/1/restore", user: "jhon", status: http.StatusOK},
+		{name: "Restore not found", method: "POST", path: "/v1/articles/2/restore", user: "jhon", status: http.StatusNotFound},
+		{name: "Restore without user", method: "POST", path: "/v1/articles/1/restore", status: http.StatusUnauthorized},
+		{name: "Restore forbidden", method: "POST", path: "/v1/articles/1/restore", user: "Jim Doe", status: http.StatusForbidden},
+		{name: "Successful purge", method: "DELETE", path: "/v1/articles/1/purge", user: "jane", role: "editor", status: http.StatusNoContent},
+		{name: "Purge not found", method: "DELETE", path: "/v1/articles/2/purge", user: "jane", role: "editor", status: http.StatusNotFound},
+		{name: "Purge without user", method: "DELETE", path: "/v1/articles/1/purge", status: http.StatusUnauthorized},
+		{name: "Purge forbidden", method: "DELETE", path: "/v1/articles/1/purge", user: "jhon", status: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(tt.method, tt.path, nil)
+			req.Header.Set("X-User", tt.user)
+			req.Header.Set("X-User-Role", tt.role)
+			if tt.ifMatch != "" {
+				req.Header.Set("If-Match", tt.ifMatch)
+			}
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+		})
+	}
+}
+
 func TestApiHandler_GetArticleByID(t *testing.T) {
 	// Test case: successful retrieval of an article
 	t.Run("Successful retrieval", func(t *testing.T) {
@@ -121,6 +567,32 @@ func TestApiHandler_GetArticleByID(t *testing.T) {
 		r.ServeHTTP(w, req)
 
 		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, `"2"`, w.Header().Get("ETag"))
+	})
+
+	t.Run("Not modified", func(t *testing.T) {
+		apiHandler := api.NewApiHandler(&mockArticleService{})
+
+		r := gin.Default()
+		r.GET("/v1/articles/:id", apiHandler.GetArticleByID)
+
+		for ifNoneMatch, status := range map[string]int{
+			`"2"`:        http.StatusNotModified,
+			`"1", W/"2"`: http.StatusNotModified,
+			`"1"`:        http.StatusOK,
+		} {
+			req, _ := http.NewRequest("GET", "/v1/articles/1", nil)
+			req.Header.Set("If-None-Match", ifNoneMatch)
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, status, w.Code, ifNoneMatch)
+			assert.Equal(t, `"2"`, w.Header().Get("ETag"))
+			if status == http.StatusNotModified {
+				assert.Empty(t, w.Body.String())
+			}
+		}
 	})
 
 	t.Run("Unsuccessful retrieval", func(t *testing.T) {
@@ -141,4 +613,328 @@ func TestApiHandler_GetArticleByID(t *testing.T) {
 		assert.Equal(t, http.StatusNotFound, w.Code)
 
 	})
+
+	t.Run("Unpublished article", func(t *testing.T) {
+		apiHandler := api.NewApiHandler(&mockArticleService{})
+
+		r := gin.Default()
+		r.GET("/v1/articles/:id", apiHandler.GetArticleByID)
+
+		tests := []struct {
+			name   string
+			user   string
+			role   string
+			status int
+		}{
+			{name: "Anonymous", status: http.StatusNotFound},
+			{name: "Other author", user: "Jane Doe", status: http.StatusNotFound},
+			{name: "Author", user: "John Doe", status: http.StatusOK},
+			{name: "Editor", user: "Jane Doe", role: "Editor", status: http.StatusOK},
+		}
+
+		for _, tt := range tests {
+			req, _ := http.NewRequest("GET", "/v1/articles/3", nil)
+			req.Header.Set("X-User", tt.user)
+			req.Header.Set("X-User-Role", tt.role)
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.status, w.Code, tt.name)
+		}
+	})
+}
+
+func TestApiHandler_GetArticleBySlug(t *testing.T) {
+	apiHandler := api.NewApiHandler(&mockArticleService{})
+
+	r := gin.Default()
+	r.GET("/v1/articles/by-slug/:slug", apiHandler.GetArticleBySlug)
+
+	t.Run("Current slug", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/v1/articles/by-slug/test-article", nil)
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, `"2"`, w.Header().Get("ETag"))
+		assert.Contains(t, w.Body.String(), `"slug":"test-article"`)
+	})
+
+	t.Run("Not modified", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/v1/articles/by-slug/test-article", nil)
+		req.Header.Set("If-None-Match", `"2"`)
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotModified, w.Code)
+	})
+
+	t.Run("Former slug", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/v1/articles/by-slug/old-test-article?utm_source=feed", nil)
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusMovedPermanently, w.Code)
+		assert.Equal(t, "/v1/articles/by-slug/test-article?utm_source=feed", w.Header().Get("Location"))
+	})
+
+	t.Run("Unknown slug", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/v1/articles/by-slug/unknown", nil)
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("Unpublished article", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/v1/articles/by-slug/draft-article", nil)
+		req.Header.Set("X-User", "Jane Doe")
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+// TestApiHandler_GetListArticles_Status tests that only the editors and the author
+// restricting the list to their own articles may list the unpublished articles.
+func TestApiHandler_GetListArticles_Status(t *testing.T) {
+	apiHandler := api.NewApiHandler(&mockArticleService{})
+
+	r := gin.Default()
+	r.GET("/v1/articles", apiHandler.GetListArticles)
+
+	tests := []struct {
+		name   string
+		url    string
+		user   string
+		role   string
+		status int
+	}{
+		{name: "Published", url: "/v1/articles", status: http.StatusOK},
+		{name: "Invalid status", url: "/v1/articles?status=hidden", status: http.StatusBadRequest},
+		{name: "Anonymous drafts", url: "/v1/articles?status=draft", status: http.StatusForbidden},
+		{name: "Drafts of another author", url: "/v1/articles?status=draft&author=Jim+Doe", user: "John Doe", status: http.StatusForbidden},
+		{name: "Own drafts", url: "/v1/articles?status=draft&author=John+Doe", user: "John Doe", status: http.StatusOK},
+		{name: "Editor drafts", url: "/v1/articles?status=in_review", user: "Jane Doe", role: "editor", status: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", tt.url, nil)
+			req.Header.Set("X-User", tt.user)
+			req.Header.Set("X-User-Role", tt.role)
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+		})
+	}
+}
+
+// TestApiHandler_GetListArticles tests the GetListArticles handler.
+//
+// It checks the pagination metadata of the body and the Link header on the first, a middle and the last page.
+func TestApiHandler_GetListArticles(t *testing.T) {
+	tests := []struct {
+		name  string
+		url   string
+		links string
+	}{
+		{
+			name:  "First page",
+			url:   "/v1/articles?author=jhon",
+			links: `</v1/articles?author=jhon&page=1>; rel="first", </v1/articles?author=jhon&page=2>; rel="next", </v1/articles?author=jhon&page=3>; rel="last"`,
+		},
+		{
+			name:  "Middle page",
+			url:   "/v1/articles?page=2",
+			links: `</v1/articles?page=1>; rel="first", </v1/articles?page=1>; rel="prev", </v1/articles?page=3>; rel="next", </v1/articles?page=3>; rel="last"`,
+		},
+		{
+			name:  "Last page",
+			url:   "/v1/articles?page=3",
+			links: `</v1/articles?page=1>; rel="first", </v1/articles?page=2>; rel="prev", </v1/articles?page=3>; rel="last"`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			apiHandler := api.NewApiHandler(&mockArticleService{})
+
+			r := gin.Default()
+			r.GET("/v1/articles", apiHandler.GetListArticles)
+
+			req, _ := http.NewRequest("GET", tt.url, nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, tt.links, w.Header().Get("Link"))
+
+			var body article.ListArticleDTO
+			err := json.Unmarshal(w.Body.Bytes(), &body)
+			assert.NoError(t, err)
+			assert.Equal(t, int64(25), body.Total)
+			assert.Equal(t, 3, body.TotalPages)
+			assert.Equal(t, body.Page < 3, body.HasNext)
+		})
+	}
+}
+
+// TestApiHandler_GetListArticles_TooDeep tests a page beyond the pages reachable without a cursor is a bad request.
+func TestApiHandler_GetListArticles_TooDeep(t *testing.T) {
+	apiHandler := api.NewApiHandler(&mockArticleService{})
+
+	r := gin.Default()
+	r.GET("/v1/articles", apiHandler.GetListArticles)
+
+	req, _ := http.NewRequest("GET", "/v1/articles?limit=100&page=101", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "cursor")
+}
+
+// TestApiHandler_GetListArticles_Cursor tests the cursor pagination of the GetListArticles handler.
+//
+// It checks the Link header of the first and last page, and that an invalid cursor or facet interval is a bad request.
+func TestApiHandler_GetListArticles_Cursor(t *testing.T) {
+	tests := []struct {
+		name   string
+		url    string
+		status int
+		links  string
+	}{
+		{
+			name:   "First page",
+			url:    "/v1/articles?cursor=",
+			status: http.StatusOK,
+			links:  `</v1/articles?cursor=>; rel="first", </v1/articles?cursor=next>; rel="next"`,
+		},
+		{
+			name:   "Last page",
+			url:    "/v1/articles?cursor=next",
+			status: http.StatusOK,
+			links:  `</v1/articles?cursor=>; rel="first"`,
+		},
+		{
+			name:   "Invalid cursor",
+			url:    "/v1/articles?cursor=invalid",
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "Invalid facet interval",
+			url:    "/v1/articles?cursor=&created_facet=week",
+			status: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			apiHandler := api.NewApiHandler(&mockArticleService{})
+
+			r := gin.Default()
+			r.GET("/v1/articles", apiHandler.GetListArticles)
+
+			req, _ := http.NewRequest("GET", tt.url, nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+			assert.Equal(t, tt.links, w.Header().Get("Link"))
+		})
+	}
+}
+
+// TestApiHandler_SuggestArticles tests the SuggestArticles handler.
+func TestApiHandler_SuggestArticles(t *testing.T) {
+	apiHandler := api.NewApiHandler(&mockArticleService{})
+
+	r := gin.Default()
+	r.GET("/v1/articles/suggest", apiHandler.SuggestArticles)
+
+	t.Run("Suggestions", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/v1/articles/suggest?q=tes", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"titles":[{"id":1,"title":"Test Article","slug":""}],"authors":[]}`, w.Body.String())
+	})
+
+	t.Run("Missing prefix", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/v1/articles/suggest?q=+", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+// TestApiHandler_GetRelatedArticles tests the GetRelatedArticles handler.
+func TestApiHandler_GetRelatedArticles(t *testing.T) {
+	apiHandler := api.NewApiHandler(&mockArticleService{})
+
+	r := gin.Default()
+	r.GET("/v1/articles/:id/related", apiHandler.GetRelatedArticles)
+
+	tests := []struct {
+		name   string
+		url    string
+		status int
+	}{
+		{"Related articles", "/v1/articles/1/related?limit=3&same_author=true", http.StatusOK},
+		{"Article not found", "/v1/articles/9/related", http.StatusNotFound},
+		{"Invalid ID", "/v1/articles/abc/related", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", tt.url, nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+			if tt.status == http.StatusOK {
+				var body article.RelatedArticlesDTO
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+				assert.Len(t, body.Articles, 1)
+			}
+		})
+	}
+}
+
+// TestApiHandler_GetListArticles_Degraded tests that the results of the fallback search backend are flagged.
+func TestApiHandler_GetListArticles_Degraded(t *testing.T) {
+	apiHandler := api.NewApiHandler(&mockArticleService{})
+
+	r := gin.Default()
+	r.GET("/v1/articles", apiHandler.GetListArticles)
+
+	req, _ := http.NewRequest("GET", "/v1/articles?search=degraded", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "true", w.Header().Get("X-Search-Degraded"))
+	assert.Contains(t, w.Body.String(), `"degraded":true`)
+
+	req, _ = http.NewRequest("GET", "/v1/articles", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Empty(t, w.Header().Get("X-Search-Degraded"))
+	assert.NotContains(t, w.Body.String(), `"degraded"`)
 }
@@ -0,0 +1,144 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/undercode99/article_service/internal/app/article"
+)
+
+// revisionErrorStatus returns the status of an error of the revisions of an article.
+func revisionErrorStatus(err error) int {
+	switch err {
+	case article.ErrArticleNotFound, article.ErrRevisionNotFound:
+		return http.StatusNotFound
+	case article.ErrArticleVersionMismatch:
+		return http.StatusPreconditionFailed
+	case article.ErrEditForbidden:
+		return http.StatusForbidden
+	}
+	return http.StatusInternalServerError
+}
+
+// revisionParams returns the article ID and revision number of the path.
+func revisionParams(c *gin.Context) (int, int, error) {
+	idInt, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return 0, 0, err
+	}
+
+	revision, err := strconv.Atoi(c.Param("rev"))
+	if err != nil {
+		return 0, 0, err
+	}
+	return idInt, revision, nil
+}
+
+func (h *ApiHandler) GetArticleRevisions(c *gin.Context) {
+	idInt, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		h.withResponseErrorStatus(c, err, http.StatusBadRequest)
+		return
+	}
+
+	if _, err := h.getVisibleArticle(c, idInt); err != nil {
+		h.withResponseErrorStatus(c, err, articleErrorStatus(err))
+		return
+	}
+
+	revisions, err := h.articleService.GetArticleRevisions(c, idInt)
+	if err != nil {
+		h.withResponseErrorStatus(c, err, revisionErrorStatus(err))
+		return
+	}
+
+	h.withResponse(c, revisions)
+}
+
+func (h *ApiHandler) GetArticleRevision(c *gin.Context) {
+	idInt, revision, err := revisionParams(c)
+	if err != nil {
+		h.withResponseErrorStatus(c, err, http.StatusBadRequest)
+		return
+	}
+
+	if _, err := h.getVisibleArticle(c, idInt); err != nil {
+		h.withResponseErrorStatus(c, err, articleErrorStatus(err))
+		return
+	}
+
+	revisionItem, err := h.articleService.GetArticleRevision(c, idInt, revision)
+	if err != nil {
+		h.withResponseErrorStatus(c, err, revisionErrorStatus(err))
+		return
+	}
+
+	h.withResponse(c, revisionItem)
+}
+
+func (h *ApiHandler) GetArticleRevisionDiff(c *gin.Context) {
+	idInt, revision, err := revisionParams(c)
+	if err != nil {
+		h.withResponseErrorStatus(c, err, http.StatusBadRequest)
+		return
+	}
+
+	var qry article.ArticleRevisionDiffQuery
+	if err := c.BindQuery(&qry); err != nil {
+		h.withResponseError(c, err)
+		return
+	}
+	qry.ID = idInt
+	qry.Revision = revision
+
+	if _, err := h.getVisibleArticle(c, idInt); err != nil {
+		h.withResponseErrorStatus(c, err, articleErrorStatus(err))
+		return
+	}
+
+	diff, err := h.articleService.GetArticleRevisionDiff(c, &qry)
+	if err != nil {
+		h.withResponseErrorStatus(c, err, revisionErrorStatus(err))
+		return
+	}
+
+	h.withResponse(c, diff)
+}
+
+func (h *ApiHandler) RevertArticle(c *gin.Context) {
+	idInt, revision, err := revisionParams(c)
+	if err != nil {
+		h.withResponseErrorStatus(c, err, http.StatusBadRequest)
+		return
+	}
+
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		h.withResponseErrorStatus(c, err, preconditionStatus(err))
+		return
+	}
+
+	// the body with the note of the revert is optional
+	var revertCmd article.ArticleRevertCommand
+	if c.Request.ContentLength != 0 {
+		if err := c.BindJSON(&revertCmd); err != nil {
+			h.withResponseError(c, err)
+			return
+		}
+	}
+	revertCmd.ID = idInt
+	revertCmd.Revision = revision
+	revertCmd.Version = version
+	revertCmd.Actor = requestActor(c)
+	revertCmd.Editor = requestEditor(c)
+
+	revertedArticle, err := h.articleService.RevertArticle(c, &revertCmd)
+	if err != nil {
+		h.withResponseErrorStatus(c, err, revisionErrorStatus(err))
+		return
+	}
+
+	h.withETag(c, revertedArticle)
+	h.withResponse(c, revertedArticle)
+}
@@ -0,0 +1,113 @@
+package api_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/undercode99/article_service/internal/api"
+	"github.com/undercode99/article_service/internal/app/article"
+)
+
+func TestApiHandler_GetArticleRevisions(t *testing.T) {
+	apiHandler := api.NewApiHandler(&mockArticleService{})
+
+	r := gin.Default()
+	r.GET("/v1/articles/:id/revisions", apiHandler.GetArticleRevisions)
+	r.GET("/v1/articles/:id/revisions/:rev", apiHandler.GetArticleRevision)
+
+	tests := []struct {
+		name   string
+		path   string
+		status int
+	}{
+		{name: "Revisions listed", path: "/v1/articles/1/revisions", status: http.StatusOK},
+		{name: "Revisions of a missing article", path: "/v1/articles/2/revisions", status: http.StatusNotFound},
+		{name: "Revision found", path: "/v1/articles/1/revisions/1", status: http.StatusOK},
+		{name: "Revision not found", path: "/v1/articles/1/revisions/5", status: http.StatusNotFound},
+		{name: "Invalid revision", path: "/v1/articles/1/revisions/abc", status: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", tt.path, nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+		})
+	}
+}
+
+func TestApiHandler_GetArticleRevisionDiff(t *testing.T) {
+	apiHandler := api.NewApiHandler(&mockArticleService{})
+
+	r := gin.Default()
+	r.GET("/v1/articles/:id/revisions/:rev/diff", apiHandler.GetArticleRevisionDiff)
+
+	req, _ := http.NewRequest("GET", "/v1/articles/1/revisions/3/diff?from=1", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var diff article.ArticleRevisionDiffDTO
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &diff))
+	assert.Equal(t, 1, diff.From)
+	assert.Equal(t, 3, diff.To)
+	assert.Len(t, diff.Title, 2)
+}
+
+func TestApiHandler_RevertArticle(t *testing.T) {
+	apiHandler := api.NewApiHandler(&mockArticleService{})
+
+	r := gin.Default()
+	r.POST("/v1/articles/:id/revisions/:rev/revert", apiHandler.RevertArticle)
+
+	t.Run("Article reverted", func(t *testing.T) {
+		req, _ := http.NewRequest("POST", "/v1/articles/1/revisions/1/revert", bytes.NewBufferString(`{"note": "Vandalism"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("If-Match", `"2"`)
+		req.Header.Set("X-User", "jane")
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, `"3"`, w.Header().Get("ETag"))
+
+		bodyResultMap := make(map[string]interface{})
+		json.Unmarshal(w.Body.Bytes(), &bodyResultMap)
+		assert.Equal(t, "jane: Vandalism", bodyResultMap["body"])
+	})
+
+	tests := []struct {
+		name    string
+		path    string
+		ifMatch string
+		status  int
+	}{
+		{name: "Without a note", path: "/v1/articles/1/revisions/1/revert", ifMatch: "*", status: http.StatusOK},
+		{name: "Without If-Match", path: "/v1/articles/1/revisions/1/revert", status: http.StatusPreconditionRequired},
+		{name: "Version mismatch", path: "/v1/articles/1/revisions/1/revert", ifMatch: `"1"`, status: http.StatusPreconditionFailed},
+		{name: "Revision not found", path: "/v1/articles/1/revisions/4/revert", ifMatch: "*", status: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("POST", tt.path, nil)
+			if tt.ifMatch != "" {
+				req.Header.Set("If-Match", tt.ifMatch)
+			}
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+		})
+	}
+}
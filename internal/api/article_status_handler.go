@@ -0,0 +1,68 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/undercode99/article_service/internal/app/article"
+)
+
+// transitionErrorStatus returns the status of an error of a transition of an article.
+func transitionErrorStatus(err error) int {
+	switch err {
+	case article.ErrUnknownTransition:
+		return http.StatusBadRequest
+	case article.ErrTransitionForbidden:
+		return http.StatusForbidden
+	case article.ErrArticleNotFound:
+		return http.StatusNotFound
+	case article.ErrInvalidTransition:
+		return http.StatusConflict
+	case article.ErrArticleVersionMismatch:
+		return http.StatusPreconditionFailed
+	}
+	return http.StatusInternalServerError
+}
+
+func (h *ApiHandler) TransitionArticle(c *gin.Context) {
+	idInt, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		h.withResponseErrorStatus(c, err, http.StatusBadRequest)
+		return
+	}
+
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		h.withResponseErrorStatus(c, err, preconditionStatus(err))
+		return
+	}
+
+	// the body with the note and publish time of the transition is optional
+	var transitionCmd article.ArticleTransitionCommand
+	if c.Request.ContentLength != 0 {
+		if err := c.BindJSON(&transitionCmd); err != nil {
+			h.withResponseError(c, err)
+			return
+		}
+	}
+	transitionCmd.ID = idInt
+	transitionCmd.Transition = c.Param("transition")
+	transitionCmd.Version = version
+	transitionCmd.Actor = requestActor(c)
+	transitionCmd.Editor = requestEditor(c)
+
+	if err := transitionCmd.Validate(); err != nil {
+		h.withResponseErrorStatus(c, err, http.StatusBadRequest)
+		return
+	}
+
+	transitionedArticle, err := h.articleService.TransitionArticle(c, &transitionCmd)
+	if err != nil {
+		h.withResponseErrorStatus(c, err, transitionErrorStatus(err))
+		return
+	}
+
+	h.withETag(c, transitionedArticle)
+	h.withResponse(c, transitionedArticle)
+}
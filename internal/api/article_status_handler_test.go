@@ -0,0 +1,64 @@
+package api_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/undercode99/article_service/internal/api"
+	"github.com/undercode99/article_service/internal/app/article"
+)
+
+func TestApiHandler_TransitionArticle(t *testing.T) {
+	apiHandler := api.NewApiHandler(&mockArticleService{})
+
+	r := gin.Default()
+	r.POST("/v1/articles/:id/transitions/:transition", apiHandler.TransitionArticle)
+
+	tests := []struct {
+		name    string
+		path    string
+		ifMatch string
+		role    string
+		body    string
+		status  int
+		result  string
+	}{
+		{name: "Published", path: "/v1/articles/1/transitions/publish", ifMatch: `"2"`, role: "editor", status: http.StatusOK, result: article.StatusPublished},
+		{name: "Scheduled", path: "/v1/articles/1/transitions/publish", ifMatch: `"2"`, role: "admin", body: `{"publish_at": "2100-01-01T09:00:00Z"}`, status: http.StatusOK, result: article.StatusInReview},
+		{name: "Rejected", path: "/v1/articles/1/transitions/reject", ifMatch: "*", role: "editor", status: http.StatusOK, result: article.StatusDraft},
+		{name: "Author may not publish", path: "/v1/articles/1/transitions/publish", ifMatch: `"2"`, status: http.StatusForbidden},
+		{name: "Not in review", path: "/v1/articles/1/transitions/archive", ifMatch: `"2"`, role: "editor", status: http.StatusConflict},
+		{name: "Unknown transition", path: "/v1/articles/1/transitions/delete", ifMatch: `"2"`, role: "editor", status: http.StatusBadRequest},
+		{name: "Missing If-Match", path: "/v1/articles/1/transitions/publish", role: "editor", status: http.StatusPreconditionRequired},
+		{name: "Stale version", path: "/v1/articles/1/transitions/publish", ifMatch: `"1"`, role: "editor", status: http.StatusPreconditionFailed},
+		{name: "Article not found", path: "/v1/articles/2/transitions/publish", ifMatch: "*", role: "editor", status: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("POST", tt.path, bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("X-User", "Jane Doe")
+			req.Header.Set("X-User-Role", tt.role)
+			if tt.ifMatch != "" {
+				req.Header.Set("If-Match", tt.ifMatch)
+			}
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+			if tt.result != "" {
+				var item article.Article
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &item))
+				assert.Equal(t, tt.result, item.Status)
+				assert.Equal(t, `"3"`, w.Header().Get("ETag"))
+			}
+		})
+	}
+}
@@ -0,0 +1,213 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/undercode99/article_service/internal/app/article"
+)
+
+// taxonomyErrorStatus returns the status of an error of the tags and categories.
+func taxonomyErrorStatus(err error) int {
+	switch err {
+	case article.ErrInvalidTag, article.ErrTagMergeSelf, article.ErrCategoryNameMissing, article.ErrCategoryParentNotFound:
+		return http.StatusBadRequest
+	case article.ErrTaxonomyForbidden:
+		return http.StatusForbidden
+	case article.ErrTagNotFound, article.ErrCategoryNotFound:
+		return http.StatusNotFound
+	case article.ErrTagExists, article.ErrCategoryExists, article.ErrCategoryCycle, article.ErrCategoryNotEmpty:
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
+
+// canManageTaxonomy reports whether the user of the request may change the tags and categories,
+// the response is written if not.
+func (h *ApiHandler) canManageTaxonomy(c *gin.Context) bool {
+	if !requestActor(c).IsEditor() {
+		h.withResponseErrorStatus(c, article.ErrTaxonomyForbidden, http.StatusForbidden)
+		return false
+	}
+	return true
+}
+
+func (h *ApiHandler) GetTags(c *gin.Context) {
+	tags, err := h.articleService.GetTags(c)
+	if err != nil {
+		h.withResponseError(c, err)
+		return
+	}
+
+	h.withResponse(c, tags)
+}
+
+func (h *ApiHandler) CreateTag(c *gin.Context) {
+	if !h.canManageTaxonomy(c) {
+		return
+	}
+
+	var tagCmd article.TagCommand
+	if err := c.BindJSON(&tagCmd); err != nil {
+		h.withResponseError(c, err)
+		return
+	}
+
+	tag, err := h.articleService.CreateTag(c, &tagCmd)
+	if err != nil {
+		h.withResponseErrorStatus(c, err, taxonomyErrorStatus(err))
+		return
+	}
+
+	h.withResponse(c, tag, http.StatusCreated)
+}
+
+func (h *ApiHandler) UpdateTag(c *gin.Context) {
+	idInt, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		h.withResponseErrorStatus(c, err, http.StatusBadRequest)
+		return
+	}
+
+	if !h.canManageTaxonomy(c) {
+		return
+	}
+
+	var tagCmd article.TagCommand
+	if err := c.BindJSON(&tagCmd); err != nil {
+		h.withResponseError(c, err)
+		return
+	}
+	tagCmd.ID = idInt
+
+	tag, err := h.articleService.UpdateTag(c, &tagCmd)
+	if err != nil {
+		h.withResponseErrorStatus(c, err, taxonomyErrorStatus(err))
+		return
+	}
+
+	h.withResponse(c, tag)
+}
+
+func (h *ApiHandler) MergeTag(c *gin.Context) {
+	idInt, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		h.withResponseErrorStatus(c, err, http.StatusBadRequest)
+		return
+	}
+
+	if !h.canManageTaxonomy(c) {
+		return
+	}
+
+	var mergeCmd article.TagMergeCommand
+	if err := c.BindJSON(&mergeCmd); err != nil {
+		h.withResponseError(c, err)
+		return
+	}
+	mergeCmd.ID = idInt
+
+	tag, err := h.articleService.MergeTag(c, &mergeCmd)
+	if err != nil {
+		h.withResponseErrorStatus(c, err, taxonomyErrorStatus(err))
+		return
+	}
+
+	h.withResponse(c, tag)
+}
+
+func (h *ApiHandler) DeleteTag(c *gin.Context) {
+	idInt, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		h.withResponseErrorStatus(c, err, http.StatusBadRequest)
+		return
+	}
+
+	if !h.canManageTaxonomy(c) {
+		return
+	}
+
+	if err := h.articleService.DeleteTag(c, idInt); err != nil {
+		h.withResponseErrorStatus(c, err, taxonomyErrorStatus(err))
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *ApiHandler) GetCategories(c *gin.Context) {
+	categories, err := h.articleService.GetCategories(c)
+	if err != nil {
+		h.withResponseError(c, err)
+		return
+	}
+
+	h.withResponse(c, categories)
+}
+
+func (h *ApiHandler) CreateCategory(c *gin.Context) {
+	if !h.canManageTaxonomy(c) {
+		return
+	}
+
+	var categoryCmd article.CategoryCommand
+	if err := c.BindJSON(&categoryCmd); err != nil {
+		h.withResponseError(c, err)
+		return
+	}
+
+	category, err := h.articleService.CreateCategory(c, &categoryCmd)
+	if err != nil {
+		h.withResponseErrorStatus(c, err, taxonomyErrorStatus(err))
+		return
+	}
+
+	h.withResponse(c, category, http.StatusCreated)
+}
+
+func (h *ApiHandler) UpdateCategory(c *gin.Context) {
+	idInt, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		h.withResponseErrorStatus(c, err, http.StatusBadRequest)
+		return
+	}
+
+	if !h.canManageTaxonomy(c) {
+		return
+	}
+
+	var categoryCmd article.CategoryCommand
+	if err := c.BindJSON(&categoryCmd); err != nil {
+		h.withResponseError(c, err)
+		return
+	}
+	categoryCmd.ID = idInt
+
+	category, err := h.articleService.UpdateCategory(c, &categoryCmd)
+	if err != nil {
+		h.withResponseErrorStatus(c, err, taxonomyErrorStatus(err))
+		return
+	}
+
+	h.withResponse(c, category)
+}
+
+func (h *ApiHandler) DeleteCategory(c *gin.Context) {
+	idInt, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		h.withResponseErrorStatus(c, err, http.StatusBadRequest)
+		return
+	}
+
+	if !h.canManageTaxonomy(c) {
+		return
+	}
+
+	if err := h.articleService.DeleteCategory(c, idInt); err != nil {
+		h.withResponseErrorStatus(c, err, taxonomyErrorStatus(err))
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
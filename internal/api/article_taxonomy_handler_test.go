@@ -0,0 +1,119 @@
+package api_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/undercode99/article_service/internal/api"
+)
+
+func TestApiHandler_Tags(t *testing.T) {
+	apiHandler := api.NewApiHandler(&mockArticleService{})
+
+	r := gin.Default()
+	r.GET("/v1/tags", apiHandler.GetTags)
+	r.POST("/v1/tags", apiHandler.CreateTag)
+	r.PUT("/v1/tags/:id", apiHandler.UpdateTag)
+	r.DELETE("/v1/tags/:id", apiHandler.DeleteTag)
+	r.POST("/v1/tags/:id/merge", apiHandler.MergeTag)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		role   string
+		body   string
+		status int
+		result string
+	}{
+		{name: "Listed", method: "GET", path: "/v1/tags", status: http.StatusOK, result: `{"items": [{"id": 1, "name": "golang", "created": "0001-01-01T00:00:00Z"}]}`},
+		{name: "Created", method: "POST", path: "/v1/tags", role: "editor", body: `{"name": " Web  Development "}`, status: http.StatusCreated},
+		{name: "Author may not create", method: "POST", path: "/v1/tags", body: `{"name": "web"}`, status: http.StatusForbidden},
+		{name: "Invalid name", method: "POST", path: "/v1/tags", role: "editor", body: `{"name": "  "}`, status: http.StatusBadRequest},
+		{name: "Existing name", method: "POST", path: "/v1/tags", role: "editor", body: `{"name": "Golang"}`, status: http.StatusConflict},
+		{name: "Renamed", method: "PUT", path: "/v1/tags/1", role: "admin", body: `{"name": "Go"}`, status: http.StatusOK},
+		{name: "Rename not found", method: "PUT", path: "/v1/tags/9", role: "editor", body: `{"name": "Go"}`, status: http.StatusNotFound},
+		{name: "Merged", method: "POST", path: "/v1/tags/2/merge", role: "editor", body: `{"into": 1}`, status: http.StatusOK},
+		{name: "Merged into itself", method: "POST", path: "/v1/tags/1/merge", role: "editor", body: `{"into": 1}`, status: http.StatusBadRequest},
+		{name: "Deleted", method: "DELETE", path: "/v1/tags/1", role: "editor", status: http.StatusNoContent},
+		{name: "Author may not delete", method: "DELETE", path: "/v1/tags/1", status: http.StatusForbidden},
+		{name: "Delete not found", method: "DELETE", path: "/v1/tags/9", role: "editor", status: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(tt.method, tt.path, bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("X-User", "Jane Doe")
+			req.Header.Set("X-User-Role", tt.role)
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+			if tt.result != "" {
+				assert.JSONEq(t, tt.result, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestApiHandler_Categories(t *testing.T) {
+	apiHandler := api.NewApiHandler(&mockArticleService{})
+
+	r := gin.Default()
+	r.GET("/v1/categories", apiHandler.GetCategories)
+	r.POST("/v1/categories", apiHandler.CreateCategory)
+	r.PUT("/v1/categories/:id", apiHandler.UpdateCategory)
+	r.DELETE("/v1/categories/:id", apiHandler.DeleteCategory)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		role   string
+		body   string
+		status int
+		result string
+	}{
+		{name: "Tree", method: "GET", path: "/v1/categories", status: http.StatusOK, result: `{"items": [{
+			"id": 1, "parent_id": null, "name": "Tech", "path": "tech",
+			"created": "0001-01-01T00:00:00Z", "updated": "0001-01-01T00:00:00Z",
+			"children": [{
+				"id": 2, "parent_id": 1, "name": "Golang", "path": "tech/golang",
+				"created": "0001-01-01T00:00:00Z", "updated": "0001-01-01T00:00:00Z"
+			}]
+		}]}`},
+		{name: "Created", method: "POST", path: "/v1/categories", role: "editor", body: `{"name": "Rust", "parent_id": 1}`, status: http.StatusCreated},
+		{name: "Author may not create", method: "POST", path: "/v1/categories", body: `{"name": "Rust"}`, status: http.StatusForbidden},
+		{name: "Missing name", method: "POST", path: "/v1/categories", role: "editor", body: `{"name": " "}`, status: http.StatusBadRequest},
+		{name: "Missing parent", method: "POST", path: "/v1/categories", role: "editor", body: `{"name": "Rust", "parent_id": 9}`, status: http.StatusBadRequest},
+		{name: "Renamed", method: "PUT", path: "/v1/categories/1", role: "editor", body: `{"name": "Technology"}`, status: http.StatusOK},
+		{name: "Moved under itself", method: "PUT", path: "/v1/categories/1", role: "editor", body: `{"name": "Tech", "parent_id": 2}`, status: http.StatusConflict},
+		{name: "Update not found", method: "PUT", path: "/v1/categories/9", role: "editor", body: `{"name": "Tech"}`, status: http.StatusNotFound},
+		{name: "Deleted", method: "DELETE", path: "/v1/categories/2", role: "editor", status: http.StatusNoContent},
+		{name: "Not empty", method: "DELETE", path: "/v1/categories/1", role: "editor", status: http.StatusConflict},
+		{name: "Delete not found", method: "DELETE", path: "/v1/categories/9", role: "editor", status: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(tt.method, tt.path, bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("X-User", "Jane Doe")
+			req.Header.Set("X-User-Role", tt.role)
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.status, w.Code)
+			if tt.result != "" {
+				assert.JSONEq(t, tt.result, w.Body.String())
+			}
+		})
+	}
+}
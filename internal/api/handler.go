@@ -1,12 +1,19 @@
 package api
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/undercode99/article_service/internal/app/article"
 )
 
+// ErrPreconditionRequired is returned for a change of an article without an If-Match header.
+var ErrPreconditionRequired = errors.New("If-Match header is required")
+
 type ApiHandler struct {
 	articleService article.ArticleService
 }
@@ -32,3 +39,145 @@ func (h *ApiHandler) withResponse(c *gin.Context, data interface{}, status ...in
 		c.JSON(http.StatusOK, data)
 	}
 }
+
+// degradedHeader is the response header flagging results of the fallback search backend.
+const degradedHeader = "X-Search-Degraded"
+
+// withDegraded flags the response as degraded if its results come from the fallback search backend.
+func (h *ApiHandler) withDegraded(c *gin.Context, degraded bool) {
+	if degraded {
+		c.Header(degradedHeader, "true")
+	}
+}
+
+// withPaginationLinks sets the RFC 8288 Link header of a paginated response.
+//
+// The links keep the query of the current request and only replace the page,
+// prev and next are left out on the first and last page.
+func (h *ApiHandler) withPaginationLinks(c *gin.Context, page, totalPages int) {
+	if totalPages < 1 {
+		totalPages = 1
+	}
+
+	link := func(page int, rel string) string {
+		return paginationLink(c, "page", strconv.Itoa(page), rel)
+	}
+
+	links := []string{link(1, "first")}
+	if page > 1 {
+		links = append(links, link(page-1, "prev"))
+	}
+	if page < totalPages {
+		links = append(links, link(page+1, "next"))
+	}
+	links = append(links, link(totalPages, "last"))
+
+	c.Header("Link", strings.Join(links, ", "))
+}
+
+// withCursorLinks sets the RFC 8288 Link header of a cursor paginated response.
+//
+// first restarts the traversal, next is left out on the last page.
+func (h *ApiHandler) withCursorLinks(c *gin.Context, nextCursor string) {
+	links := []string{paginationLink(c, "cursor", "", "first")}
+	if nextCursor != "" {
+		links = append(links, paginationLink(c, "cursor", nextCursor, "next"))
+	}
+
+	c.Header("Link", strings.Join(links, ", "))
+}
+
+// paginationLink returns a link to the current request with the given query parameter replaced.
+func paginationLink(c *gin.Context, param, value, rel string) string {
+	u := *c.Request.URL
+	q := u.Query()
+	q.Set(param, value)
+	u.RawQuery = q.Encode()
+	return fmt.Sprintf(`<%s>; rel="%s"`, u.RequestURI(), rel)
+}
+
+// userHeader is the request header naming the user making the request, it is set by the gateway.
+const userHeader = "X-User"
+
+// anonymousEditor is recorded as the editor of the changes of requests without a user.
+const anonymousEditor = "anonymous"
+
+// requestEditor returns the user making the request, recorded as the editor of its changes.
+func requestEditor(c *gin.Context) string {
+	if user := strings.TrimSpace(c.GetHeader(userHeader)); user != "" {
+		return user
+	}
+	return anonymousEditor
+}
+
+// roleHeader is the request header naming the role of the user making the request, it is set by the gateway.
+const roleHeader = "X-User-Role"
+
+// requestActor returns the user making the request and their role.
+func requestActor(c *gin.Context) article.Actor {
+	return article.Actor{
+		User: strings.TrimSpace(c.GetHeader(userHeader)),
+		Role: strings.ToLower(strings.TrimSpace(c.GetHeader(roleHeader))),
+	}
+}
+
+// requireUser reports whether the request names the user making it, the response is written if not.
+func (h *ApiHandler) requireUser(c *gin.Context) bool {
+	if requestActor(c).IsAnonymous() {
+		h.withResponseErrorStatus(c, article.ErrUserRequired, http.StatusUnauthorized)
+		return false
+	}
+	return true
+}
+
+// withETag sets the ETag header of a response of the article.
+func (h *ApiHandler) withETag(c *gin.Context, item *article.Article) {
+	c.Header("ETag", item.ETag())
+}
+
+// ifNoneMatch reports whether the If-None-Match header of the request matches the ETag,
+// the response is then not modified.
+func ifNoneMatch(c *gin.Context, etag string) bool {
+	header := c.GetHeader("If-None-Match")
+	if header == "" {
+		return false
+	}
+
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		// the comparison is weak for If-None-Match
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// ifMatchVersion returns the version of the article the If-Match header of the request requires,
+// 0 if it matches any version.
+//
+// ErrPreconditionRequired is returned if the header is missing, and article.ErrArticleVersionMismatch
+// if it cannot match a version, such as a weak ETag or a list of ETags.
+func ifMatchVersion(c *gin.Context) (int, error) {
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+	if header == "" {
+		return 0, ErrPreconditionRequired
+	}
+	if header == "*" {
+		return 0, nil
+	}
+
+	version, ok := article.ParseETag(header)
+	if !ok {
+		return 0, article.ErrArticleVersionMismatch
+	}
+	return version, nil
+}
+
+// preconditionStatus returns the status of a failed precondition of a change.
+func preconditionStatus(err error) int {
+	if err == ErrPreconditionRequired {
+		return http.StatusPreconditionRequired
+	}
+	return http.StatusPreconditionFailed
+}
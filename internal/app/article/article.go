@@ -2,25 +2,61 @@ package article
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
+
+	"gorm.io/gorm"
 )
 
 var (
 	ErrArticleNotFound        = errors.New("article not found")
 	ErrArticleValidation      = errors.New("article validation error")
 	ErrArticleCachingNotFound = errors.New("article not found")
+	ErrInvalidCursor          = errors.New("invalid cursor")
+	ErrCursorExpired          = errors.New("cursor expired")
+	ErrArticleVersionMismatch = errors.New("article version mismatch")
 
-	// IndexName is the name of the index in Elasticsearch
+	// IndexName is the name of the alias in Elasticsearch the article index is served under
 	IndexName = "articles"
 )
 
 type Article struct {
 	ID      int       `json:"id"`
 	Title   string    `json:"title"`
-	Body    string    `json:"body"`
+	Body    string    `json:"body,omitempty"`
 	Author  string    `json:"author"`
 	Created time.Time `json:"created"`
+	Updated time.Time `json:"updated"`
+
+	// Slug is the current slug of the article, generated from its title when it is saved.
+	Slug string `json:"slug" gorm:"not null;default:'';index"`
+
+	// Version is incremented by every update, it is served as the ETag of the article.
+	Version int `json:"version" gorm:"not null;default:1"`
+
+	// Status is the status of the article in its lifecycle, only the published articles are public.
+	// The articles written before the lifecycle are published.
+	Status string `json:"status" gorm:"not null;default:published;index"`
+	// PublishAt is when the article is published, or is to be published while it is in review.
+	PublishAt *time.Time `json:"publish_at,omitempty"`
+
+	// Tags are the names of the tags of the article, CategoryID is the category it is filed
+	// under and Category the path of that category. The tags and the path are stored in
+	// their own tables and loaded along the article.
+	Tags       []string `json:"tags,omitempty" gorm:"-"`
+	CategoryID *int     `json:"category_id,omitempty" gorm:"index"`
+	Category   string   `json:"category,omitempty" gorm:"-"`
+
+	// DeletedAt marks the article as soft-deleted, it is kept until it is purged.
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+
+	// Highlight holds the highlighted fragments of a search result keyed by field.
+	Highlight map[string][]string `json:"highlight,omitempty" gorm:"-"`
 }
 
 // NewArticle creates a new article based on the provided ArticleCreateCommand.
@@ -28,33 +64,174 @@ type Article struct {
 // The function takes a pointer to an ArticleCreateCommand as its parameter
 // and returns a pointer to an Article. The Article struct is populated with
 // the values from the command parameter, including the author, title, body,
-// and creation timestamp.
+// tags, category and creation timestamp. The article starts as a draft.
 func NewArticle(cmd *ArticleCreateCommand) *Article {
+	now := time.Now()
 	return &Article{
-		Author:  cmd.Author,
-		Title:   cmd.Title,
-		Body:    cmd.Body,
-		Created: time.Now(),
+		Author:     cmd.Author,
+		Title:      cmd.Title,
+		Body:       cmd.Body,
+		Created:    now,
+		Updated:    now,
+		Version:    1,
+		Status:     StatusDraft,
+		Tags:       NormalizeTags(cmd.Tags),
+		CategoryID: cmd.CategoryID,
+	}
+}
+
+// Update replaces the editable fields of the article with the values
+// from the provided ArticleUpdateCommand.
+//
+// The ID and creation timestamp of the article are left untouched. A new title
+// with another slug clears the slug, a new one is then generated when the article is saved.
+// The tags and category are replaced too, the path of a new category is set when the article is saved.
+func (a *Article) Update(cmd *ArticleUpdateCommand) {
+	if NewSlug(cmd.Title) != NewSlug(a.Title) {
+		a.Slug = ""
+	}
+	if !sameCategory(a.CategoryID, cmd.CategoryID) {
+		a.Category = ""
+	}
+	a.Author = cmd.Author
+	a.Title = cmd.Title
+	a.Body = cmd.Body
+	a.Tags = NormalizeTags(cmd.Tags)
+	a.CategoryID = cmd.CategoryID
+	a.Updated = time.Now()
+}
+
+// sameCategory reports whether the category IDs are both unset or equal.
+func sameCategory(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// ETag returns the entity tag of the article, its version as a strong ETag.
+func (a *Article) ETag() string {
+	return `"` + strconv.Itoa(a.Version) + `"`
+}
+
+// ParseETag returns the version of the article of an ETag returned by Article.ETag.
+//
+// It returns false if the ETag is not a strong ETag of a version.
+func ParseETag(etag string) (int, bool) {
+	if len(etag) < 2 || etag[0] != '"' || etag[len(etag)-1] != '"' {
+		return 0, false
+	}
+
+	version, err := strconv.Atoi(etag[1 : len(etag)-1])
+	if err != nil || version < 1 {
+		return 0, false
+	}
+	return version, true
+}
+
+// ContentHash returns a hash of the content of the article.
+//
+// It is used to compare the copies of an article kept in the different stores,
+// every field of contentFields is hashed.
+func (a *Article) ContentHash() string {
+	h := sha256.New()
+	for _, field := range a.contentFields() {
+		h.Write([]byte(field))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// contentFields returns the fields of the article compared across the stores.
+//
+// A field stored in the database, the index and the cache belongs here, or a stale
+// copy of it is never detected. The creation and update times are left out since
+// they may be stored with a different precision, the publish time is compared to the second.
+func (a *Article) contentFields() []string {
+	publishAt := ""
+	if a.PublishAt != nil {
+		publishAt = a.PublishAt.UTC().Truncate(time.Second).Format(time.RFC3339)
 	}
+	// the tags are stored in any order
+	tags := append([]string(nil), a.Tags...)
+	sort.Strings(tags)
+	return []string{a.Author, a.Title, a.Body, strconv.Itoa(a.Version), a.Status, publishAt, strings.Join(tags, "\x1f"), a.Category}
 }
 
 type ArticleCommandRepository interface {
-	CreateArticle(article *Article) error
+	CreateArticle(article *Article, revision *ArticleRevision) error
+	UpdateArticle(article *Article, revision *ArticleRevision) error
+	DeleteArticle(id int, version int) error
+	RestoreArticle(id int) error
+	PurgeArticle(id int) error
+	PurgeDeletedArticles(deletedBefore time.Time) ([]int, error)
+	PublishScheduledArticles(now time.Time) ([]int, error)
 	CreateIndexArticle(ctx context.Context, article *Article) error
+	DeleteIndexArticle(ctx context.Context, id int) error
 }
 
 type ArticleCachingRepository interface {
 	CreateArticle(ctx context.Context, article *Article) error
+	CreateArticleNotFound(ctx context.Context, id int) error
 	GetArticleByID(ctx context.Context, id int) (*Article, error)
+	DeleteArticle(ctx context.Context, id int) error
+	CreateArticleSlug(ctx context.Context, slug string, id int) error
+	GetArticleIDBySlug(ctx context.Context, slug string) (int, error)
+	CreateRelatedArticles(ctx context.Context, query *ArticleRelatedQuery, related *RelatedArticlesDTO) error
+	GetRelatedArticles(ctx context.Context, query *ArticleRelatedQuery) (*RelatedArticlesDTO, error)
+	CreateListArticles(ctx context.Context, query *ArticleQuery, list *ListArticleDTO) error
+	GetListArticles(ctx context.Context, query *ArticleQuery) (*ListArticleDTO, error)
+	DeleteListArticles(ctx context.Context, authors ...string) error
+}
+
+// ArticleSearchRepository searches the articles, it is implemented by each search backend.
+type ArticleSearchRepository interface {
+	GetListArticles(ctx context.Context, query *ArticleQuery) (*ListArticleDTO, error)
+	GetArticleSuggestions(ctx context.Context, query *ArticleSuggestQuery) (*ArticleSuggestionsDTO, error)
+	GetRelatedArticles(ctx context.Context, query *ArticleRelatedQuery, source *Article) (*RelatedArticlesDTO, error)
 }
 
 type ArticleQueryRepository interface {
 	GetArticleByID(id int) (*Article, error)
-	GetListArticles(ctx context.Context, query *ArticleQuery) (*ListArticleDTO, error)
+	GetDeletedArticleByID(id int) (*Article, error)
+	ArticleSearchRepository
+}
+
+type ArticleReindexer interface {
+	Reindex(ctx context.Context, batchSize int, deleteOld bool) (*ReindexResultDTO, error)
+}
+
+type ArticleConsistencyChecker interface {
+	Check(ctx context.Context, batchSize int, repair bool) (*ConsistencyReportDTO, error)
 }
 
 type ArticleService interface {
 	CreateArticle(ctx context.Context, cmd *ArticleCreateCommand) (*Article, error)
+	UpdateArticle(ctx context.Context, cmd *ArticleUpdateCommand) (*Article, error)
+	DeleteArticle(ctx context.Context, cmd *ArticleDeleteCommand) error
+	RestoreArticle(ctx context.Context, cmd *ArticleRestoreCommand) (*Article, error)
+	RevertArticle(ctx context.Context, cmd *ArticleRevertCommand) (*Article, error)
+	TransitionArticle(ctx context.Context, cmd *ArticleTransitionCommand) (*Article, error)
+	PublishScheduledArticles(ctx context.Context) (int, error)
+	GetArticleRevisions(ctx context.Context, id int) (*ArticleRevisionsDTO, error)
+	GetArticleRevision(ctx context.Context, id, revision int) (*ArticleRevision, error)
+	GetArticleRevisionDiff(ctx context.Context, query *ArticleRevisionDiffQuery) (*ArticleRevisionDiffDTO, error)
+	PurgeArticle(ctx context.Context, cmd *ArticlePurgeCommand) error
+	PurgeDeletedArticles(ctx context.Context, retention time.Duration) (int, error)
+	GetOutboxStats(ctx context.Context) (*ArticleOutboxStatsDTO, error)
+	RetryOutbox(ctx context.Context, id int) error
 	GetArticleByID(ctx context.Context, id int) (*Article, error)
+	GetArticleBySlug(ctx context.Context, slug string) (*Article, error)
 	GetListArticles(ctx context.Context, query *ArticleQuery) (*ListArticleDTO, error)
+	GetArticleSuggestions(ctx context.Context, query *ArticleSuggestQuery) (*ArticleSuggestionsDTO, error)
+	GetRelatedArticles(ctx context.Context, query *ArticleRelatedQuery) (*RelatedArticlesDTO, error)
+	GetTags(ctx context.Context) (*TagsDTO, error)
+	CreateTag(ctx context.Context, cmd *TagCommand) (*Tag, error)
+	UpdateTag(ctx context.Context, cmd *TagCommand) (*Tag, error)
+	MergeTag(ctx context.Context, cmd *TagMergeCommand) (*Tag, error)
+	DeleteTag(ctx context.Context, id int) error
+	GetCategories(ctx context.Context) (*CategoriesDTO, error)
+	CreateCategory(ctx context.Context, cmd *CategoryCommand) (*Category, error)
+	UpdateCategory(ctx context.Context, cmd *CategoryCommand) (*Category, error)
+	DeleteCategory(ctx context.Context, id int) error
 }
@@ -1,6 +1,10 @@
 package article
 
-import "errors"
+import (
+	"errors"
+	"strings"
+	"time"
+)
 
 var (
 	ErrAuthorIsRequired = errors.New("author is required")
@@ -12,11 +16,20 @@ type ArticleCreateCommand struct {
 	Author string `json:"author"`
 	Title  string `json:"title"`
 	Body   string `json:"body"`
+
+	// Tags are the names of the tags of the article, the missing tags are created,
+	// and CategoryID is the category the article is filed under.
+	Tags       []string `json:"tags"`
+	CategoryID *int     `json:"category_id"`
+
+	// Editor and Note are recorded in the first revision of the article.
+	Editor string `json:"-"`
+	Note   string `json:"note"`
 }
 
 // Validate checks if the ArticleCreateCommand is valid.
 //
-// It returns an error if any of the required fields are missing.
+// It returns an error if any of the required fields are missing or a tag is invalid.
 func (a *ArticleCreateCommand) Validate() error {
 	if a.Author == "" {
 		return ErrAuthorIsRequired
@@ -28,5 +41,155 @@ func (a *ArticleCreateCommand) Validate() error {
 		return ErrBodyIsRequired
 	}
 
+	return validateTags(a.Tags)
+}
+
+type ArticleUpdateCommand struct {
+	ID     int    `json:"-"`
+	Author string `json:"author"`
+	Title  string `json:"title"`
+	Body   string `json:"body"`
+
+	// Tags are the names of the tags of the article, the missing tags are created,
+	// and CategoryID is the category the article is filed under.
+	Tags       []string `json:"tags"`
+	CategoryID *int     `json:"category_id"`
+
+	// Actor is the user making the update, Editor and Note are recorded in its revision.
+	Actor  Actor  `json:"-"`
+	Editor string `json:"-"`
+	Note   string `json:"note"`
+
+	// Version is the version of the article the update is based on, 0 matches any version.
+	Version int `json:"-"`
+}
+
+// Validate checks if the ArticleUpdateCommand is valid.
+//
+// It returns an error if any of the required fields are missing or a tag is invalid.
+func (a *ArticleUpdateCommand) Validate() error {
+	if a.Author == "" {
+		return ErrAuthorIsRequired
+	}
+	if a.Title == "" {
+		return ErrTitleIsRequired
+	}
+	if a.Body == "" {
+		return ErrBodyIsRequired
+	}
+
+	return validateTags(a.Tags)
+}
+
+type ArticleDeleteCommand struct {
+	ID int
+
+	// Actor is the user deleting the article.
+	Actor Actor
+
+	// Version is the version of the article the deletion is based on, 0 matches any version.
+	Version int
+}
+
+type ArticleRestoreCommand struct {
+	ID int
+
+	// Actor is the user restoring the article.
+	Actor Actor
+}
+
+type ArticlePurgeCommand struct {
+	ID int
+
+	// Actor is the user purging the article.
+	Actor Actor
+}
+
+type ArticleRevertCommand struct {
+	ID       int `json:"-"`
+	Revision int `json:"-"`
+
+	// Actor is the user making the revert, Editor and Note are recorded in its revision.
+	Actor  Actor  `json:"-"`
+	Editor string `json:"-"`
+	Note   string `json:"note"`
+
+	// Version is the version of the article the revert is based on, 0 matches any version.
+	Version int `json:"-"`
+}
+
+type ArticleTransitionCommand struct {
+	ID         int    `json:"-"`
+	Transition string `json:"-"`
+
+	// PublishAt schedules the publication of the publish transition, it is published at once
+	// if it is unset or in the past.
+	PublishAt *time.Time `json:"publish_at"`
+
+	// Actor is the user performing the transition, Editor and Note are recorded in its revision.
+	Actor  Actor  `json:"-"`
+	Editor string `json:"-"`
+	Note   string `json:"note"`
+
+	// Version is the version of the article the transition is based on, 0 matches any version.
+	Version int `json:"-"`
+}
+
+// Validate checks if the ArticleTransitionCommand is valid.
+//
+// It returns ErrUnknownTransition if the transition does not exist.
+func (a *ArticleTransitionCommand) Validate() error {
+	if _, ok := ArticleTransitions[a.Transition]; !ok {
+		return ErrUnknownTransition
+	}
+
+	return nil
+}
+
+type TagCommand struct {
+	ID   int    `json:"-"`
+	Name string `json:"name"`
+}
+
+// Validate checks if the TagCommand is valid.
+//
+// It returns ErrInvalidTag if the normalized name is empty or too long.
+func (a *TagCommand) Validate() error {
+	return validateTag(a.Name)
+}
+
+type TagMergeCommand struct {
+	// ID is the tag merged into the tag Into, it is taken from the path.
+	ID   int `json:"-"`
+	Into int `json:"into"`
+}
+
+// Validate checks if the TagMergeCommand is valid.
+//
+// It returns ErrTagMergeSelf if the tag is merged into itself.
+func (a *TagMergeCommand) Validate() error {
+	if a.ID == a.Into {
+		return ErrTagMergeSelf
+	}
+
+	return nil
+}
+
+type CategoryCommand struct {
+	ID   int    `json:"-"`
+	Name string `json:"name"`
+
+	// ParentID is the category the category is filed under, it is a root category if unset.
+	ParentID *int `json:"parent_id"`
+}
+
+// Validate checks if the CategoryCommand is valid.
+//
+// It returns ErrCategoryNameMissing if the name is blank.
+func (a *CategoryCommand) Validate() error {
+	if strings.TrimSpace(a.Name) == "" {
+		return ErrCategoryNameMissing
+	}
+
 	return nil
 }
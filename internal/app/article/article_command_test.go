@@ -54,6 +54,72 @@ func TestArticleCreateCommand_Validate(t *testing.T) {
 			},
 			wantErr: article.ErrBodyIsRequired,
 		},
+		{
+			name: "blank tag",
+			command: &article.ArticleCreateCommand{
+				Author: "John Doe",
+				Title:  "Test Article",
+				Body:   "This is a test article",
+				Tags:   []string{"golang", "  "},
+			},
+			wantErr: article.ErrInvalidTag,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotErr := tt.command.Validate()
+			assert.Equal(t, tt.wantErr, gotErr)
+		})
+	}
+}
+
+// TestArticleUpdateCommand_Validate tests the validation of the ArticleUpdateCommand function.
+//
+// It follows the same scenarios as the create command, since an update replaces all editable fields.
+func TestArticleUpdateCommand_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		command *article.ArticleUpdateCommand
+		wantErr error
+	}{
+		{
+			name: "valid command",
+			command: &article.ArticleUpdateCommand{
+				ID:     1,
+				Author: "John Doe",
+				Title:  "Test Article",
+				Body:   "This is a test article",
+			},
+			wantErr: nil,
+		},
+		{
+			name: "missing author",
+			command: &article.ArticleUpdateCommand{
+				ID:    1,
+				Title: "Test Article",
+				Body:  "This is a test article",
+			},
+			wantErr: article.ErrAuthorIsRequired,
+		},
+		{
+			name: "missing title",
+			command: &article.ArticleUpdateCommand{
+				ID:     1,
+				Author: "John Doe",
+				Body:   "This is a test article",
+			},
+			wantErr: article.ErrTitleIsRequired,
+		},
+		{
+			name: "missing body",
+			command: &article.ArticleUpdateCommand{
+				ID:     1,
+				Author: "John Doe",
+				Title:  "Test Article",
+			},
+			wantErr: article.ErrBodyIsRequired,
+		},
 	}
 
 	for _, tt := range tests {
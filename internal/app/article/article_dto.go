@@ -1,7 +1,202 @@
 package article
 
+import (
+	"sort"
+	"time"
+
+	"github.com/undercode99/article_service/internal/diffing"
+)
+
 type ListArticleDTO struct {
-	Articles []Article `json:"items"`
-	Page     int       `json:"page"`
-	Limit    int       `json:"limit"`
+	Articles   []Article `json:"items"`
+	Page       int       `json:"page"`
+	Limit      int       `json:"limit"`
+	Total      int64     `json:"total"`
+	TotalPages int       `json:"total_pages"`
+	HasNext    bool      `json:"has_next"`
+	NextCursor string    `json:"next_cursor,omitempty"`
+
+	Facets *ArticleFacetsDTO `json:"facets,omitempty"`
+
+	// Degraded is set when the articles were searched by the fallback backend
+	// while Elasticsearch is unavailable, with approximate relevance.
+	Degraded bool `json:"degraded,omitempty"`
+}
+
+type ArticleFacetsDTO struct {
+	Authors []FacetBucketDTO `json:"authors,omitempty"`
+	Created []FacetBucketDTO `json:"created,omitempty"`
+	Tags    []FacetBucketDTO `json:"tags,omitempty"`
+}
+
+type FacetBucketDTO struct {
+	Key   string `json:"key"`
+	Count int64  `json:"count"`
+}
+
+// NewListArticleDTO creates a page of articles out of total matching articles.
+//
+// The number of pages and whether a next page exists are derived from the total,
+// the pages beyond the window reachable without a cursor are not counted.
+func NewListArticleDTO(articles []Article, page, limit int, total int64) *ListArticleDTO {
+	totalPages := 0
+	if limit > 0 {
+		totalPages = int((total + int64(limit) - 1) / int64(limit))
+		if totalPages > maxListWindow/limit {
+			totalPages = maxListWindow / limit
+		}
+	}
+
+	return &ListArticleDTO{
+		Articles:   articles,
+		Page:       page,
+		Limit:      limit,
+		Total:      total,
+		TotalPages: totalPages,
+		HasNext:    page < totalPages,
+	}
+}
+
+type ArticleSuggestionsDTO struct {
+	Titles  []TitleSuggestionDTO `json:"titles"`
+	Authors []string             `json:"authors"`
+
+	Degraded bool `json:"degraded,omitempty"`
+}
+
+type RelatedArticlesDTO struct {
+	Articles []Article `json:"articles"`
+
+	Degraded bool `json:"degraded,omitempty"`
+}
+
+type TitleSuggestionDTO struct {
+	ID    int    `json:"id"`
+	Title string `json:"title"`
+	Slug  string `json:"slug"`
+}
+
+type ArticleOutboxStatsDTO struct {
+	Pending       int64           `json:"pending"`
+	Retrying      int64           `json:"retrying"`
+	Dead          int64           `json:"dead"`
+	OldestPending *time.Time      `json:"oldest_pending"`
+	Failures      []ArticleOutbox `json:"failures"`
+}
+
+type ReindexResultDTO struct {
+	Index           string   `json:"index"`
+	PreviousIndices []string `json:"previous_indices"`
+	Indexed         int      `json:"indexed"`
+	CaughtUp        int      `json:"caught_up"`
+	Purged          int      `json:"purged"`
+}
+
+type ConsistencyReportDTO struct {
+	DatabaseArticles int `json:"database_articles"`
+	IndexDocuments   int `json:"index_documents"`
+	CacheEntries     int `json:"cache_entries"`
+
+	MissingInIndex   []int `json:"missing_in_index"`
+	OrphanedInIndex  []int `json:"orphaned_in_index"`
+	DivergentInIndex []int `json:"divergent_in_index"`
+	OrphanedInCache  []int `json:"orphaned_in_cache"`
+	DivergentInCache []int `json:"divergent_in_cache"`
+
+	Repaired bool `json:"repaired"`
+}
+
+// NewConsistencyReport compares the content hashes of the articles kept in each store.
+//
+// The maps are keyed by article ID. A missing cache entry is not reported, the
+// cache is only filled on reads.
+func NewConsistencyReport(database, index, cache map[int]string) *ConsistencyReportDTO {
+	report := &ConsistencyReportDTO{
+		DatabaseArticles: len(database),
+		IndexDocuments:   len(index),
+		CacheEntries:     len(cache),
+		MissingInIndex:   []int{},
+		OrphanedInIndex:  []int{},
+		DivergentInIndex: []int{},
+		OrphanedInCache:  []int{},
+		DivergentInCache: []int{},
+	}
+
+	for id, hash := range database {
+		indexHash, ok := index[id]
+		if !ok {
+			report.MissingInIndex = append(report.MissingInIndex, id)
+		} else if indexHash != hash {
+			report.DivergentInIndex = append(report.DivergentInIndex, id)
+		}
+
+		if cacheHash, ok := cache[id]; ok && cacheHash != hash {
+			report.DivergentInCache = append(report.DivergentInCache, id)
+		}
+	}
+
+	for id := range index {
+		if _, ok := database[id]; !ok {
+			report.OrphanedInIndex = append(report.OrphanedInIndex, id)
+		}
+	}
+
+	for id := range cache {
+		if _, ok := database[id]; !ok {
+			report.OrphanedInCache = append(report.OrphanedInCache, id)
+		}
+	}
+
+	sort.Ints(report.MissingInIndex)
+	sort.Ints(report.OrphanedInIndex)
+	sort.Ints(report.DivergentInIndex)
+	sort.Ints(report.OrphanedInCache)
+	sort.Ints(report.DivergentInCache)
+
+	return report
+}
+
+// IsConsistent reports whether no problems were found.
+func (r *ConsistencyReportDTO) IsConsistent() bool {
+	return len(r.MissingInIndex) == 0 &&
+		len(r.OrphanedInIndex) == 0 &&
+		len(r.DivergentInIndex) == 0 &&
+		len(r.OrphanedInCache) == 0 &&
+		len(r.DivergentInCache) == 0
+}
+
+type ArticleRevisionsDTO struct {
+	Revisions []ArticleRevision `json:"items"`
+}
+
+type TagsDTO struct {
+	Tags []Tag `json:"items"`
+}
+
+type CategoriesDTO struct {
+	// Categories are the root categories with their subcategories.
+	Categories []*Category `json:"items"`
+}
+
+type ArticleRevisionDiffDTO struct {
+	From  int            `json:"from"`
+	To    int            `json:"to"`
+	Title []diffing.Line `json:"title"`
+	Body  []diffing.Line `json:"body"`
+}
+
+// NewArticleRevisionDiffDTO returns the line-level diff of the title and body from one revision to another.
+//
+// A nil from is an empty article, everything in the other revision is then inserted.
+func NewArticleRevisionDiffDTO(from, to *ArticleRevision) *ArticleRevisionDiffDTO {
+	if from == nil {
+		from = &ArticleRevision{}
+	}
+
+	return &ArticleRevisionDiffDTO{
+		From:  from.Revision,
+		To:    to.Revision,
+		Title: diffing.Lines(from.Title, to.Title),
+		Body:  diffing.Lines(from.Body, to.Body),
+	}
 }
@@ -0,0 +1,45 @@
+package article_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/undercode99/article_service/internal/app/article"
+)
+
+// TestNewConsistencyReport tests the NewConsistencyReport function.
+//
+// It checks that missing, orphaned and divergent entries are reported per store,
+// and that articles not in the cache are not reported.
+func TestNewConsistencyReport(t *testing.T) {
+	database := map[int]string{1: "a", 2: "b", 3: "c"}
+	index := map[int]string{1: "a", 3: "x", 4: "d"}
+	cache := map[int]string{2: "x", 5: "e"}
+
+	report := article.NewConsistencyReport(database, index, cache)
+	assert.Equal(t, 3, report.DatabaseArticles)
+	assert.Equal(t, 3, report.IndexDocuments)
+	assert.Equal(t, 2, report.CacheEntries)
+	assert.Equal(t, []int{2}, report.MissingInIndex)
+	assert.Equal(t, []int{4}, report.OrphanedInIndex)
+	assert.Equal(t, []int{3}, report.DivergentInIndex)
+	assert.Equal(t, []int{5}, report.OrphanedInCache)
+	assert.Equal(t, []int{2}, report.DivergentInCache)
+	assert.False(t, report.IsConsistent())
+
+	report = article.NewConsistencyReport(database, database, map[int]string{1: "a"})
+	assert.True(t, report.IsConsistent())
+}
+
+// TestNewListArticleDTO tests the pagination of a page of articles.
+//
+// It checks that the pages beyond the window reachable without a cursor are not counted.
+func TestNewListArticleDTO(t *testing.T) {
+	result := article.NewListArticleDTO(nil, 2, 10, 25)
+	assert.Equal(t, 3, result.TotalPages)
+	assert.True(t, result.HasNext)
+
+	result = article.NewListArticleDTO(nil, 100, 100, 50000)
+	assert.Equal(t, 100, result.TotalPages)
+	assert.False(t, result.HasNext)
+}
@@ -0,0 +1,89 @@
+package article
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	ErrOutboxNotFound  = errors.New("outbox record not found")
+	ErrOutboxForbidden = errors.New("only editors may manage the outbox")
+)
+
+const (
+	// OutboxStatusPending is the status of a record waiting to be dispatched or retried.
+	OutboxStatusPending = "pending"
+	// OutboxStatusDead is the status of a record that ran out of attempts.
+	OutboxStatusDead = "dead"
+
+	OutboxActionCreated  = "created"
+	OutboxActionUpdated  = "updated"
+	OutboxActionDeleted  = "deleted"
+	OutboxActionRestored = "restored"
+	OutboxActionPurged   = "purged"
+	OutboxActionRepair   = "repair"
+)
+
+// ArticleOutbox is a pending change of an article that still has to be
+// propagated to the search index.
+//
+// It is written in the same transaction as the change itself, so a change
+// saved in the database is never lost even if indexing fails.
+type ArticleOutbox struct {
+	ID            int       `json:"id"`
+	ArticleID     int       `json:"article_id" gorm:"index"`
+	Action        string    `json:"action"`
+	Status        string    `json:"status" gorm:"index"`
+	Attempts      int       `json:"attempts"`
+	LastError     string    `json:"last_error"`
+	NextAttemptAt time.Time `json:"next_attempt_at" gorm:"index"`
+	Created       time.Time `json:"created"`
+}
+
+// NewArticleOutbox creates a new pending outbox record for the given article and action.
+func NewArticleOutbox(articleID int, action string) *ArticleOutbox {
+	now := time.Now()
+	return &ArticleOutbox{
+		ArticleID:     articleID,
+		Action:        action,
+		Status:        OutboxStatusPending,
+		NextAttemptAt: now,
+		Created:       now,
+	}
+}
+
+// Fail records a failed dispatch attempt.
+//
+// The record is scheduled for another attempt after the given backoff,
+// or marked as dead once maxAttempts is reached.
+func (o *ArticleOutbox) Fail(err error, maxAttempts int, backoff time.Duration) {
+	o.Attempts++
+	o.LastError = err.Error()
+	if o.Attempts >= maxAttempts {
+		o.Status = OutboxStatusDead
+		return
+	}
+	o.NextAttemptAt = time.Now().Add(backoff)
+}
+
+// Postpone schedules the record for another attempt after the given backoff
+// without counting the failed one, the failure was not caused by the record.
+func (o *ArticleOutbox) Postpone(err error, backoff time.Duration) {
+	o.LastError = err.Error()
+	o.NextAttemptAt = time.Now().Add(backoff)
+}
+
+type ArticleOutboxRepository interface {
+	CreateOutbox(outboxes []*ArticleOutbox) error
+	ClaimOutbox(limit int, lease time.Duration) ([]ArticleOutbox, error)
+	UpdateOutbox(outbox *ArticleOutbox) error
+	DeleteOutbox(id int) error
+	RetryOutbox(id int) error
+	GetOutboxStats(failuresLimit int) (*ArticleOutboxStatsDTO, error)
+}
+
+type ArticleOutboxDispatcher interface {
+	Dispatch(ctx context.Context) (int, error)
+	Run(ctx context.Context)
+}
@@ -0,0 +1,44 @@
+package article_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/undercode99/article_service/internal/app/article"
+)
+
+// TestArticleOutbox_Fail tests the Fail method.
+//
+// It checks that a failed record is rescheduled after the backoff until it runs out of attempts,
+// at which point it is moved to the dead-letter state.
+func TestArticleOutbox_Fail(t *testing.T) {
+	outbox := article.NewArticleOutbox(1, article.OutboxActionCreated)
+	assert.Equal(t, article.OutboxStatusPending, outbox.Status)
+
+	outbox.Fail(errors.New("timeout"), 2, time.Minute)
+	assert.Equal(t, article.OutboxStatusPending, outbox.Status)
+	assert.Equal(t, 1, outbox.Attempts)
+	assert.Equal(t, "timeout", outbox.LastError)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), outbox.NextAttemptAt, time.Second)
+
+	outbox.Fail(errors.New("timeout again"), 2, time.Minute)
+	assert.Equal(t, article.OutboxStatusDead, outbox.Status)
+	assert.Equal(t, 2, outbox.Attempts)
+	assert.Equal(t, "timeout again", outbox.LastError)
+}
+
+// TestArticleOutbox_Postpone tests the Postpone method.
+//
+// It checks that a postponed record is rescheduled after the backoff without counting the attempt.
+func TestArticleOutbox_Postpone(t *testing.T) {
+	outbox := article.NewArticleOutbox(1, article.OutboxActionCreated)
+	outbox.Attempts = 2
+
+	outbox.Postpone(errors.New("elasticsearch unavailable"), time.Minute)
+	assert.Equal(t, article.OutboxStatusPending, outbox.Status)
+	assert.Equal(t, 2, outbox.Attempts)
+	assert.Equal(t, "elasticsearch unavailable", outbox.LastError)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), outbox.NextAttemptAt, time.Second)
+}
@@ -1,23 +1,305 @@
 package article
 
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidFacetInterval = errors.New("created facet must be day, month or year")
+	ErrInvalidMatchMode     = errors.New("match mode must be all, any or phrase")
+	ErrInvalidCreatedRange  = errors.New("created range must be dates (2006-01-02) or RFC 3339 times with from before to")
+	ErrSuggestQueryRequired = errors.New("q is required")
+	ErrInvalidPagination    = errors.New("limit and page must not be negative")
+	ErrPageTooDeep          = errors.New("page * limit must not exceed 10000, use the cursor parameter to paginate further")
+)
+
+const (
+	// ArticleViewSummary is the view of a list without the article bodies.
+	ArticleViewSummary = "summary"
+
+	// MatchModeAny, MatchModeAll and MatchModePhrase select how the search terms must match,
+	// any of the terms, all of the terms or the terms as a phrase.
+	MatchModeAny    = "any"
+	MatchModeAll    = "all"
+	MatchModePhrase = "phrase"
+
+	// FacetIntervalDay, FacetIntervalMonth and FacetIntervalYear are the intervals of the created facet.
+	FacetIntervalDay   = "day"
+	FacetIntervalMonth = "month"
+	FacetIntervalYear  = "year"
+
+	defaultFragmentSize    = 150
+	maxFragmentSize        = 1000
+	defaultAuthorFacetSize = 10
+	maxAuthorFacetSize     = 100
+	defaultTagFacetSize    = 10
+	maxTagFacetSize        = 100
+	defaultSuggestLimit    = 5
+	maxSuggestLimit        = 20
+	defaultRelatedLimit    = 5
+	maxRelatedLimit        = 20
+	defaultListLimit       = 10
+	maxListLimit           = 100
+	// maxListWindow is the deepest article page pagination reaches, the result window of Elasticsearch.
+	maxListWindow = 10000
+)
+
 type ArticleQuery struct {
 	Search     string `form:"search"`
 	Author     string `form:"author"`
 	SortNewest bool   `form:"sort_newest"`
 	Limit      int    `form:"limit"`
 	Page       int    `form:"page"`
+
+	// MatchMode is how the search terms must match, MatchModeAny by default.
+	MatchMode string `form:"match_mode"`
+	// Authors restricts the articles to any of the given authors, along Author.
+	Authors []string `form:"authors"`
+	// ExcludeAuthors leaves out the articles of the given authors.
+	ExcludeAuthors []string `form:"exclude_authors"`
+	// Status restricts the articles to a status of their lifecycle, StatusPublished by default.
+	Status string `form:"status"`
+	// Tags restricts the articles to those with every one of the given tags.
+	Tags []string `form:"tags"`
+	// Category restricts the articles to the category with the given path and its subcategories.
+	Category string `form:"category"`
+	// CreatedFrom and CreatedTo restrict the creation time of the articles, both are inclusive.
+	// A date without a time covers the whole day.
+	CreatedFrom string `form:"created_from"`
+	CreatedTo   string `form:"created_to"`
+
+	// Cursor is the opaque cursor of the page to return, it is taken from the
+	// next_cursor of the previous page.
+	Cursor string `form:"cursor"`
+	// UseCursor selects cursor pagination instead of page pagination,
+	// it is set when the cursor parameter is present, even if empty for the first page.
+	UseCursor bool `form:"-"`
+
+	// Highlight returns the fragments of the title and body matching the search.
+	Highlight    bool   `form:"highlight"`
+	FragmentSize int    `form:"fragment_size"`
+	PreTag       string `form:"pre_tag"`
+	PostTag      string `form:"post_tag"`
+
+	// View selects the projection of the articles, ArticleViewSummary leaves out the body.
+	View string `form:"view"`
+
+	// AuthorFacet returns the top authors of the matching articles with their counts.
+	AuthorFacet     bool `form:"author_facet"`
+	AuthorFacetSize int  `form:"author_facet_size"`
+	// CreatedFacet returns the number of matching articles created per interval, one of
+	// FacetIntervalDay, FacetIntervalMonth or FacetIntervalYear.
+	CreatedFacet string `form:"created_facet"`
+	// TagFacet returns the top tags of the matching articles with their counts.
+	TagFacet     bool `form:"tag_facet"`
+	TagFacetSize int  `form:"tag_facet_size"`
+}
+
+// Validate checks if the ArticleQuery is valid.
+//
+// It returns an error if the interval of the created facet is unknown,
+// and ErrPageTooDeep if the page lies beyond the pages reachable without a cursor.
+func (a *ArticleQuery) Validate() error {
+	if a.Limit < 0 || a.Page < 0 {
+		return ErrInvalidPagination
+	}
+	if !a.UseCursor && a.GetPage()*a.GetLimit() > maxListWindow {
+		return ErrPageTooDeep
+	}
+
+	switch a.CreatedFacet {
+	case "", FacetIntervalDay, FacetIntervalMonth, FacetIntervalYear:
+	default:
+		return ErrInvalidFacetInterval
+	}
+
+	switch a.MatchMode {
+	case "", MatchModeAny, MatchModeAll, MatchModePhrase:
+	default:
+		return ErrInvalidMatchMode
+	}
+
+	switch a.Status {
+	case "", StatusDraft, StatusInReview, StatusPublished, StatusArchived:
+	default:
+		return ErrInvalidStatus
+	}
+
+	if _, _, err := a.GetCreatedRange(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// GetStatus returns the status the articles are restricted to, it defaults to StatusPublished.
+func (a *ArticleQuery) GetStatus() string {
+	if a.Status == "" {
+		return StatusPublished
+	}
+	return a.Status
+}
+
+// GetMatchMode returns how the search terms must match, it defaults to MatchModeAny.
+func (a *ArticleQuery) GetMatchMode() string {
+	if a.MatchMode == "" {
+		return MatchModeAny
+	}
+	return a.MatchMode
+}
+
+// GetAuthors returns the authors the articles are restricted to, without duplicates.
+//
+// An empty slice means articles of every author.
+func (a *ArticleQuery) GetAuthors() []string {
+	return uniqueStrings(append([]string{a.Author}, a.Authors...))
+}
+
+// GetExcludeAuthors returns the authors whose articles are left out, without duplicates.
+func (a *ArticleQuery) GetExcludeAuthors() []string {
+	return uniqueStrings(a.ExcludeAuthors)
+}
+
+// GetTags returns the normalized tags the articles must all have, without duplicates.
+func (a *ArticleQuery) GetTags() []string {
+	return NormalizeTags(a.Tags)
+}
+
+// GetCategory returns the path of the category the articles are restricted to, empty for every category.
+func (a *ArticleQuery) GetCategory() string {
+	return strings.Trim(strings.ToLower(strings.TrimSpace(a.Category)), "/")
+}
+
+// GetCreatedRange returns the bounds of the creation time of the articles.
+//
+// from is inclusive and to is exclusive, a date as upper bound is moved to the
+// start of the next day so the day is included. Unset bounds are nil.
+// ErrInvalidCreatedRange is returned if a bound cannot be parsed or from is not before to.
+func (a *ArticleQuery) GetCreatedRange() (from *time.Time, to *time.Time, err error) {
+	if a.CreatedFrom != "" {
+		t, _, err := parseDateOrTime(a.CreatedFrom)
+		if err != nil {
+			return nil, nil, ErrInvalidCreatedRange
+		}
+		from = &t
+	}
+
+	if a.CreatedTo != "" {
+		t, isDate, err := parseDateOrTime(a.CreatedTo)
+		if err != nil {
+			return nil, nil, ErrInvalidCreatedRange
+		}
+		if isDate {
+			t = t.AddDate(0, 0, 1)
+		} else {
+			// the index stores the creation time with millisecond precision
+			t = t.Add(time.Millisecond)
+		}
+		to = &t
+	}
+
+	if from != nil && to != nil && !from.Before(*to) {
+		return nil, nil, ErrInvalidCreatedRange
+	}
+
+	return from, to, nil
+}
+
+// parseDateOrTime parses a date (2006-01-02) or an RFC 3339 time and reports whether it was a date.
+func parseDateOrTime(value string) (time.Time, bool, error) {
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		return t, true, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	return t, false, err
+}
+
+// uniqueStrings returns the non-empty values in order, without duplicates.
+func uniqueStrings(values []string) []string {
+	seen := map[string]bool{}
+	result := []string{}
+	for _, value := range values {
+		if value == "" || seen[value] {
+			continue
+		}
+		seen[value] = true
+		result = append(result, value)
+	}
+	return result
+}
+
+// GetAuthorFacetSize returns the number of top authors of the author facet.
+//
+// It defaults to 10 and is capped at 100.
+func (a *ArticleQuery) GetAuthorFacetSize() int {
+	if a.AuthorFacetSize <= 0 {
+		return defaultAuthorFacetSize
+	}
+	if a.AuthorFacetSize > maxAuthorFacetSize {
+		return maxAuthorFacetSize
+	}
+	return a.AuthorFacetSize
+}
+
+// GetTagFacetSize returns the number of top tags of the tag facet.
+//
+// It defaults to 10 and is capped at 100.
+func (a *ArticleQuery) GetTagFacetSize() int {
+	if a.TagFacetSize <= 0 {
+		return defaultTagFacetSize
+	}
+	if a.TagFacetSize > maxTagFacetSize {
+		return maxTagFacetSize
+	}
+	return a.TagFacetSize
+}
+
+// IsSummary reports whether the articles are returned without their body.
+func (a *ArticleQuery) IsSummary() bool {
+	return a.View == ArticleViewSummary
+}
+
+// IsHighlight reports whether highlighted fragments are returned, which requires a search.
+func (a *ArticleQuery) IsHighlight() bool {
+	return a.Highlight && a.Search != ""
+}
+
+// GetFragmentSize returns the size in characters of the highlighted fragments.
+//
+// It defaults to 150 and is capped at 1000.
+func (a *ArticleQuery) GetFragmentSize() int {
+	if a.FragmentSize <= 0 {
+		return defaultFragmentSize
+	}
+	if a.FragmentSize > maxFragmentSize {
+		return maxFragmentSize
+	}
+	return a.FragmentSize
+}
+
+// GetHighlightTags returns the tags wrapped around the highlighted terms.
+//
+// They default to <em> and </em>.
+func (a *ArticleQuery) GetHighlightTags() (string, string) {
+	if a.PreTag == "" || a.PostTag == "" {
+		return "<em>", "</em>"
+	}
+	return a.PreTag, a.PostTag
 }
 
 // GetLimit returns the limit value of the ArticleQuery for pagination purposes.
 //
-// It does not accept any parameters.
-// It returns an int value.
+// It defaults to 10 and is capped at 100.
 func (a *ArticleQuery) GetLimit() int {
-	// Set the limit value to the default value of 10 if it is not set.
-	if a.Limit == 0 {
-		a.Limit = 10
+	if a.Limit <= 0 {
+		return defaultListLimit
+	}
+	if a.Limit > maxListLimit {
+		return maxListLimit
 	}
-
 	return a.Limit
 }
 
@@ -27,8 +309,66 @@ func (a *ArticleQuery) GetLimit() int {
 // It returns an integer.
 func (a *ArticleQuery) GetPage() int {
 	// If the page value is not set, set it to the default value of 1.
-	if a.Page == 0 {
+	if a.Page <= 0 {
 		return 1
 	}
 	return a.Page
 }
+
+type ArticleSuggestQuery struct {
+	Q     string `form:"q"`
+	Limit int    `form:"limit"`
+}
+
+// Validate checks if the ArticleSuggestQuery is valid.
+//
+// It returns an error if the prefix to suggest for is missing.
+func (a *ArticleSuggestQuery) Validate() error {
+	if strings.TrimSpace(a.Q) == "" {
+		return ErrSuggestQueryRequired
+	}
+	return nil
+}
+
+// GetLimit returns the maximum number of titles and of authors suggested.
+//
+// It defaults to 5 and is capped at 20.
+func (a *ArticleSuggestQuery) GetLimit() int {
+	if a.Limit <= 0 {
+		return defaultSuggestLimit
+	}
+	if a.Limit > maxSuggestLimit {
+		return maxSuggestLimit
+	}
+	return a.Limit
+}
+
+type ArticleRelatedQuery struct {
+	// ID is the article the related articles are similar to, it is taken from the path.
+	ID    int `form:"-"`
+	Limit int `form:"limit"`
+	// SameAuthor ranks the related articles of the same author higher.
+	SameAuthor bool `form:"same_author"`
+}
+
+// GetLimit returns the maximum number of related articles.
+//
+// It defaults to 5 and is capped at 20.
+func (a *ArticleRelatedQuery) GetLimit() int {
+	if a.Limit <= 0 {
+		return defaultRelatedLimit
+	}
+	if a.Limit > maxRelatedLimit {
+		return maxRelatedLimit
+	}
+	return a.Limit
+}
+
+type ArticleRevisionDiffQuery struct {
+	// ID and Revision are the article and the revision the diff ends at, they are taken from the path.
+	ID       int `form:"-"`
+	Revision int `form:"-"`
+
+	// From is the revision the diff starts from, the previous revision by default.
+	From int `form:"from"`
+}
@@ -2,6 +2,7 @@ package article_test
 
 import (
 	"testing"
+	"time"
 
 	"github.com/undercode99/article_service/internal/app/article"
 
@@ -11,9 +12,10 @@ import (
 
 // TestGetLimit is a unit test for the GetLimit function.
 //
-// It tests two cases:
+// It tests three cases:
 //  1. When the limit is not set, the default value should be returned.
 //  2. When the limit is set, the set value should be returned.
+//  3. When the limit is above the maximum, the maximum should be returned.
 func TestGetLimit(t *testing.T) {
 	// Test case 1: Limit is not set, default value should be returned
 	a := &article.ArticleQuery{}
@@ -26,6 +28,13 @@ func TestGetLimit(t *testing.T) {
 	}
 	expected = 20
 	assert.Equal(t, expected, a.GetLimit())
+
+	// Test case 3: Limit is above the maximum, should return the maximum
+	a = &article.ArticleQuery{
+		Limit: 1000,
+	}
+	expected = 100
+	assert.Equal(t, expected, a.GetLimit())
 }
 
 // TestGetPage is a unit test for the GetPage function.
@@ -65,3 +74,108 @@ func TestGetPage(t *testing.T) {
 		})
 	}
 }
+
+// TestArticleQuery_Highlight tests the highlight options of the ArticleQuery.
+//
+// It checks that highlighting requires a search, and the defaults and bounds of the fragment size and tags.
+func TestArticleQuery_Highlight(t *testing.T) {
+	qry := &article.ArticleQuery{Highlight: true}
+	assert.False(t, qry.IsHighlight(), "Expected no highlight without a search")
+
+	qry.Search = "golang"
+	assert.True(t, qry.IsHighlight())
+	assert.Equal(t, 150, qry.GetFragmentSize())
+
+	qry.FragmentSize = 5000
+	assert.Equal(t, 1000, qry.GetFragmentSize())
+
+	preTag, postTag := qry.GetHighlightTags()
+	assert.Equal(t, "<em>", preTag)
+	assert.Equal(t, "</em>", postTag)
+
+	qry.PreTag, qry.PostTag = "<mark>", "</mark>"
+	preTag, postTag = qry.GetHighlightTags()
+	assert.Equal(t, "<mark>", preTag)
+	assert.Equal(t, "</mark>", postTag)
+}
+
+// TestArticleQuery_Validate tests the Validate function.
+//
+// It checks that only the day, month and year intervals are accepted for the created facet,
+// only the statuses of the lifecycle for the status, and only the pages reachable without a cursor.
+func TestArticleQuery_Validate(t *testing.T) {
+	for _, interval := range []string{"", "day", "month", "year"} {
+		qry := &article.ArticleQuery{CreatedFacet: interval}
+		assert.NoError(t, qry.Validate(), "Expected interval %q to be valid", interval)
+	}
+
+	qry := &article.ArticleQuery{CreatedFacet: "week"}
+	assert.Equal(t, article.ErrInvalidFacetInterval, qry.Validate())
+
+	qry = &article.ArticleQuery{Status: "hidden"}
+	assert.Equal(t, article.ErrInvalidStatus, qry.Validate())
+	assert.Equal(t, article.StatusPublished, (&article.ArticleQuery{}).GetStatus())
+
+	qry = &article.ArticleQuery{Limit: -1}
+	assert.Equal(t, article.ErrInvalidPagination, qry.Validate())
+
+	qry = &article.ArticleQuery{Limit: 100, Page: 100}
+	assert.NoError(t, qry.Validate())
+
+	qry = &article.ArticleQuery{Limit: 100, Page: 101}
+	assert.Equal(t, article.ErrPageTooDeep, qry.Validate())
+
+	qry.UseCursor = true
+	assert.NoError(t, qry.Validate())
+}
+
+// TestArticleQuery_Filters tests the filter getters of the ArticleQuery.
+//
+// 1. Test the authors are merged with the single author without duplicates.
+// 2. Test a date upper bound includes the whole day and a time upper bound includes the time.
+// 3. Test invalid match modes and created ranges are rejected.
+func TestArticleQuery_Filters(t *testing.T) {
+	qry := &article.ArticleQuery{Author: "John Doe", Authors: []string{"Jane Doe", "John Doe", ""}}
+	assert.Equal(t, []string{"John Doe", "Jane Doe"}, qry.GetAuthors())
+	assert.Equal(t, []string{}, (&article.ArticleQuery{}).GetAuthors())
+	assert.Equal(t, article.MatchModeAny, qry.GetMatchMode())
+
+	qry = &article.ArticleQuery{CreatedFrom: "2023-07-01", CreatedTo: "2023-09-30"}
+	from, to, err := qry.GetCreatedRange()
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2023, 7, 1, 0, 0, 0, 0, time.UTC), *from)
+	assert.Equal(t, time.Date(2023, 10, 1, 0, 0, 0, 0, time.UTC), *to)
+
+	qry = &article.ArticleQuery{CreatedTo: "2023-09-30T12:00:00Z"}
+	from, to, err = qry.GetCreatedRange()
+	assert.NoError(t, err)
+	assert.Nil(t, from)
+	assert.Equal(t, time.Date(2023, 9, 30, 12, 0, 0, int(time.Millisecond), time.UTC), *to)
+
+	qry = &article.ArticleQuery{Tags: []string{" Golang", "golang ", ""}, Category: " /Tech/Golang/ "}
+	assert.Equal(t, []string{"golang"}, qry.GetTags())
+	assert.Equal(t, "tech/golang", qry.GetCategory())
+	assert.Equal(t, 10, qry.GetTagFacetSize())
+	assert.Equal(t, 100, (&article.ArticleQuery{TagFacetSize: 500}).GetTagFacetSize())
+
+	assert.Equal(t, article.ErrInvalidMatchMode, (&article.ArticleQuery{MatchMode: "fuzzy"}).Validate())
+	assert.Equal(t, article.ErrInvalidCreatedRange, (&article.ArticleQuery{CreatedFrom: "yesterday"}).Validate())
+	assert.Equal(t, article.ErrInvalidCreatedRange, (&article.ArticleQuery{CreatedFrom: "2023-10-01", CreatedTo: "2023-09-30"}).Validate())
+}
+
+// TestArticleSuggestQuery tests the Validate and GetLimit functions of the ArticleSuggestQuery.
+func TestArticleSuggestQuery(t *testing.T) {
+	assert.Equal(t, article.ErrSuggestQueryRequired, (&article.ArticleSuggestQuery{Q: "  "}).Validate())
+	assert.NoError(t, (&article.ArticleSuggestQuery{Q: "go"}).Validate())
+
+	assert.Equal(t, 5, (&article.ArticleSuggestQuery{}).GetLimit())
+	assert.Equal(t, 8, (&article.ArticleSuggestQuery{Limit: 8}).GetLimit())
+	assert.Equal(t, 20, (&article.ArticleSuggestQuery{Limit: 50}).GetLimit())
+}
+
+// TestArticleRelatedQuery_GetLimit tests the GetLimit function of the ArticleRelatedQuery.
+func TestArticleRelatedQuery_GetLimit(t *testing.T) {
+	assert.Equal(t, 5, (&article.ArticleRelatedQuery{}).GetLimit())
+	assert.Equal(t, 8, (&article.ArticleRelatedQuery{Limit: 8}).GetLimit())
+	assert.Equal(t, 20, (&article.ArticleRelatedQuery{Limit: 50}).GetLimit())
+}
@@ -0,0 +1,44 @@
+package article
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrRevisionNotFound = errors.New("article revision not found")
+)
+
+// ArticleRevision is an immutable snapshot of the title and body of an article.
+//
+// A revision is written with every version of the article, in the transaction
+// of the change, so the revision number is the version of the article it records.
+type ArticleRevision struct {
+	ID        int       `json:"-"`
+	ArticleID int       `json:"article_id" gorm:"not null;uniqueIndex:idx_article_revisions_article_revision"`
+	Revision  int       `json:"revision" gorm:"not null;uniqueIndex:idx_article_revisions_article_revision"`
+	Title     string    `json:"title"`
+	Body      string    `json:"body,omitempty"`
+	Editor    string    `json:"editor"`
+	Note      string    `json:"note"`
+	Created   time.Time `json:"created"`
+}
+
+// NewArticleRevision creates the revision of the current title and body of the article.
+//
+// The article ID and revision number are set by the repository saving the change,
+// once the article has its ID and new version.
+func NewArticleRevision(item *Article, editor, note string) *ArticleRevision {
+	return &ArticleRevision{
+		Title:   item.Title,
+		Body:    item.Body,
+		Editor:  editor,
+		Note:    note,
+		Created: item.Updated,
+	}
+}
+
+type ArticleRevisionRepository interface {
+	GetArticleRevisions(articleID int) ([]ArticleRevision, error)
+	GetArticleRevision(articleID, revision int) (*ArticleRevision, error)
+}
@@ -0,0 +1,55 @@
+package article
+
+import (
+	"errors"
+	"time"
+
+	"github.com/undercode99/article_service/internal/slugging"
+)
+
+var (
+	ErrSlugUnavailable = errors.New("no slug available for the article")
+)
+
+const (
+	// maxSlugLength is the maximum length of the slug generated from a title, before any collision suffix.
+	maxSlugLength = 80
+	// defaultSlug is the slug of the titles without any letter or digit to transliterate.
+	defaultSlug = "article"
+)
+
+// ArticleSlug is a slug an article is reachable by.
+//
+// The current slug of an article is its Slug, the former ones are kept to redirect
+// to it. A slug is never released, even once the article is purged, so its URL
+// never leads to another article.
+type ArticleSlug struct {
+	ID        int       `json:"-"`
+	ArticleID int       `json:"article_id" gorm:"not null;index"`
+	Slug      string    `json:"slug" gorm:"not null;uniqueIndex"`
+	Created   time.Time `json:"created"`
+}
+
+// NewArticleSlug creates the slug of the article with the given ID.
+func NewArticleSlug(articleID int, slug string) *ArticleSlug {
+	return &ArticleSlug{
+		ArticleID: articleID,
+		Slug:      slug,
+		Created:   time.Now(),
+	}
+}
+
+// NewSlug returns the slug of a title, before any suffix making it unique.
+//
+// The title is transliterated to lowercase ASCII words separated by hyphens,
+// a title without anything to transliterate gets the slug "article".
+func NewSlug(title string) string {
+	if slug := slugging.Slugify(title, maxSlugLength); slug != "" {
+		return slug
+	}
+	return defaultSlug
+}
+
+type ArticleSlugRepository interface {
+	GetArticleSlug(slug string) (*ArticleSlug, error)
+}
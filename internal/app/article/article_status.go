@@ -0,0 +1,190 @@
+package article
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrUnknownTransition   = errors.New("transition must be submit, withdraw, reject, publish, archive or reopen")
+	ErrInvalidTransition   = errors.New("transition not allowed from the status of the article")
+	ErrTransitionForbidden = errors.New("transition not allowed for the user")
+	ErrInvalidStatus       = errors.New("status must be draft, in_review, published or archived")
+	ErrStatusForbidden     = errors.New("only editors and the author may list unpublished articles")
+	ErrEditForbidden       = errors.New("only editors and the author of a draft may change the article")
+	ErrDeleteForbidden     = errors.New("only editors and the author may delete the article")
+	ErrAuthorForbidden     = errors.New("only editors may change the author of an article")
+	ErrRestoreForbidden    = errors.New("only editors and the author may restore the article")
+	ErrPurgeForbidden      = errors.New("only editors may purge an article")
+	ErrUserRequired        = errors.New("the user making the request is required")
+)
+
+const (
+	// StatusDraft, StatusInReview, StatusPublished and StatusArchived are the statuses of the
+	// lifecycle of an article, only the published articles are public.
+	StatusDraft     = "draft"
+	StatusInReview  = "in_review"
+	StatusPublished = "published"
+	StatusArchived  = "archived"
+
+	// RoleEditor and RoleAdmin are the roles reviewing and publishing the articles of every author,
+	// any other role is an author.
+	RoleEditor = "editor"
+	RoleAdmin  = "admin"
+
+	TransitionSubmit   = "submit"
+	TransitionWithdraw = "withdraw"
+	TransitionReject   = "reject"
+	TransitionPublish  = "publish"
+	TransitionArchive  = "archive"
+	TransitionReopen   = "reopen"
+
+	// SchedulerEditor is recorded as the editor of the publications of the scheduler.
+	SchedulerEditor = "scheduler"
+)
+
+// ArticleTransition is a move of an article from one status to another.
+type ArticleTransition struct {
+	// From are the statuses the transition starts from.
+	From []string
+	// To is the status of the article after the transition.
+	To string
+	// ByAuthor allows the author of the article to perform the transition, the editors always may.
+	ByAuthor bool
+	// Note is recorded in the revision of the transition unless the command has one.
+	Note string
+}
+
+// ArticleTransitions are the transitions of the lifecycle of an article keyed by name.
+//
+// An author writes a draft and submits it for review, an editor then rejects or publishes
+// it, at once or at a later time. The published articles are archived by the editors,
+// who can reopen them as drafts.
+var ArticleTransitions = map[string]ArticleTransition{
+	TransitionSubmit:   {From: []string{StatusDraft}, To: StatusInReview, ByAuthor: true, Note: "Submitted for review"},
+	TransitionWithdraw: {From: []string{StatusInReview}, To: StatusDraft, ByAuthor: true, Note: "Withdrawn from review"},
+	TransitionReject:   {From: []string{StatusInReview}, To: StatusDraft, Note: "Rejected"},
+	TransitionPublish:  {From: []string{StatusInReview}, To: StatusPublished, Note: "Published"},
+	TransitionArchive:  {From: []string{StatusPublished}, To: StatusArchived, Note: "Archived"},
+	TransitionReopen:   {From: []string{StatusArchived}, To: StatusDraft, Note: "Reopened as draft"},
+}
+
+// Actor is the user making a request and their role, as set by the gateway.
+type Actor struct {
+	User string
+	Role string
+}
+
+// IsAnonymous reports whether the request names no user.
+func (a Actor) IsAnonymous() bool {
+	return a.User == ""
+}
+
+// IsEditor reports whether the actor reviews and publishes the articles of every author.
+func (a Actor) IsEditor() bool {
+	return a.Role == RoleEditor || a.Role == RoleAdmin
+}
+
+// IsAuthorOf reports whether the actor is the author of the article.
+func (a Actor) IsAuthorOf(item *Article) bool {
+	return a.User != "" && a.User == item.Author
+}
+
+// CanView reports whether the actor may read the article.
+//
+// The published articles are public, the others are only visible to their author and the editors.
+func (a Actor) CanView(item *Article) bool {
+	return item.IsPublished() || a.IsEditor() || a.IsAuthorOf(item)
+}
+
+// CanEdit reports whether the actor may change the content of the article.
+//
+// The editors change every article, the authors only their drafts: a change to an
+// article in review or published would otherwise reach the readers unreviewed.
+func (a Actor) CanEdit(item *Article) bool {
+	return a.IsEditor() || (a.IsAuthorOf(item) && item.Status == StatusDraft)
+}
+
+// CanDelete reports whether the actor may delete the article, the editors and its author may.
+func (a Actor) CanDelete(item *Article) bool {
+	return a.IsEditor() || a.IsAuthorOf(item)
+}
+
+// CanTransition reports whether the actor may move the article through the named transition,
+// the editors perform every transition and the author those allowed to authors.
+func (a Actor) CanTransition(item *Article, name string) bool {
+	transition, ok := ArticleTransitions[name]
+	return ok && (a.IsEditor() || (transition.ByAuthor && a.IsAuthorOf(item)))
+}
+
+// CanList reports whether the actor may list the articles of the query.
+//
+// The published articles are listed by everyone, the others only by the editors,
+// or by an author restricting the list to their own articles.
+func (a Actor) CanList(qry *ArticleQuery) bool {
+	if qry.GetStatus() == StatusPublished || a.IsEditor() {
+		return true
+	}
+
+	authors := qry.GetAuthors()
+	return a.User != "" && len(authors) == 1 && authors[0] == a.User
+}
+
+// IsPublished reports whether the article is public.
+func (a *Article) IsPublished() bool {
+	return a.Status == StatusPublished
+}
+
+// IsScheduled reports whether the article waits in review for its publication time.
+func (a *Article) IsScheduled() bool {
+	return a.Status == StatusInReview && a.PublishAt != nil
+}
+
+// Transition moves the article through the transition of the command.
+//
+// A publication with a publish time in the future keeps the article in review until
+// the scheduler publishes it, otherwise the publish time is the time of the publication.
+// Leaving the review or the archive clears the publish time.
+// ErrUnknownTransition is returned if the transition does not exist, ErrTransitionForbidden
+// if the actor may not perform it and ErrInvalidTransition if the article is in another status.
+func (a *Article) Transition(cmd *ArticleTransitionCommand) error {
+	transition, ok := ArticleTransitions[cmd.Transition]
+	if !ok {
+		return ErrUnknownTransition
+	}
+	if !cmd.Actor.CanTransition(a, cmd.Transition) {
+		return ErrTransitionForbidden
+	}
+	if !containsString(transition.From, a.Status) {
+		return ErrInvalidTransition
+	}
+
+	now := time.Now()
+	switch transition.To {
+	case StatusPublished:
+		if cmd.PublishAt != nil && cmd.PublishAt.After(now) {
+			publishAt := *cmd.PublishAt
+			a.PublishAt = &publishAt
+			// the article stays in review until the scheduler publishes it
+			a.Updated = now
+			return nil
+		}
+		a.PublishAt = &now
+	case StatusDraft:
+		a.PublishAt = nil
+	}
+
+	a.Status = transition.To
+	a.Updated = now
+	return nil
+}
+
+// containsString reports whether the value is one of the values.
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
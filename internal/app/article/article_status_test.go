@@ -0,0 +1,85 @@
+package article_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/undercode99/article_service/internal/app/article"
+)
+
+// TestArticle_Transition tests the Transition method of the Article.
+//
+// It walks an article through its lifecycle and checks who may perform each transition.
+func TestArticle_Transition(t *testing.T) {
+	author := article.Actor{User: "John Doe"}
+	editor := article.Actor{User: "Jane Doe", Role: article.RoleEditor}
+	item := article.NewArticle(&article.ArticleCreateCommand{Author: "John Doe", Title: "Title", Body: "Body"})
+	assert.Equal(t, article.StatusDraft, item.Status)
+
+	steps := []struct {
+		transition string
+		actor      article.Actor
+		err        error
+		status     string
+	}{
+		{transition: article.TransitionPublish, actor: editor, err: article.ErrInvalidTransition, status: article.StatusDraft},
+		{transition: article.TransitionSubmit, actor: article.Actor{User: "Jim Doe"}, err: article.ErrTransitionForbidden, status: article.StatusDraft},
+		{transition: article.TransitionSubmit, actor: author, status: article.StatusInReview},
+		{transition: article.TransitionPublish, actor: author, err: article.ErrTransitionForbidden, status: article.StatusInReview},
+		{transition: article.TransitionPublish, actor: editor, status: article.StatusPublished},
+		{transition: article.TransitionArchive, actor: article.Actor{User: "Jill Doe", Role: article.RoleAdmin}, status: article.StatusArchived},
+		{transition: article.TransitionReopen, actor: editor, status: article.StatusDraft},
+		{transition: "delete", actor: editor, err: article.ErrUnknownTransition, status: article.StatusDraft},
+	}
+
+	for _, step := range steps {
+		err := item.Transition(&article.ArticleTransitionCommand{Transition: step.transition, Actor: step.actor})
+		assert.Equal(t, step.err, err, step.transition)
+		assert.Equal(t, step.status, item.Status, step.transition)
+	}
+	assert.Nil(t, item.PublishAt)
+}
+
+// TestArticle_Transition_Scheduled tests a publication in the future keeps the article in review.
+func TestArticle_Transition_Scheduled(t *testing.T) {
+	editor := article.Actor{User: "Jane Doe", Role: article.RoleEditor}
+	item := &article.Article{Author: "John Doe", Status: article.StatusInReview}
+
+	publishAt := time.Now().Add(time.Hour)
+	assert.NoError(t, item.Transition(&article.ArticleTransitionCommand{Transition: article.TransitionPublish, PublishAt: &publishAt, Actor: editor}))
+	assert.True(t, item.IsScheduled())
+	assert.Equal(t, publishAt, *item.PublishAt)
+
+	// withdrawing the article cancels its publication
+	assert.NoError(t, item.Transition(&article.ArticleTransitionCommand{Transition: article.TransitionWithdraw, Actor: article.Actor{User: "John Doe"}}))
+	assert.Equal(t, article.StatusDraft, item.Status)
+	assert.Nil(t, item.PublishAt)
+}
+
+// TestActor tests the visibility of the articles and lists for the actors.
+func TestActor(t *testing.T) {
+	draft := &article.Article{Author: "John Doe", Status: article.StatusDraft}
+	published := &article.Article{Author: "John Doe", Status: article.StatusPublished}
+
+	assert.True(t, article.Actor{}.CanView(published))
+	assert.False(t, article.Actor{}.CanView(draft))
+	assert.False(t, article.Actor{User: "Jim Doe"}.CanView(draft))
+	assert.True(t, article.Actor{User: "John Doe"}.CanView(draft))
+	assert.True(t, article.Actor{Role: article.RoleEditor}.CanView(draft))
+
+	assert.False(t, article.Actor{}.CanEdit(draft))
+	assert.False(t, article.Actor{User: "Jim Doe"}.CanEdit(draft))
+	assert.True(t, article.Actor{User: "John Doe"}.CanEdit(draft))
+	assert.False(t, article.Actor{User: "John Doe"}.CanEdit(published))
+	assert.True(t, article.Actor{Role: article.RoleEditor}.CanEdit(published))
+	assert.True(t, article.Actor{User: "John Doe"}.CanDelete(published))
+	assert.False(t, article.Actor{User: "Jim Doe"}.CanDelete(published))
+
+	assert.True(t, article.Actor{}.CanList(&article.ArticleQuery{}))
+	assert.False(t, article.Actor{}.CanList(&article.ArticleQuery{Status: article.StatusDraft}))
+	assert.False(t, article.Actor{User: "John Doe"}.CanList(&article.ArticleQuery{Status: article.StatusDraft}))
+	assert.False(t, article.Actor{User: "John Doe"}.CanList(&article.ArticleQuery{Status: article.StatusDraft, Authors: []string{"John Doe", "Jim Doe"}}))
+	assert.True(t, article.Actor{User: "John Doe"}.CanList(&article.ArticleQuery{Status: article.StatusDraft, Author: "John Doe"}))
+	assert.True(t, article.Actor{Role: article.RoleAdmin}.CanList(&article.ArticleQuery{Status: article.StatusArchived}))
+}
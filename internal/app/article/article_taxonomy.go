@@ -0,0 +1,204 @@
+package article
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/undercode99/article_service/internal/slugging"
+)
+
+var (
+	ErrInvalidTag             = errors.New("tags must be 1 to 50 characters, at most 20 per article")
+	ErrTagNotFound            = errors.New("tag not found")
+	ErrTagExists              = errors.New("tag already exists, merge the tags instead")
+	ErrTagMergeSelf           = errors.New("tag cannot be merged into itself")
+	ErrCategoryNameMissing    = errors.New("category name is required")
+	ErrCategoryNotFound       = errors.New("category not found")
+	ErrCategoryParentNotFound = errors.New("parent category not found")
+	ErrCategoryExists         = errors.New("category already exists under the parent")
+	ErrCategoryCycle          = errors.New("category cannot be moved under itself")
+	ErrCategoryNotEmpty       = errors.New("category still has subcategories or articles")
+	ErrTaxonomyForbidden      = errors.New("only editors may manage the tags and categories")
+)
+
+const (
+	// maxTagLength is the maximum length in characters of a tag name.
+	maxTagLength = 50
+	// maxArticleTags is the maximum number of tags of an article.
+	maxArticleTags = 20
+	// defaultCategorySlug is the slug of the category names without any letter or digit to transliterate.
+	defaultCategorySlug = "category"
+
+	// TaxonomyEditor is recorded as the editor of the revisions of the articles changed
+	// by a change of their tags or category.
+	TaxonomyEditor = "taxonomy"
+)
+
+// Tag is a label shared by any number of articles.
+//
+// The name is normalized by NormalizeTag so the tags differing only by case or
+// spacing are the same tag.
+type Tag struct {
+	ID      int       `json:"id"`
+	Name    string    `json:"name" gorm:"not null;uniqueIndex"`
+	Created time.Time `json:"created"`
+}
+
+// NewTag creates the tag of the given name.
+func NewTag(name string) *Tag {
+	return &Tag{
+		Name:    NormalizeTag(name),
+		Created: time.Now(),
+	}
+}
+
+// ArticleTag links an article to one of its tags.
+type ArticleTag struct {
+	ArticleID int `gorm:"primaryKey;autoIncrement:false"`
+	TagID     int `gorm:"primaryKey;autoIncrement:false;index"`
+}
+
+// NormalizeTag returns the name of a tag in lowercase, with its words separated by single spaces.
+func NormalizeTag(name string) string {
+	return strings.Join(strings.Fields(strings.ToLower(name)), " ")
+}
+
+// NormalizeTags returns the normalized names of the tags in order, without empty names and duplicates.
+func NormalizeTags(names []string) []string {
+	normalized := make([]string, len(names))
+	for i, name := range names {
+		normalized[i] = NormalizeTag(name)
+	}
+	return uniqueStrings(normalized)
+}
+
+// validateTags checks the number of tags of an article and the length of their names.
+func validateTags(names []string) error {
+	if len(names) > maxArticleTags {
+		return ErrInvalidTag
+	}
+	for _, name := range names {
+		if err := validateTag(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateTag checks the normalized name of a tag is not empty nor too long.
+func validateTag(name string) error {
+	length := len([]rune(NormalizeTag(name)))
+	if length == 0 || length > maxTagLength {
+		return ErrInvalidTag
+	}
+	return nil
+}
+
+// Category is a node of the category tree the articles are filed under.
+//
+// Path is the slug of the name of the category appended to the path of its parent,
+// such as "tech/golang", it identifies the category in the queries and the index.
+// An article of a category is also an article of every ancestor of the category.
+type Category struct {
+	ID       int       `json:"id"`
+	ParentID *int      `json:"parent_id" gorm:"index"`
+	Name     string    `json:"name" gorm:"not null"`
+	Path     string    `json:"path" gorm:"not null;uniqueIndex"`
+	Created  time.Time `json:"created"`
+	Updated  time.Time `json:"updated"`
+
+	// Children are the subcategories of the category when it is returned as a tree.
+	Children []*Category `json:"children,omitempty" gorm:"-"`
+}
+
+// NewCategory creates the category of the command, its path is set by MoveTo.
+func NewCategory(cmd *CategoryCommand) *Category {
+	now := time.Now()
+	return &Category{
+		Name:    strings.TrimSpace(cmd.Name),
+		Created: now,
+		Updated: now,
+	}
+}
+
+// MoveTo renames the category and files it under the parent, a nil parent makes it a root category.
+//
+// ErrCategoryCycle is returned if the parent is the category itself or one of its subcategories.
+func (c *Category) MoveTo(name string, parent *Category) error {
+	if parent != nil && c.Path != "" && c.Contains(parent.Path) {
+		return ErrCategoryCycle
+	}
+
+	slug := slugging.Slugify(name, maxSlugLength)
+	if slug == "" {
+		slug = defaultCategorySlug
+	}
+
+	c.Name = strings.TrimSpace(name)
+	c.ParentID = nil
+	c.Path = slug
+	if parent != nil {
+		c.ParentID = &parent.ID
+		c.Path = parent.Path + "/" + slug
+	}
+	c.Updated = time.Now()
+	return nil
+}
+
+// Contains reports whether the path is the path of the category or of one of its subcategories.
+func (c *Category) Contains(path string) bool {
+	return path == c.Path || strings.HasPrefix(path, c.Path+"/")
+}
+
+// NewCategoryTree returns the root categories with their subcategories, each level sorted by name.
+func NewCategoryTree(categories []Category) []*Category {
+	nodes := make(map[int]*Category, len(categories))
+	for i := range categories {
+		node := categories[i]
+		node.Children = nil
+		nodes[node.ID] = &node
+	}
+
+	roots := []*Category{}
+	for i := range categories {
+		node := nodes[categories[i].ID]
+		if node.ParentID != nil {
+			if parent, ok := nodes[*node.ParentID]; ok {
+				parent.Children = append(parent.Children, node)
+				continue
+			}
+		}
+		roots = append(roots, node)
+	}
+
+	var sortNodes func(nodes []*Category)
+	sortNodes = func(nodes []*Category) {
+		sort.Slice(nodes, func(i, j int) bool { return nodes[i].Name < nodes[j].Name })
+		for _, node := range nodes {
+			sortNodes(node.Children)
+		}
+	}
+	sortNodes(roots)
+
+	return roots
+}
+
+// ArticleTaxonomyRepository stores the tags and categories.
+//
+// The changes of a tag or category affecting the indexed articles return the IDs of
+// those articles, their documents are replaced through the outbox.
+type ArticleTaxonomyRepository interface {
+	GetTags() ([]Tag, error)
+	GetTag(id int) (*Tag, error)
+	CreateTag(tag *Tag) error
+	UpdateTag(tag *Tag) ([]int, error)
+	MergeTag(tag *Tag, into *Tag) ([]int, error)
+	DeleteTag(id int) ([]int, error)
+	GetCategories() ([]Category, error)
+	GetCategory(id int) (*Category, error)
+	CreateCategory(category *Category) error
+	UpdateCategory(category *Category, previousPath string) ([]int, error)
+	DeleteCategory(id int) error
+}
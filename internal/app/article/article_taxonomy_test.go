@@ -0,0 +1,96 @@
+package article_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/undercode99/article_service/internal/app/article"
+)
+
+// TestNormalizeTags tests that the tags differing only by case or spacing are the same tag.
+func TestNormalizeTags(t *testing.T) {
+	assert.Equal(t, "web development", article.NormalizeTag("  Web \t Development "))
+	assert.Equal(t, []string{"golang", "web development"}, article.NormalizeTags([]string{"Golang", "web  development", "GOLANG", " "}))
+	assert.Equal(t, []string{}, article.NormalizeTags(nil))
+}
+
+// TestTagCommand_Validate tests the length of the tag names and the merge of a tag into itself.
+func TestTagCommand_Validate(t *testing.T) {
+	assert.NoError(t, (&article.TagCommand{Name: "Golang"}).Validate())
+	assert.Equal(t, article.ErrInvalidTag, (&article.TagCommand{Name: "  "}).Validate())
+	assert.Equal(t, article.ErrInvalidTag, (&article.TagCommand{Name: strings.Repeat("a", 51)}).Validate())
+	assert.Equal(t, article.ErrTagMergeSelf, (&article.TagMergeCommand{ID: 1, Into: 1}).Validate())
+	assert.Equal(t, article.ErrCategoryNameMissing, (&article.CategoryCommand{Name: " "}).Validate())
+
+	tags := make([]string, 21)
+	for i := range tags {
+		tags[i] = strings.Repeat("a", i+1)
+	}
+	cmd := &article.ArticleUpdateCommand{Author: "John Doe", Title: "Title", Body: "Body", Tags: tags}
+	assert.Equal(t, article.ErrInvalidTag, cmd.Validate())
+}
+
+// TestCategory_MoveTo tests the paths of the categories and that a category cannot be moved under itself.
+func TestCategory_MoveTo(t *testing.T) {
+	tech := article.NewCategory(&article.CategoryCommand{Name: "Tech"})
+	assert.NoError(t, tech.MoveTo("Tech", nil))
+	tech.ID = 1
+	assert.Equal(t, "tech", tech.Path)
+	assert.Nil(t, tech.ParentID)
+
+	golang := article.NewCategory(&article.CategoryCommand{Name: "Go Lang"})
+	assert.NoError(t, golang.MoveTo("Go Lang", tech))
+	golang.ID = 2
+	assert.Equal(t, "tech/go-lang", golang.Path)
+	assert.Equal(t, 1, *golang.ParentID)
+
+	assert.Equal(t, article.ErrCategoryCycle, tech.MoveTo("Tech", tech))
+	assert.Equal(t, article.ErrCategoryCycle, tech.MoveTo("Tech", golang))
+	assert.Equal(t, "tech", tech.Path)
+
+	// a sibling sharing the prefix of the path is not a subcategory
+	technology := &article.Category{ID: 3, Path: "technology"}
+	assert.NoError(t, tech.MoveTo("Tech", technology))
+	assert.Equal(t, "technology/tech", tech.Path)
+
+	assert.NoError(t, golang.MoveTo("★", nil))
+	assert.Equal(t, "category", golang.Path)
+}
+
+// TestNewCategoryTree tests that the categories are nested under their parents and sorted by name.
+func TestNewCategoryTree(t *testing.T) {
+	one, two := 1, 2
+	tree := article.NewCategoryTree([]article.Category{
+		{ID: 3, ParentID: &one, Name: "Rust", Path: "tech/rust"},
+		{ID: 1, Name: "Tech", Path: "tech"},
+		{ID: 2, ParentID: &one, Name: "Golang", Path: "tech/golang"},
+		{ID: 4, Name: "Lifestyle", Path: "lifestyle"},
+		{ID: 5, ParentID: &two, Name: "Generics", Path: "tech/golang/generics"},
+	})
+
+	assert.Len(t, tree, 2)
+	assert.Equal(t, "Lifestyle", tree[0].Name)
+	assert.Equal(t, "Tech", tree[1].Name)
+	assert.Len(t, tree[1].Children, 2)
+	assert.Equal(t, "Golang", tree[1].Children[0].Name)
+	assert.Equal(t, "Rust", tree[1].Children[1].Name)
+	assert.Equal(t, "Generics", tree[1].Children[0].Children[0].Name)
+}
+
+// TestArticle_Update_Taxonomy tests that an update replaces the tags and category of the article.
+func TestArticle_Update_Taxonomy(t *testing.T) {
+	one, two := 1, 2
+	item := article.NewArticle(&article.ArticleCreateCommand{Author: "John Doe", Title: "Title", Body: "Body", Tags: []string{"Golang"}, CategoryID: &one})
+	assert.Equal(t, []string{"golang"}, item.Tags)
+	item.Category = "tech"
+
+	item.Update(&article.ArticleUpdateCommand{Author: "John Doe", Title: "Title", Body: "Body", Tags: []string{"Rust"}, CategoryID: &one})
+	assert.Equal(t, []string{"rust"}, item.Tags)
+	assert.Equal(t, "tech", item.Category)
+
+	item.Update(&article.ArticleUpdateCommand{Author: "John Doe", Title: "Title", Body: "Body", CategoryID: &two})
+	assert.Empty(t, item.Tags)
+	assert.Equal(t, 2, *item.CategoryID)
+	assert.Empty(t, item.Category)
+}
@@ -1,7 +1,9 @@
 package article_test
 
 import (
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/undercode99/article_service/internal/app/article"
 
@@ -26,6 +28,8 @@ func TestNewArticle(t *testing.T) {
 	assert.Equal(t, cmd.Author, newArticle.Author, "Expected author to be %s", cmd.Author)
 	assert.Equal(t, cmd.Title, newArticle.Title, "Expected title to be %s", cmd.Title)
 	assert.Equal(t, cmd.Body, newArticle.Body, "Expected body to be %s", cmd.Body)
+	assert.Equal(t, newArticle.Created, newArticle.Updated, "Expected updated to be the creation time")
+	assert.Equal(t, 1, newArticle.Version, "Expected the first version")
 
 	// Test case 2: Create a new article with empty command values
 	cmd = &article.ArticleCreateCommand{}
@@ -34,3 +38,94 @@ func TestNewArticle(t *testing.T) {
 	assert.Empty(t, newArticle.Title, "Expected title to be empty")
 	assert.Empty(t, newArticle.Body, "Expected body to be empty")
 }
+
+// TestArticle_Update tests the Update method.
+//
+// It verifies that the editable fields are replaced with the command values
+// and the update timestamp is bumped, while the ID and creation timestamp are kept.
+func TestArticle_Update(t *testing.T) {
+	created := time.Now()
+	item := &article.Article{ID: 1, Author: "John Doe", Title: "Old Title", Body: "Old body.", Created: created, Updated: created}
+
+	item.Update(&article.ArticleUpdateCommand{ID: 1, Author: "Jane Doe", Title: "New Title", Body: "New body."})
+
+	assert.Equal(t, 1, item.ID)
+	assert.Equal(t, "Jane Doe", item.Author)
+	assert.Equal(t, "New Title", item.Title)
+	assert.Equal(t, "New body.", item.Body)
+	assert.Equal(t, created, item.Created)
+	assert.True(t, item.Updated.After(created), "Expected updated to be bumped")
+}
+
+// TestArticle_Update_Slug tests the slug is only cleared, to be given again, when the slug of the title changes.
+func TestArticle_Update_Slug(t *testing.T) {
+	item := &article.Article{ID: 1, Author: "John Doe", Title: "Old Title", Slug: "old-title-2"}
+
+	item.Update(&article.ArticleUpdateCommand{ID: 1, Author: "John Doe", Title: "Old title!"})
+	assert.Equal(t, "old-title-2", item.Slug)
+
+	item.Update(&article.ArticleUpdateCommand{ID: 1, Author: "John Doe", Title: "New Title"})
+	assert.Empty(t, item.Slug)
+}
+
+// TestNewSlug tests the slug of a title, with the default slug of the titles without anything to transliterate.
+func TestNewSlug(t *testing.T) {
+	assert.Equal(t, "learning-golang-generics", article.NewSlug("Learning Golang: Generics"))
+	assert.Equal(t, "privet-mir", article.NewSlug("Привет, мир"))
+	assert.Equal(t, "article", article.NewSlug("日本語"))
+	assert.LessOrEqual(t, len(article.NewSlug(strings.Repeat("golang ", 40))), 80)
+}
+
+// TestArticle_ContentHash tests the ContentHash method.
+//
+// It checks that the hash only depends on the content of the article, not its ID nor timestamps.
+func TestArticle_ContentHash(t *testing.T) {
+	a := &article.Article{ID: 1, Author: "John Doe", Title: "Title", Body: "Body", Created: time.Now()}
+	b := &article.Article{ID: 2, Author: "John Doe", Title: "Title", Body: "Body"}
+	assert.Equal(t, a.ContentHash(), b.ContentHash())
+
+	b.Body = "Other body"
+	assert.NotEqual(t, a.ContentHash(), b.ContentHash())
+
+	// a copy at another version is stale
+	b.Body = "Body"
+	b.Version = 2
+	assert.NotEqual(t, a.ContentHash(), b.ContentHash())
+
+	// a copy still public after the article is withdrawn is stale
+	b.Version = 0
+	b.Status = article.StatusPublished
+	assert.NotEqual(t, a.ContentHash(), b.ContentHash())
+
+	// the publish time is compared to the second
+	publishAt := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	stored := publishAt.Add(time.Microsecond).In(time.FixedZone("CEST", 2*60*60))
+	a.PublishAt, b.PublishAt, b.Status = &publishAt, &stored, ""
+	assert.Equal(t, a.ContentHash(), b.ContentHash())
+
+	// the tags are compared in any order, a stale category path is detected
+	a.Tags, b.Tags = []string{"go", "web"}, []string{"web", "go"}
+	assert.Equal(t, a.ContentHash(), b.ContentHash())
+	b.Category = "tech"
+	assert.NotEqual(t, a.ContentHash(), b.ContentHash())
+
+	// fields are delimited so content cannot move between them
+	c := &article.Article{Author: "John Doe", Title: "TitleB", Body: "ody"}
+	d := &article.Article{Author: "John Doe", Title: "Title", Body: "Body"}
+	assert.NotEqual(t, c.ContentHash(), d.ContentHash())
+}
+
+// TestParseETag tests the ETag of an article is parsed back into its version.
+func TestParseETag(t *testing.T) {
+	item := &article.Article{Version: 7}
+	assert.Equal(t, `"7"`, item.ETag())
+
+	version, ok := article.ParseETag(item.ETag())
+	assert.True(t, ok)
+	assert.Equal(t, 7, version)
+
+	for _, etag := range []string{"", `"`, "7", `W/"7"`, `"abc"`, `"0"`, `"1", "2"`} {
+		_, ok := article.ParseETag(etag)
+		assert.False(t, ok, etag)
+	}
+}
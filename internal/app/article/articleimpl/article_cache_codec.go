@@ -0,0 +1,180 @@
+package articleimpl
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/undercode99/article_service/config"
+	"github.com/undercode99/article_service/internal/app/article"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// articleCacheVersion is the version of the schema of the cached entries.
+//
+// It is written in the first byte of every entry, bump it when the cached structs
+// change incompatibly: the entries of another version are then treated as misses
+// and replaced, without flushing Redis.
+// Version 2 added the version of the articles, served as their ETag.
+// Version 3 added the status and publish time of the articles.
+// Version 4 added the slug of the articles and of the title suggestions.
+// Version 5 added the tags and category of the articles and the tag facet.
+const articleCacheVersion byte = 5
+
+// The flags in the second byte of an entry, describing how its payload is encoded.
+// They are read back from the entry, so changing the configuration does not invalidate the cache.
+const (
+	articleCacheFlagMsgpack byte = 1 << iota
+	articleCacheFlagGzip
+	articleCacheFlagNotFound
+	articleCacheFlagZstd
+)
+
+// The zstd encoder and decoder are shared, EncodeAll and DecodeAll may be called concurrently.
+// They are created without options, which cannot fail.
+var (
+	zstdEncoder, _ = zstd.NewWriter(nil)
+	zstdDecoder, _ = zstd.NewReader(nil)
+)
+
+// errArticleCacheVersion is returned for an entry written with another version of the schema.
+var errArticleCacheVersion = errors.New("cache entry of another version")
+
+// articleCacheCodec serializes the cached entries in the configured format,
+// compressing the large ones.
+type articleCacheCodec struct {
+	msgpack         bool
+	gzip            bool
+	zstd            bool
+	compressMinSize int
+	notFoundPayload []byte
+}
+
+func newArticleCacheCodec(cfg *config.RedisConfig) *articleCacheCodec {
+	codec := &articleCacheCodec{
+		msgpack:         cfg.Format == config.CacheFormatMsgpack,
+		gzip:            cfg.Compression == config.CacheCompressionGzip,
+		zstd:            cfg.Compression == config.CacheCompressionZstd,
+		compressMinSize: cfg.CompressionMinSize,
+	}
+
+	if cfg.Format != config.CacheFormatJSON && !codec.msgpack {
+		log.Printf("WARNING: unknown cache format %q, entries are stored as %s", cfg.Format, config.CacheFormatJSON)
+	}
+	if cfg.Compression != config.CacheCompressionNone && !codec.gzip && !codec.zstd {
+		log.Printf("WARNING: unknown cache compression %q, entries are stored uncompressed", cfg.Compression)
+	}
+
+	return codec
+}
+
+// encode returns the entry of the value.
+func (c *articleCacheCodec) encode(value interface{}) ([]byte, error) {
+	var flags byte
+	var payload []byte
+	var err error
+	if c.msgpack {
+		flags |= articleCacheFlagMsgpack
+		payload, err = marshalMsgpack(value)
+	} else {
+		payload, err = json.Marshal(value)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(payload) >= c.compressMinSize {
+		switch {
+		case c.gzip:
+			flags |= articleCacheFlagGzip
+			if payload, err = gzipPayload(payload); err != nil {
+				return nil, err
+			}
+		case c.zstd:
+			flags |= articleCacheFlagZstd
+			payload = zstdEncoder.EncodeAll(payload, nil)
+		}
+	}
+
+	return append([]byte{articleCacheVersion, flags}, payload...), nil
+}
+
+// encodeNotFound returns the entry of a missing article.
+func (c *articleCacheCodec) encodeNotFound() []byte {
+	return []byte{articleCacheVersion, articleCacheFlagNotFound}
+}
+
+// decode reads the entry into the value.
+//
+// errArticleCacheVersion is returned for an entry of another version of the schema,
+// and article.ErrArticleNotFound for the entry of a missing article.
+func (c *articleCacheCodec) decode(entry []byte, value interface{}) error {
+	if len(entry) < 2 || entry[0] != articleCacheVersion {
+		return errArticleCacheVersion
+	}
+
+	flags, payload := entry[1], entry[2:]
+	if flags&articleCacheFlagNotFound != 0 {
+		return article.ErrArticleNotFound
+	}
+
+	var err error
+	switch {
+	case flags&articleCacheFlagGzip != 0:
+		if payload, err = gunzipPayload(payload); err != nil {
+			return err
+		}
+	case flags&articleCacheFlagZstd != 0:
+		if payload, err = zstdDecoder.DecodeAll(payload, nil); err != nil {
+			return err
+		}
+	}
+
+	if flags&articleCacheFlagMsgpack != 0 {
+		return unmarshalMsgpack(payload, value)
+	}
+	return json.Unmarshal(payload, value)
+}
+
+// marshalMsgpack encodes the value with the field names and omissions of its json tags,
+// so the entries match whatever the format.
+func marshalMsgpack(value interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := msgpack.NewEncoder(&buf)
+	encoder.SetCustomStructTag("json")
+	if err := encoder.Encode(value); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func unmarshalMsgpack(payload []byte, value interface{}) error {
+	decoder := msgpack.NewDecoder(bytes.NewReader(payload))
+	decoder.SetCustomStructTag("json")
+	return decoder.Decode(value)
+}
+
+func gzipPayload(payload []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if _, err := writer.Write(payload); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func gunzipPayload(payload []byte) ([]byte, error) {
+	reader, err := gzip.NewReader(bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return io.ReadAll(reader)
+}
@@ -0,0 +1,164 @@
+package articleimpl
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/undercode99/article_service/config"
+	"github.com/undercode99/article_service/internal/app/article"
+)
+
+const (
+	// articleListTagAll tags every list.
+	articleListTagAll = "all"
+	// articleListTagAnyAuthor tags the lists not restricted to some authors, which a change
+	// to the article of any author may affect.
+	articleListTagAnyAuthor = "any"
+)
+
+// articleCacheKeys names the Redis keys and channels of the article cache.
+//
+// Every name is prefixed with the namespace of the configuration, so the
+// environments or tenants sharing a Redis do not collide.
+type articleCacheKeys struct {
+	namespace string
+}
+
+func newArticleCacheKeys(cfg *config.RedisConfig) articleCacheKeys {
+	namespace := cfg.Namespace
+	if namespace != "" && !strings.HasSuffix(namespace, ":") {
+		namespace += ":"
+	}
+	return articleCacheKeys{namespace: namespace}
+}
+
+// article returns the key under which the article with the given ID is cached.
+func (k articleCacheKeys) article(id int) string {
+	return k.namespace + "article:" + strconv.Itoa(id)
+}
+
+// articleSlug returns the key under which the ID of the article with the given slug is cached.
+func (k articleCacheKeys) articleSlug(slug string) string {
+	return k.namespace + "article:slug:" + slug
+}
+
+// articlePattern returns the SCAN pattern matching the keys of the cached articles,
+// along other keys starting alike that articleID rejects.
+func (k articleCacheKeys) articlePattern() string {
+	return k.namespace + "article:*"
+}
+
+// articleID returns the ID of the article cached under the key.
+func (k articleCacheKeys) articleID(key string) (int, error) {
+	return strconv.Atoi(strings.TrimPrefix(key, k.namespace+"article:"))
+}
+
+// related returns the key under which the related articles of the query are cached.
+func (k articleCacheKeys) related(qry *article.ArticleRelatedQuery) string {
+	return fmt.Sprintf("%sarticle:%d:related:%d:%t", k.namespace, qry.ID, qry.GetLimit(), qry.SameAuthor)
+}
+
+// list returns the key under which the list of the query is cached.
+//
+// The query is normalized first, so the queries returning the same list, such as one
+// without a limit and one with the default limit, share their key.
+func (k articleCacheKeys) list(qry *article.ArticleQuery) string {
+	from, to, _ := qry.GetCreatedRange()
+	authors := qry.GetAuthors()
+	excludeAuthors := qry.GetExcludeAuthors()
+	tags := qry.GetTags()
+	sort.Strings(authors)
+	sort.Strings(excludeAuthors)
+	sort.Strings(tags)
+
+	normalized := struct {
+		Search          string     `json:"search"`
+		MatchMode       string     `json:"match_mode"`
+		Authors         []string   `json:"authors"`
+		ExcludeAuthors  []string   `json:"exclude_authors"`
+		Tags            []string   `json:"tags,omitempty"`
+		Category        string     `json:"category,omitempty"`
+		Status          string     `json:"status"`
+		CreatedFrom     *time.Time `json:"created_from"`
+		CreatedTo       *time.Time `json:"created_to"`
+		SortNewest      bool       `json:"sort_newest"`
+		Limit           int        `json:"limit"`
+		Page            int        `json:"page"`
+		Summary         bool       `json:"summary"`
+		Highlight       bool       `json:"highlight"`
+		FragmentSize    int        `json:"fragment_size,omitempty"`
+		PreTag          string     `json:"pre_tag,omitempty"`
+		PostTag         string     `json:"post_tag,omitempty"`
+		AuthorFacetSize int        `json:"author_facet_size,omitempty"`
+		CreatedFacet    string     `json:"created_facet,omitempty"`
+		TagFacetSize    int        `json:"tag_facet_size,omitempty"`
+	}{
+		Search:         strings.TrimSpace(qry.Search),
+		MatchMode:      qry.GetMatchMode(),
+		Authors:        authors,
+		ExcludeAuthors: excludeAuthors,
+		Tags:           tags,
+		Category:       qry.GetCategory(),
+		Status:         qry.GetStatus(),
+		CreatedFrom:    from,
+		CreatedTo:      to,
+		SortNewest:     qry.SortNewest,
+		Limit:          qry.GetLimit(),
+		Page:           qry.GetPage(),
+		Summary:        qry.IsSummary(),
+		Highlight:      qry.IsHighlight(),
+		CreatedFacet:   qry.CreatedFacet,
+	}
+	if normalized.Highlight {
+		normalized.FragmentSize = qry.GetFragmentSize()
+		normalized.PreTag, normalized.PostTag = qry.GetHighlightTags()
+	}
+	if qry.AuthorFacet {
+		normalized.AuthorFacetSize = qry.GetAuthorFacetSize()
+	}
+	if qry.TagFacet {
+		normalized.TagFacetSize = qry.GetTagFacetSize()
+	}
+
+	normalizedJSON, _ := json.Marshal(normalized)
+	sum := sha256.Sum256(normalizedJSON)
+	return k.namespace + "articles:list:" + hex.EncodeToString(sum[:])
+}
+
+// tag returns the key of the set holding the keys of the lists of the tag.
+func (k articleCacheKeys) tag(tag string) string {
+	return k.namespace + "articles:tag:" + tag
+}
+
+// invalidation returns the pub/sub channel the IDs of the changed articles are published on.
+func (k articleCacheKeys) invalidation() string {
+	return k.namespace + "article:invalidate"
+}
+
+// articleListCacheTags returns the tags of the list of the query.
+//
+// A list restricted to some authors is tagged with each of them, any other list
+// is tagged as one of any author.
+func articleListCacheTags(qry *article.ArticleQuery) []string {
+	tags := []string{articleListTagAll}
+
+	authors := qry.GetAuthors()
+	if len(authors) == 0 {
+		return append(tags, articleListTagAnyAuthor)
+	}
+	for _, author := range authors {
+		tags = append(tags, articleListAuthorTag(author))
+	}
+	return tags
+}
+
+// articleListAuthorTag returns the tag of the lists restricted to the author.
+func articleListAuthorTag(author string) string {
+	return "author:" + author
+}
@@ -2,27 +2,41 @@ package articleimpl
 
 import (
 	"context"
-	"encoding/json"
 	"log"
-	"strconv"
+	"math/rand"
 	"time"
 
 	"github.com/redis/go-redis/v9"
+	"github.com/undercode99/article_service/config"
 	"github.com/undercode99/article_service/internal/app/article"
 )
 
+// articleCacheJitter is the fraction of a TTL added at random to it.
+const articleCacheJitter = 0.1
+
+// jitterTTL returns the TTL extended by a random part of up to articleCacheJitter of it.
+func jitterTTL(ttl time.Duration) time.Duration {
+	return ttl + time.Duration(rand.Int63n(int64(float64(ttl)*articleCacheJitter)+1))
+}
+
 type ArticleCachingRepository struct {
 	redisClient *redis.Client
+	cfg         *config.RedisConfig
+	keys        articleCacheKeys
+	codec       *articleCacheCodec
 }
 
 // NewArticleCachingRepository returns a new instance of article.ArticleCachingRepository.
 //
-// It takes a redisClient as a parameter and initializes the ArticleCachingRepository
-// struct with the provided redisClient.
+// It takes a redisClient and the application configuration as parameters, the keys,
+// TTLs and encoding of the entries follow the Redis settings of the configuration.
 // It returns a pointer to the initialized ArticleCachingRepository.
-func NewArticleCachingRepository(redisClient *redis.Client) article.ArticleCachingRepository {
+func NewArticleCachingRepository(redisClient *redis.Client, cfg *config.Config) article.ArticleCachingRepository {
 	return &ArticleCachingRepository{
 		redisClient: redisClient,
+		cfg:         cfg.Cache,
+		keys:        newArticleCacheKeys(cfg.Cache),
+		codec:       newArticleCacheCodec(cfg.Cache),
 	}
 }
 
@@ -31,18 +45,28 @@ func NewArticleCachingRepository(redisClient *redis.Client) article.ArticleCachi
 // ctx - The context in which the function is being called.
 // article - The article object to be created.
 // Returns an error if there was an issue creating the article.
+// The TTL is jittered so the entries cached together do not expire together.
 func (r *ArticleCachingRepository) CreateArticle(ctx context.Context, article *article.Article) error {
 
 	// create cache key
-	key := "article:" + strconv.Itoa(article.ID)
+	key := r.keys.article(article.ID)
 
-	articleJSON, err := json.Marshal(article)
+	entry, err := r.codec.encode(article)
 	if err != nil {
 		return err
 	}
 
 	// set cache
-	return r.redisClient.Set(ctx, key, articleJSON, time.Hour*20).Err()
+	return r.redisClient.Set(ctx, key, entry, jitterTTL(r.cfg.ArticleTTL)).Err()
+}
+
+// CreateArticleNotFound caches that no article with the given ID exists for a short time.
+//
+// ctx - the context.Context object used for cancellation and timeouts.
+// id - the ID of the missing article.
+// Returns an error if there was an issue creating the cache entry.
+func (r *ArticleCachingRepository) CreateArticleNotFound(ctx context.Context, id int) error {
+	return r.redisClient.Set(ctx, r.keys.article(id), r.codec.encodeNotFound(), jitterTTL(r.cfg.NotFoundTTL)).Err()
 }
 
 // GetFromCache retrieves an article from the cache based on its ID.
@@ -50,32 +74,173 @@ func (r *ArticleCachingRepository) CreateArticle(ctx context.Context, article *a
 // ctx - the context.Context object used for cancellation and timeouts.
 // id - the ID of the article to retrieve.
 // Returns a pointer to the retrieved article and an error, if any.
+// article.ErrArticleCachingNotFound is returned if the article is not cached, or cached
+// by another version of the schema, and article.ErrArticleNotFound if the article is cached as missing.
 func (r *ArticleCachingRepository) GetArticleByID(ctx context.Context, id int) (*article.Article, error) {
-	key := "article:" + strconv.Itoa(id)
-	// check if the article is in the cache
-	exists, err := r.redisClient.Exists(ctx, key).Result()
+	key := r.keys.article(id)
+
+	// a single round-trip, a missing key is a cache miss
+	entry, err := r.redisClient.Get(ctx, key).Bytes()
+	if err == redis.Nil {
+		return nil, article.ErrArticleCachingNotFound
+	}
 	if err != nil {
-		log.Printf("failed to check if article is in cache: %v", err)
+		log.Printf("failed to get article from cache: %v", err)
 		return nil, err
 	}
-	if exists == 0 {
-		log.Printf("article not found in cache")
+
+	var item article.Article
+	err = r.codec.decode(entry, &item)
+	switch err {
+	case nil:
+		return &item, nil
+	case article.ErrArticleNotFound:
+		return nil, err
+	case errArticleCacheVersion:
 		return nil, article.ErrArticleCachingNotFound
 	}
 
-	// get the article from the cache
-	var article article.Article
-	articleJSON, err := r.redisClient.Get(ctx, key).Result()
+	log.Printf("failed to decode article from cache: %v", err)
+	return nil, err
+}
+
+// DeleteArticle evicts an article from the cache based on its ID.
+//
+// ctx - the context.Context object used for cancellation and timeouts.
+// id - the ID of the article to evict.
+// Returns an error if there was an issue deleting the cache entry.
+func (r *ArticleCachingRepository) DeleteArticle(ctx context.Context, id int) error {
+	key := r.keys.article(id)
+	return r.redisClient.Del(ctx, key).Err()
+}
+
+// CreateArticleSlug caches the ID of the article with the given slug, current or former.
+//
+// A slug is never given to another article, so the entry is not evicted when the
+// article changes, it only expires like the cached articles.
+func (r *ArticleCachingRepository) CreateArticleSlug(ctx context.Context, slug string, id int) error {
+	return r.redisClient.Set(ctx, r.keys.articleSlug(slug), id, jitterTTL(r.cfg.ArticleTTL)).Err()
+}
+
+// GetArticleIDBySlug returns the cached ID of the article with the given slug.
+//
+// article.ErrArticleCachingNotFound is returned if the slug is not cached.
+func (r *ArticleCachingRepository) GetArticleIDBySlug(ctx context.Context, slug string) (int, error) {
+	id, err := r.redisClient.Get(ctx, r.keys.articleSlug(slug)).Int()
+	if err == redis.Nil {
+		return 0, article.ErrArticleCachingNotFound
+	}
 	if err != nil {
-		log.Printf("failed to get article from cache: %v", err)
+		log.Printf("failed to get article slug from cache: %v", err)
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// CreateRelatedArticles caches the related articles of the query for a short time.
+//
+// The related articles are not evicted when articles change, they are only
+// kept long enough to absorb the traffic of a popular article.
+func (r *ArticleCachingRepository) CreateRelatedArticles(ctx context.Context, qry *article.ArticleRelatedQuery, related *article.RelatedArticlesDTO) error {
+	entry, err := r.codec.encode(related)
+	if err != nil {
+		return err
+	}
+
+	return r.redisClient.Set(ctx, r.keys.related(qry), entry, r.cfg.RelatedTTL).Err()
+}
+
+// GetRelatedArticles retrieves the related articles of the query from the cache.
+//
+// article.ErrArticleCachingNotFound is returned if they are not cached.
+func (r *ArticleCachingRepository) GetRelatedArticles(ctx context.Context, qry *article.ArticleRelatedQuery) (*article.RelatedArticlesDTO, error) {
+	var related article.RelatedArticlesDTO
+	if err := r.get(ctx, r.keys.related(qry), &related); err != nil {
 		return nil, err
 	}
 
-	err = json.Unmarshal([]byte(articleJSON), &article)
+	return &related, nil
+}
+
+// CreateListArticles caches the list of the query for a short time, tagged for its eviction.
+//
+// The key of the list is added to the set of each of its tags, the sets expire along the lists.
+// The lists are evicted when their articles change, the TTL only bounds how long
+// a list missed by an eviction can be served.
+func (r *ArticleCachingRepository) CreateListArticles(ctx context.Context, qry *article.ArticleQuery, list *article.ListArticleDTO) error {
+	entry, err := r.codec.encode(list)
 	if err != nil {
-		log.Printf("failed to unmarshal article from cache: %v", err)
+		return err
+	}
+
+	key := r.keys.list(qry)
+	_, err = r.redisClient.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, key, entry, r.cfg.ListTTL)
+		for _, tag := range articleListCacheTags(qry) {
+			pipe.SAdd(ctx, r.keys.tag(tag), key)
+			pipe.Expire(ctx, r.keys.tag(tag), r.cfg.ListTTL)
+		}
+		return nil
+	})
+	return err
+}
+
+// GetListArticles retrieves the list of the query from the cache.
+//
+// article.ErrArticleCachingNotFound is returned if it is not cached.
+func (r *ArticleCachingRepository) GetListArticles(ctx context.Context, qry *article.ArticleQuery) (*article.ListArticleDTO, error) {
+	var list article.ListArticleDTO
+	if err := r.get(ctx, r.keys.list(qry), &list); err != nil {
 		return nil, err
 	}
 
-	return &article, nil
+	return &list, nil
+}
+
+// DeleteListArticles evicts the cached lists a change to an article of one of the authors may affect,
+// the lists restricted to these authors and the lists of any author.
+//
+// Every cached list is evicted if no author is given.
+// A list cached while its tag is evicted may be missed, it is then served until it expires.
+func (r *ArticleCachingRepository) DeleteListArticles(ctx context.Context, authors ...string) error {
+	tags := []string{articleListTagAll}
+	if len(authors) > 0 {
+		tags = []string{articleListTagAnyAuthor}
+		for _, author := range authors {
+			tags = append(tags, articleListAuthorTag(author))
+		}
+	}
+
+	for _, tag := range tags {
+		tagKey := r.keys.tag(tag)
+		keys, err := r.redisClient.SMembers(ctx, tagKey).Result()
+		if err != nil {
+			return err
+		}
+		if err := r.redisClient.Del(ctx, append(keys, tagKey)...).Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// get reads the entry of the key into the value.
+//
+// article.ErrArticleCachingNotFound is returned if the key is not cached, or cached
+// by another version of the schema.
+func (r *ArticleCachingRepository) get(ctx context.Context, key string, value interface{}) error {
+	entry, err := r.redisClient.Get(ctx, key).Bytes()
+	if err == redis.Nil {
+		return article.ErrArticleCachingNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	err = r.codec.decode(entry, value)
+	if err == errArticleCacheVersion {
+		return article.ErrArticleCachingNotFound
+	}
+	return err
 }
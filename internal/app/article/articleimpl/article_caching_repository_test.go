@@ -1,19 +1,340 @@
 package articleimpl_test
 
 import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/undercode99/article_service/config"
+	"github.com/undercode99/article_service/internal/app/article"
+	"github.com/undercode99/article_service/internal/app/article/articleimpl"
 )
 
-// TestArticleCachingRepository_GetArticleByID TODO: Implement test cases for GetArticleByID function.
+// cacheTestConfig returns the configuration of the caches in the tests, with the default Redis settings.
+func cacheTestConfig() *config.Config {
+	return &config.Config{
+		Cache:      config.NewRedisConfig(),
+		LocalCache: &config.LocalCacheConfig{Size: 10, TTL: time.Minute},
+	}
+}
+
+// TestArticleCachingRepository_GetArticleByID tests the GetArticleByID function.
 //
-// t is the test object used for reporting errors and failures.
+// 1. Test a missing key is a cache miss.
+// 2. Test a cached article is returned with a single GET.
+// 3. Test a cached missing article is article.ErrArticleNotFound.
 func TestArticleCachingRepository_GetArticleByID(t *testing.T) {
-	// TODO: Implement test cases for GetArticleByID function
+	store := map[string]string{
+		"article:1": "\x05\x00" + `{"id":1,"title":"Golang testing","author":"cena","version":5}`,
+		"article:2": "\x05\x04",
+		// written by previous versions of the schema
+		"article:4": `{"id":4,"title":"Golang testing","author":"cena"}`,
+		"article:5": "\x01\x00" + `{"id":5,"title":"Golang testing","author":"cena"}`,
+	}
+	var gets []string
+	redisClient := redisHandlerConnection(func(args []string) string {
+		if strings.ToUpper(args[0]) != "GET" {
+			return "-ERR unknown command\r\n"
+		}
+		gets = append(gets, args[1])
+		value, ok := store[args[1]]
+		if !ok {
+			return "$-1\r\n"
+		}
+		return fmt.Sprintf("$%d\r\n%s\r\n", len(value), value)
+	})
+	defer redisClient.Close()
+
+	ctx := context.Background()
+	repo := articleimpl.NewArticleCachingRepository(redisClient, cacheTestConfig())
+
+	_, err := repo.GetArticleByID(ctx, 3)
+	assert.Equal(t, article.ErrArticleCachingNotFound, err)
+
+	result, err := repo.GetArticleByID(ctx, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "Golang testing", result.Title)
+	assert.Equal(t, `"5"`, result.ETag())
+
+	_, err = repo.GetArticleByID(ctx, 2)
+	assert.Equal(t, article.ErrArticleNotFound, err)
+
+	_, err = repo.GetArticleByID(ctx, 4)
+	assert.Equal(t, article.ErrArticleCachingNotFound, err)
+
+	_, err = repo.GetArticleByID(ctx, 5)
+	assert.Equal(t, article.ErrArticleCachingNotFound, err)
+
+	// each lookup is a single GET
+	assert.Equal(t, []string{"article:3", "article:1", "article:2", "article:4", "article:5"}, gets)
 }
 
-// TestArticleCachingRepository_CreateArticle is a test function for the CreateArticle function.
+// TestArticleCachingRepository_CreateArticle tests the CreateArticle and CreateArticleNotFound functions.
 //
-// It is responsible for implementing test cases for the CreateArticle function.
+// The TTLs are extended by a random jitter of up to a tenth of them.
 func TestArticleCachingRepository_CreateArticle(t *testing.T) {
-	// TODO: Implement test cases for CreateArticle function
+	store := map[string]string{}
+	ttls := map[string]int{}
+	redisClient := redisHandlerConnection(func(args []string) string {
+		if strings.ToUpper(args[0]) != "SET" {
+			return "-ERR unknown command\r\n"
+		}
+		store[args[1]] = args[2]
+		if len(args) > 4 {
+			// the jittered TTLs are usually sent in milliseconds
+			ttls[args[1]], _ = strconv.Atoi(args[4])
+			if strings.EqualFold(args[3], "ex") {
+				ttls[args[1]] *= 1000
+			}
+		}
+		return "+OK\r\n"
+	})
+	defer redisClient.Close()
+
+	ctx := context.Background()
+	repo := articleimpl.NewArticleCachingRepository(redisClient, cacheTestConfig())
+
+	assert.NoError(t, repo.CreateArticle(ctx, &article.Article{ID: 1, Title: "Golang testing"}))
+	assert.Contains(t, store["article:1"], "Golang testing")
+	assert.GreaterOrEqual(t, ttls["article:1"], 20*60*60*1000)
+	assert.LessOrEqual(t, ttls["article:1"], 22*60*60*1000)
+
+	assert.NoError(t, repo.CreateArticleNotFound(ctx, 2))
+	assert.Equal(t, "\x05\x04", store["article:2"])
+	assert.GreaterOrEqual(t, ttls["article:2"], 60*1000)
+	assert.LessOrEqual(t, ttls["article:2"], 66*1000)
+}
+
+// TestArticleCachingRepository_ArticleSlug tests the CreateArticleSlug and GetArticleIDBySlug functions.
+//
+// The ID of the article of a slug is cached under a key of the slug, an uncached slug is a cache miss.
+func TestArticleCachingRepository_ArticleSlug(t *testing.T) {
+	store := map[string]string{}
+	redisClient := redisHandlerConnection(func(args []string) string {
+		switch strings.ToUpper(args[0]) {
+		case "SET":
+			store[args[1]] = args[2]
+			return "+OK\r\n"
+		case "GET":
+			value, ok := store[args[1]]
+			if !ok {
+				return "$-1\r\n"
+			}
+			return fmt.Sprintf("$%d\r\n%s\r\n", len(value), value)
+		}
+		return "-ERR unknown command\r\n"
+	})
+	defer redisClient.Close()
+
+	ctx := context.Background()
+	repo := articleimpl.NewArticleCachingRepository(redisClient, cacheTestConfig())
+
+	_, err := repo.GetArticleIDBySlug(ctx, "golang-testing")
+	assert.Equal(t, article.ErrArticleCachingNotFound, err)
+
+	assert.NoError(t, repo.CreateArticleSlug(ctx, "golang-testing", 7))
+	assert.Equal(t, "7", store["article:slug:golang-testing"])
+
+	id, err := repo.GetArticleIDBySlug(ctx, "golang-testing")
+	assert.NoError(t, err)
+	assert.Equal(t, 7, id)
+}
+
+// TestArticleCachingRepository_RelatedArticles tests the CreateRelatedArticles and GetRelatedArticles functions.
+//
+// The related articles are cached under a key of the article and query options with a short TTL.
+func TestArticleCachingRepository_RelatedArticles(t *testing.T) {
+	store := map[string]string{}
+	var ttl string
+	redisClient := redisHandlerConnection(func(args []string) string {
+		switch strings.ToUpper(args[0]) {
+		case "SET":
+			store[args[1]] = args[2]
+			if len(args) > 4 {
+				ttl = args[4]
+			}
+			return "+OK\r\n"
+		case "GET":
+			value, ok := store[args[1]]
+			if !ok {
+				return "$-1\r\n"
+			}
+			return fmt.Sprintf("$%d\r\n%s\r\n", len(value), value)
+		}
+		return "-ERR unknown command\r\n"
+	})
+	defer redisClient.Close()
+
+	ctx := context.Background()
+	repo := articleimpl.NewArticleCachingRepository(redisClient, cacheTestConfig())
+	query := &article.ArticleRelatedQuery{ID: 1, SameAuthor: true}
+	related := &article.RelatedArticlesDTO{Articles: []article.Article{{ID: 2, Title: "Golang testing", Author: "cena"}}}
+
+	_, err := repo.GetRelatedArticles(ctx, query)
+	assert.Equal(t, article.ErrArticleCachingNotFound, err)
+
+	assert.NoError(t, repo.CreateRelatedArticles(ctx, query, related))
+	assert.Contains(t, store, "article:1:related:5:true")
+	assert.Equal(t, "600", ttl)
+
+	result, err := repo.GetRelatedArticles(ctx, query)
+	assert.NoError(t, err)
+	assert.Equal(t, related.Articles[0].Title, result.Articles[0].Title)
+
+	_, err = repo.GetRelatedArticles(ctx, &article.ArticleRelatedQuery{ID: 1})
+	assert.Equal(t, article.ErrArticleCachingNotFound, err)
+}
+
+// TestArticleCachingRepository_ListArticles tests the caching of the lists of articles.
+//
+// 1. Test the queries of the same list share their cache entry.
+// 2. Test a change to an article of an author evicts the lists of the author and of any author.
+// 3. Test every list is evicted without an author.
+func TestArticleCachingRepository_ListArticles(t *testing.T) {
+	store := map[string]string{}
+	sets := map[string]map[string]bool{}
+	redisClient := redisHandlerConnection(func(args []string) string {
+		switch strings.ToUpper(args[0]) {
+		case "SET":
+			store[args[1]] = args[2]
+			return "+OK\r\n"
+		case "GET":
+			value, ok := store[args[1]]
+			if !ok {
+				return "$-1\r\n"
+			}
+			return fmt.Sprintf("$%d\r\n%s\r\n", len(value), value)
+		case "SADD":
+			if sets[args[1]] == nil {
+				sets[args[1]] = map[string]bool{}
+			}
+			sets[args[1]][args[2]] = true
+			return ":1\r\n"
+		case "EXPIRE":
+			return ":1\r\n"
+		case "SMEMBERS":
+			reply := fmt.Sprintf("*%d\r\n", len(sets[args[1]]))
+			for member := range sets[args[1]] {
+				reply += fmt.Sprintf("$%d\r\n%s\r\n", len(member), member)
+			}
+			return reply
+		case "DEL":
+			for _, key := range args[1:] {
+				delete(store, key)
+				delete(sets, key)
+			}
+			return fmt.Sprintf(":%d\r\n", len(args)-1)
+		}
+		return "-ERR unknown command\r\n"
+	})
+	defer redisClient.Close()
+
+	ctx := context.Background()
+	repo := articleimpl.NewArticleCachingRepository(redisClient, cacheTestConfig())
+	list := func(author string) *article.ListArticleDTO {
+		return &article.ListArticleDTO{Articles: []article.Article{{ID: 1, Title: "Golang testing", Author: author}}, Total: 1}
+	}
+	homepage := &article.ArticleQuery{Page: 1, Limit: 10, SortNewest: true}
+	cena := &article.ArticleQuery{Author: "cena", SortNewest: true}
+	jhon := &article.ArticleQuery{Authors: []string{"jhon"}, SortNewest: true}
+
+	assert.NoError(t, repo.CreateListArticles(ctx, homepage, list("cena")))
+	assert.NoError(t, repo.CreateListArticles(ctx, cena, list("cena")))
+	assert.NoError(t, repo.CreateListArticles(ctx, jhon, list("jhon")))
+
+	result, err := repo.GetListArticles(ctx, &article.ArticleQuery{SortNewest: true, MatchMode: article.MatchModeAny})
+	assert.NoError(t, err)
+	assert.Equal(t, list("cena"), result)
+	_, err = repo.GetListArticles(ctx, &article.ArticleQuery{Authors: []string{"cena"}, SortNewest: true})
+	assert.NoError(t, err)
+
+	assert.NoError(t, repo.DeleteListArticles(ctx, "cena"))
+	_, err = repo.GetListArticles(ctx, homepage)
+	assert.Equal(t, article.ErrArticleCachingNotFound, err)
+	_, err = repo.GetListArticles(ctx, cena)
+	assert.Equal(t, article.ErrArticleCachingNotFound, err)
+	_, err = repo.GetListArticles(ctx, jhon)
+	assert.NoError(t, err)
+
+	assert.NoError(t, repo.DeleteListArticles(ctx))
+	_, err = repo.GetListArticles(ctx, jhon)
+	assert.Equal(t, article.ErrArticleCachingNotFound, err)
+}
+
+// TestArticleCachingRepository_Policy tests the Redis settings of the configuration.
+//
+// The keys are prefixed with the namespace and the entries serialized and compressed
+// as configured, an entry is read back whatever the settings of the reader.
+func TestArticleCachingRepository_Policy(t *testing.T) {
+	store := map[string]string{}
+	var ttl string
+	redisClient := redisHandlerConnection(func(args []string) string {
+		switch strings.ToUpper(args[0]) {
+		case "SET":
+			store[args[1]] = args[2]
+			ttl = args[3] + " " + args[4]
+			return "+OK\r\n"
+		case "GET":
+			value, ok := store[args[1]]
+			if !ok {
+				return "$-1\r\n"
+			}
+			return fmt.Sprintf("$%d\r\n%s\r\n", len(value), value)
+		}
+		return "-ERR unknown command\r\n"
+	})
+	defer redisClient.Close()
+
+	ctx := context.Background()
+	cfg := cacheTestConfig()
+	cfg.Cache.Namespace = "staging"
+	cfg.Cache.RelatedTTL = time.Minute
+	cfg.Cache.Format = config.CacheFormatMsgpack
+	cfg.Cache.Compression = config.CacheCompressionGzip
+	cfg.Cache.CompressionMinSize = 0
+	repo := articleimpl.NewArticleCachingRepository(redisClient, cfg)
+
+	query := &article.ArticleRelatedQuery{ID: 1}
+	related := &article.RelatedArticlesDTO{Articles: []article.Article{{ID: 2, Title: "Golang testing", Author: "cena"}}}
+	assert.NoError(t, repo.CreateRelatedArticles(ctx, query, related))
+
+	entry, ok := store["staging:article:1:related:5:false"]
+	assert.True(t, ok)
+	assert.Equal(t, "ex 60", strings.ToLower(ttl))
+	// version 5, msgpack and gzip
+	assert.Equal(t, "\x05\x03", entry[:2])
+	assert.Equal(t, "\x1f\x8b", entry[2:4])
+
+	result, err := articleimpl.NewArticleCachingRepository(redisClient, func() *config.Config {
+		cfg := cacheTestConfig()
+		cfg.Cache.Namespace = "staging:"
+		return cfg
+	}()).GetRelatedArticles(ctx, query)
+	assert.NoError(t, err)
+	assert.Equal(t, related, result)
+
+	_, err = articleimpl.NewArticleCachingRepository(redisClient, cacheTestConfig()).GetRelatedArticles(ctx, query)
+	assert.Equal(t, article.ErrArticleCachingNotFound, err)
+
+	cfg.Cache.Format = config.CacheFormatJSON
+	cfg.Cache.Compression = config.CacheCompressionZstd
+	repo = articleimpl.NewArticleCachingRepository(redisClient, cfg)
+	assert.NoError(t, repo.CreateRelatedArticles(ctx, query, related))
+
+	entry = store["staging:article:1:related:5:false"]
+	// version 5, json and zstd
+	assert.Equal(t, "\x05\x08", entry[:2])
+	assert.Equal(t, "\x28\xb5\x2f\xfd", entry[2:6])
+
+	result, err = articleimpl.NewArticleCachingRepository(redisClient, func() *config.Config {
+		cfg := cacheTestConfig()
+		cfg.Cache.Namespace = "staging"
+		return cfg
+	}()).GetRelatedArticles(ctx, query)
+	assert.NoError(t, err)
+	assert.Equal(t, related, result)
 }
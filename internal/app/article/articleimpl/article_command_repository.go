@@ -2,11 +2,18 @@ package articleimpl
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/elastic/go-elasticsearch/v8"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types"
 	"github.com/undercode99/article_service/internal/app/article"
+	"github.com/undercode99/article_service/internal/searching"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type ArticleCommandRepository struct {
@@ -23,34 +30,337 @@ func NewArticleCommandRepository(db *gorm.DB, elasticClient *elasticsearch.Typed
 
 // CreateArticle creates a new article in the ArticleCommandRepository.
 //
-// It takes an article object as a parameter and returns an error.
-func (r *ArticleCommandRepository) CreateArticle(article *article.Article) error {
-	// Create a new article record in the database.
-	created := r.db.Create(article)
+// It takes an article object and its first revision as parameters and returns an error.
+// The slug, the tags, the revision and an outbox record are written in the same transaction,
+// the outbox record so the article is indexed later.
+// article.ErrCategoryNotFound is returned if the category of the article does not exist.
+func (r *ArticleCommandRepository) CreateArticle(item *article.Article, revision *article.ArticleRevision) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := setArticleCategory(tx, item); err != nil {
+			return err
+		}
 
-	// Check if there was an error while creating the article.
-	if created.Error != nil {
-		return created.Error
+		// Create a new article record in the database.
+		if err := tx.Create(item).Error; err != nil {
+			return err
+		}
+
+		if err := reserveArticleSlug(tx, item); err != nil {
+			return err
+		}
+
+		if err := createArticleTags(tx, item); err != nil {
+			return err
+		}
+
+		if err := createArticleRevision(tx, item, revision); err != nil {
+			return err
+		}
+
+		return tx.Create(article.NewArticleOutbox(item.ID, article.OutboxActionCreated)).Error
+	})
+}
+
+// UpdateArticle updates an existing article in the ArticleCommandRepository.
+//
+// It takes an article object and the revision of the change as parameters and returns an error.
+// The article is only updated if its stored version is still the version of the item,
+// the version is then incremented in the database and in the item and the revision
+// is written with the new version as its number. An item without a slug, whose title
+// changed, is given a new one, the former slugs are kept. The tags of the item replace
+// the tags of the article.
+// gorm.ErrRecordNotFound is returned if no article with the given ID exists,
+// article.ErrArticleVersionMismatch if the article was updated in between, and
+// article.ErrCategoryNotFound if the category of the item does not exist.
+func (r *ArticleCommandRepository) UpdateArticle(item *article.Article, revision *article.ArticleRevision) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := setArticleCategory(tx, item); err != nil {
+			return err
+		}
+
+		// Update the editable columns of the article record in the database,
+		// the item is only changed once the update succeeds.
+		updated := tx.Model(&article.Article{ID: item.ID}).Where("version = ?", item.Version).Updates(map[string]interface{}{
+			"author":      item.Author,
+			"title":       item.Title,
+			"body":        item.Body,
+			"status":      item.Status,
+			"publish_at":  item.PublishAt,
+			"category_id": item.CategoryID,
+			"updated":     item.Updated,
+			"version":     item.Version + 1,
+		})
+		if updated.Error != nil {
+			return updated.Error
+		}
+
+		// No rows affected means there is no article with the given ID and version.
+		if updated.RowsAffected == 0 {
+			return articleUnchangedError(tx, item.ID)
+		}
+		item.Version++
+
+		if item.Slug == "" {
+			if err := reserveArticleSlug(tx, item); err != nil {
+				return err
+			}
+		}
+
+		if err := replaceArticleTags(tx, item); err != nil {
+			return err
+		}
+
+		if err := createArticleRevision(tx, item, revision); err != nil {
+			return err
+		}
+
+		return tx.Create(article.NewArticleOutbox(item.ID, article.OutboxActionUpdated)).Error
+	})
+}
+
+// DeleteArticle soft-deletes an article in the ArticleCommandRepository.
+//
+// The article stays in the database and can be restored until it is purged.
+// Unless the version is 0 the article is only deleted if it is still at that version.
+// gorm.ErrRecordNotFound is returned if no live article with the given ID exists, and
+// article.ErrArticleVersionMismatch if the article is at another version.
+func (r *ArticleCommandRepository) DeleteArticle(id int, version int) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		query := tx
+		if version != 0 {
+			query = query.Where("version = ?", version)
+		}
+
+		deleted := query.Delete(&article.Article{}, id)
+		if deleted.Error != nil {
+			return deleted.Error
+		}
+
+		if deleted.RowsAffected == 0 {
+			if version != 0 {
+				return articleUnchangedError(tx, id)
+			}
+			return gorm.ErrRecordNotFound
+		}
+
+		return tx.Create(article.NewArticleOutbox(id, article.OutboxActionDeleted)).Error
+	})
+}
+
+// createArticleRevision writes the revision of the current version of the article.
+func createArticleRevision(tx *gorm.DB, item *article.Article, revision *article.ArticleRevision) error {
+	revision.ArticleID = item.ID
+	revision.Revision = item.Version
+	return tx.Create(revision).Error
+}
+
+// createArticleRevisions writes the revisions of the current versions of the articles,
+// they keep the title and body of the articles and note a change made to many articles at once.
+func createArticleRevisions(tx *gorm.DB, ids []int, editor, note string, created time.Time) error {
+	return tx.Exec(
+		"INSERT INTO article_revisions (article_id, revision, title, body, editor, note, created) "+
+			"SELECT id, version, title, body, ?, ?, ? FROM articles WHERE id IN ?",
+		editor, note, created, ids,
+	).Error
+}
+
+// articleUnchangedError returns why a change conditioned on the version of an article affected no rows,
+// gorm.ErrRecordNotFound if no live article with the given ID exists, else article.ErrArticleVersionMismatch.
+func articleUnchangedError(tx *gorm.DB, id int) error {
+	var count int64
+	if err := tx.Model(&article.Article{}).Where("id = ?", id).Count(&count).Error; err != nil {
+		return err
 	}
 
-	// Return nil (no error) if the article was created successfully.
-	return nil
+	if count == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return article.ErrArticleVersionMismatch
+}
+
+// RestoreArticle restores a soft-deleted article in the ArticleCommandRepository.
+//
+// gorm.ErrRecordNotFound is returned if no deleted article with the given ID exists.
+func (r *ArticleCommandRepository) RestoreArticle(id int) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		restored := tx.Unscoped().
+			Model(&article.Article{}).
+			Where("id = ? AND deleted_at IS NOT NULL", id).
+			Updates(map[string]interface{}{
+				"deleted_at": nil,
+				"updated":    time.Now(),
+			})
+		if restored.Error != nil {
+			return restored.Error
+		}
+
+		if restored.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		return tx.Create(article.NewArticleOutbox(id, article.OutboxActionRestored)).Error
+	})
+}
+
+// PurgeArticle permanently removes a soft-deleted article, its revisions and its tag links from the database.
+//
+// Only deleted articles can be purged, gorm.ErrRecordNotFound is returned
+// if no deleted article with the given ID exists.
+func (r *ArticleCommandRepository) PurgeArticle(id int) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		purged := tx.Unscoped().Where("deleted_at IS NOT NULL").Delete(&article.Article{}, id)
+		if purged.Error != nil {
+			return purged.Error
+		}
+
+		if purged.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		if err := tx.Where("article_id = ?", id).Delete(&article.ArticleRevision{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("article_id = ?", id).Delete(&article.ArticleTag{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Create(article.NewArticleOutbox(id, article.OutboxActionPurged)).Error
+	})
+}
+
+// PurgeDeletedArticles permanently removes every article deleted before the given time, with its revisions and tag links.
+//
+// It returns the IDs of the purged articles, their documents are removed through the outbox.
+func (r *ArticleCommandRepository) PurgeDeletedArticles(deletedBefore time.Time) ([]int, error) {
+	var ids []int
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		// Collect the expired articles first, the IDs are returned to the caller
+		err := tx.Unscoped().
+			Model(&article.Article{}).
+			Where("deleted_at IS NOT NULL AND deleted_at < ?", deletedBefore).
+			Pluck("id", &ids).Error
+		if err != nil || len(ids) == 0 {
+			return err
+		}
+
+		if err := tx.Unscoped().Delete(&article.Article{}, ids).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("article_id IN ?", ids).Delete(&article.ArticleRevision{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("article_id IN ?", ids).Delete(&article.ArticleTag{}).Error; err != nil {
+			return err
+		}
+
+		outboxes := make([]*article.ArticleOutbox, len(ids))
+		for i, id := range ids {
+			outboxes[i] = article.NewArticleOutbox(id, article.OutboxActionPurged)
+		}
+		return tx.Create(outboxes).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+// PublishScheduledArticles publishes every article in review whose publish time is not after the given time.
+//
+// Each article is published like an update, its version is incremented and a revision is
+// written by article.SchedulerEditor. The articles locked by another transaction are left
+// to the next run, so concurrent schedulers never publish an article twice.
+// It returns the IDs of the published articles, their documents are replaced through the outbox.
+func (r *ArticleCommandRepository) PublishScheduledArticles(now time.Time) ([]int, error) {
+	var ids []int
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&article.Article{}).
+			Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND publish_at <= ?", article.StatusInReview, now).
+			Pluck("id", &ids).Error
+		if err != nil || len(ids) == 0 {
+			return err
+		}
+
+		err = tx.Model(&article.Article{}).Where("id IN ?", ids).Updates(map[string]interface{}{
+			"status":  article.StatusPublished,
+			"updated": now,
+			"version": gorm.Expr("version + 1"),
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		err = createArticleRevisions(tx, ids, article.SchedulerEditor, article.ArticleTransitions[article.TransitionPublish].Note, now)
+		if err != nil {
+			return err
+		}
+		return createArticleOutboxes(tx, ids, article.OutboxActionUpdated)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return ids, nil
 }
 
 // CreateIndexArticle indexes the document and creates an index.
 //
+// Indexing a document with an ID that already exists replaces the stored
+// document, so this is also used to propagate updates to Elasticsearch.
+//
 // ctx: the context.Context object for handling deadlines, cancellations, and values across API boundaries.
 // item: a pointer to the article.Article object to be indexed.
 // Returns an error if there was a problem indexing the document.
+// Nothing is indexed if the repository has no Elasticsearch client.
 func (r *ArticleCommandRepository) CreateIndexArticle(ctx context.Context, item *article.Article) error {
+	// without Elasticsearch the articles are searched in the database directly
+	if r.elasticClient == nil {
+		return nil
+	}
+
 	// Convert the item ID to a string
 	idString := strconv.Itoa(item.ID)
 
 	// Index the document using the ElasticSearch client
 	_, err := r.elasticClient.Index(article.IndexName).Id(idString).Request(item).Do(ctx)
 	if err != nil {
-		return err
+		return elasticWriteError(ctx, err)
+	}
+
+	return nil
+}
+
+// DeleteIndexArticle removes the document of an article from the index.
+//
+// ctx: the context.Context object for handling deadlines, cancellations, and values across API boundaries.
+// id: the ID of the article to remove.
+// Returns an error if there was a problem removing the document, a missing document is not an error.
+func (r *ArticleCommandRepository) DeleteIndexArticle(ctx context.Context, id int) error {
+	if r.elasticClient == nil {
+		return nil
+	}
+
+	_, err := r.elasticClient.Delete(article.IndexName, strconv.Itoa(id)).Do(ctx)
+	if err != nil {
+		return elasticWriteError(ctx, err)
 	}
 
 	return nil
 }
+
+// elasticWriteError returns the error of a failed index write.
+//
+// Transport and server errors wrap searching.ErrElasticUnavailable like those of the
+// searches, errors Elasticsearch answered for the document itself are returned as is.
+func elasticWriteError(ctx context.Context, err error) error {
+	var esErr *types.ElasticsearchError
+	if errors.As(err, &esErr) {
+		if esErr.Status >= http.StatusInternalServerError {
+			return fmt.Errorf("%w: %v", searching.ErrElasticUnavailable, err)
+		}
+		return err
+	}
+	return elasticTransportError(ctx, err)
+}
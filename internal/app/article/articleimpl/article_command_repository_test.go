@@ -1,6 +1,7 @@
 package articleimpl_test
 
 import (
+	"context"
 	"testing"
 	"time"
 
@@ -8,8 +9,66 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/undercode99/article_service/internal/app/article"
 	"github.com/undercode99/article_service/internal/app/article/articleimpl"
+	"gorm.io/gorm"
 )
 
+// expectOutboxInsert expects an outbox record to be written for the given article and action.
+func expectOutboxInsert(mock sqlmock.Sqlmock, articleID int, action string) {
+	mock.ExpectQuery("INSERT INTO \"article_outboxes\" (.+)").WithArgs(
+		articleID,
+		action,
+		article.OutboxStatusPending,
+		0,
+		"",
+		sqlmock.AnyArg(),
+		sqlmock.AnyArg(),
+	).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+}
+
+// expectRevisionInsert expects the revision of an article to be written.
+func expectRevisionInsert(mock sqlmock.Sqlmock, articleID, revision int, editor, note string) {
+	mock.ExpectQuery("INSERT INTO \"article_revisions\" (.+)").WithArgs(
+		articleID,
+		revision,
+		sqlmock.AnyArg(),
+		sqlmock.AnyArg(),
+		editor,
+		note,
+		sqlmock.AnyArg(),
+	).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+}
+
+// expectSlugReserve expects the slug of an article to be reserved among the taken slugs of its title.
+//
+// The taken slugs map the slugs to the IDs of their articles, the slug is only
+// inserted if the article does not have it already.
+func expectSlugReserve(mock sqlmock.Sqlmock, articleID int, base string, taken map[string]int, slug string) {
+	rows := sqlmock.NewRows([]string{"article_id", "slug"})
+	for takenSlug, takenID := range taken {
+		rows.AddRow(takenID, takenSlug)
+	}
+	mock.ExpectQuery("SELECT \"article_id\",\"slug\" FROM \"article_slugs\" WHERE slug = (.+) OR slug LIKE (.+)").
+		WithArgs(base, base+"-%").
+		WillReturnRows(rows)
+
+	if taken[slug] == 0 {
+		mock.ExpectQuery("INSERT INTO \"article_slugs\" (.+) ON CONFLICT DO NOTHING RETURNING \"id\"").
+			WithArgs(articleID, slug, sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	}
+
+	mock.ExpectExec("UPDATE \"articles\" SET \"slug\"=(.+) WHERE \"id\" = (.+)").
+		WithArgs(slug, articleID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+}
+
+// expectTagsReplace expects the tags of an article without tags to be replaced.
+func expectTagsReplace(mock sqlmock.Sqlmock, articleID int) {
+	mock.ExpectExec("DELETE FROM \"article_tags\" WHERE article_id = (.+)").
+		WithArgs(articleID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+}
+
 // TestCreateArticleDatabase tests the CreateArticle function
 // which creates an article in the database.
 //
@@ -22,23 +81,59 @@ func TestCreateArticleDatabase(t *testing.T) {
 	client, _ := elasticMockConnection()
 
 	// Create a test article
+	categoryID := 3
 	item := article.Article{
-		Title:   "Test Article",
-		Body:    "This is a test article.",
-		Author:  "John Doe",
-		Created: time.Now(),
+		Title:      "Test Article",
+		Body:       "This is a test article.",
+		Author:     "John Doe",
+		Created:    time.Now(),
+		Updated:    time.Now(),
+		Version:    1,
+		Status:     article.StatusDraft,
+		Tags:       []string{"golang", "testing"},
+		CategoryID: &categoryID,
 	}
 
 	// Begin the transaction
 	mock.ExpectBegin()
 
+	// Expect the category to be locked while the article is filed under it
+	mock.ExpectQuery("SELECT \"path\" FROM \"categories\" WHERE id = (.+) FOR SHARE").
+		WithArgs(categoryID).
+		WillReturnRows(sqlmock.NewRows([]string{"path"}).AddRow("tech/golang"))
+
 	// Expect an insert query and return the inserted ID
 	mock.ExpectQuery("INSERT INTO (.+)").WithArgs(
 		item.Title,
 		item.Body,
 		item.Author,
 		item.Created,
-	).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(item.ID))
+		item.Updated,
+		item.Slug,
+		item.Version,
+		item.Status,
+		item.PublishAt,
+		item.CategoryID,
+		item.DeletedAt,
+	).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+
+	// Expect the slug of the title to be reserved
+	expectSlugReserve(mock, 1, "test-article", nil, "test-article")
+
+	// Expect the missing tags to be created and the article linked to every tag
+	mock.ExpectQuery("INSERT INTO \"tags\" (.+) ON CONFLICT DO NOTHING RETURNING \"id\"").
+		WithArgs("golang", sqlmock.AnyArg(), "testing", sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(5))
+	mock.ExpectQuery("SELECT \"id\" FROM \"tags\" WHERE name IN (.+)").
+		WithArgs("golang", "testing").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(4).AddRow(5))
+	mock.ExpectExec("INSERT INTO \"article_tags\" (.+)").
+		WithArgs(1, 4, 1, 5).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	// Expect the first revision and an outbox record in the same transaction
+	expectRevisionInsert(mock, 1, 1, "jane", "First draft")
+	expectOutboxInsert(mock, 1, article.OutboxActionCreated)
 
 	// Commit the transaction
 	mock.ExpectCommit()
@@ -47,10 +142,247 @@ func TestCreateArticleDatabase(t *testing.T) {
 	repo := articleimpl.NewArticleCommandRepository(db, client)
 
 	// Create the article using the repository
-	err := repo.CreateArticle(&item)
+	err := repo.CreateArticle(&item, article.NewArticleRevision(&item, "jane", "First draft"))
 	assert.NoError(t, err)
+	assert.Equal(t, "test-article", item.Slug)
+	assert.Equal(t, "tech/golang", item.Category)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestUpdateArticleDatabase tests the UpdateArticle function
+// which updates an existing article in the database.
+func TestUpdateArticleDatabase(t *testing.T) {
+	client, _ := elasticMockConnection()
+
+	t.Run("Article updated", func(t *testing.T) {
+		db, mock := dbMockConnection()
+		item := article.Article{ID: 1, Title: "New Title", Body: "New body.", Author: "John Doe", Updated: time.Now(), Version: 3}
+
+		mock.ExpectBegin()
+		mock.ExpectExec("UPDATE \"articles\" SET (.+) WHERE version = (.+) AND \"articles\".\"deleted_at\" IS NULL AND \"id\" = (.+)").
+			WithArgs(item.Author, item.Body, item.CategoryID, item.PublishAt, item.Status, item.Title, item.Updated, 4, 3, item.ID).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		expectSlugReserve(mock, item.ID, "new-title", map[string]int{"new-title": 7, "new-title-2": 8}, "new-title-3")
+		expectTagsReplace(mock, item.ID)
+		expectRevisionInsert(mock, item.ID, 4, "jane", "Typo")
+		expectOutboxInsert(mock, item.ID, article.OutboxActionUpdated)
+		mock.ExpectCommit()
+
+		repo := articleimpl.NewArticleCommandRepository(db, client)
+		err := repo.UpdateArticle(&item, article.NewArticleRevision(&item, "jane", "Typo"))
+		assert.NoError(t, err)
+		assert.Equal(t, 4, item.Version)
+		assert.Equal(t, "new-title-3", item.Slug)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Former slug taken back", func(t *testing.T) {
+		db, mock := dbMockConnection()
+		item := article.Article{ID: 1, Title: "New Title", Body: "New body.", Author: "John Doe", Updated: time.Now(), Version: 3}
+
+		mock.ExpectBegin()
+		mock.ExpectExec("UPDATE \"articles\" SET (.+) WHERE version = (.+) AND \"articles\".\"deleted_at\" IS NULL AND \"id\" = (.+)").
+			WithArgs(item.Author, item.Body, item.CategoryID, item.PublishAt, item.Status, item.Title, item.Updated, 4, 3, item.ID).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		expectSlugReserve(mock, item.ID, "new-title", map[string]int{"new-title": 7, "new-title-2": 1}, "new-title-2")
+		expectTagsReplace(mock, item.ID)
+		expectRevisionInsert(mock, item.ID, 4, "jane", "")
+		expectOutboxInsert(mock, item.ID, article.OutboxActionUpdated)
+		mock.ExpectCommit()
+
+		repo := articleimpl.NewArticleCommandRepository(db, client)
+		err := repo.UpdateArticle(&item, article.NewArticleRevision(&item, "jane", ""))
+		assert.NoError(t, err)
+		assert.Equal(t, "new-title-2", item.Slug)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Slug kept", func(t *testing.T) {
+		db, mock := dbMockConnection()
+		item := article.Article{ID: 1, Title: "New title", Body: "New body.", Author: "John Doe", Updated: time.Now(), Version: 3, Slug: "new-title"}
+
+		mock.ExpectBegin()
+		mock.ExpectExec("UPDATE \"articles\" SET (.+) WHERE version = (.+) AND \"articles\".\"deleted_at\" IS NULL AND \"id\" = (.+)").
+			WithArgs(item.Author, item.Body, item.CategoryID, item.PublishAt, item.Status, item.Title, item.Updated, 4, 3, item.ID).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		expectTagsReplace(mock, item.ID)
+		expectRevisionInsert(mock, item.ID, 4, "jane", "")
+		expectOutboxInsert(mock, item.ID, article.OutboxActionUpdated)
+		mock.ExpectCommit()
+
+		repo := articleimpl.NewArticleCommandRepository(db, client)
+		err := repo.UpdateArticle(&item, article.NewArticleRevision(&item, "jane", ""))
+		assert.NoError(t, err)
+		assert.Equal(t, "new-title", item.Slug)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Article not found", func(t *testing.T) {
+		db, mock := dbMockConnection()
+		item := article.Article{ID: 2, Title: "New Title", Body: "New body.", Author: "John Doe", Updated: time.Now(), Version: 1}
+
+		mock.ExpectBegin()
+		mock.ExpectExec("UPDATE \"articles\" SET (.+) WHERE version = (.+) AND \"articles\".\"deleted_at\" IS NULL AND \"id\" = (.+)").
+			WithArgs(item.Author, item.Body, item.CategoryID, item.PublishAt, item.Status, item.Title, item.Updated, 2, 1, item.ID).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery("SELECT count\\(\\*\\) FROM \"articles\" WHERE id = (.+) AND \"articles\".\"deleted_at\" IS NULL").
+			WithArgs(item.ID).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+		mock.ExpectRollback()
+
+		repo := articleimpl.NewArticleCommandRepository(db, client)
+		err := repo.UpdateArticle(&item, article.NewArticleRevision(&item, "jane", ""))
+		assert.Equal(t, gorm.ErrRecordNotFound, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Article version mismatch", func(t *testing.T) {
+		db, mock := dbMockConnection()
+		item := article.Article{ID: 2, Title: "New Title", Body: "New body.", Author: "John Doe", Updated: time.Now(), Version: 1}
+
+		mock.ExpectBegin()
+		mock.ExpectExec("UPDATE \"articles\" SET (.+) WHERE version = (.+) AND \"articles\".\"deleted_at\" IS NULL AND \"id\" = (.+)").
+			WithArgs(item.Author, item.Body, item.CategoryID, item.PublishAt, item.Status, item.Title, item.Updated, 2, 1, item.ID).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery("SELECT count\\(\\*\\) FROM \"articles\" WHERE id = (.+) AND \"articles\".\"deleted_at\" IS NULL").
+			WithArgs(item.ID).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+		mock.ExpectRollback()
+
+		repo := articleimpl.NewArticleCommandRepository(db, client)
+		err := repo.UpdateArticle(&item, article.NewArticleRevision(&item, "jane", ""))
+		assert.Equal(t, article.ErrArticleVersionMismatch, err)
+		assert.Equal(t, 1, item.Version)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+// TestDeleteArticleDatabase tests the DeleteArticle function
+// which soft-deletes an article in the database.
+func TestDeleteArticleDatabase(t *testing.T) {
+	client, _ := elasticMockConnection()
+
+	t.Run("Article deleted at any version", func(t *testing.T) {
+		db, mock := dbMockConnection()
+
+		mock.ExpectBegin()
+		mock.ExpectExec("UPDATE \"articles\" SET \"deleted_at\"=(.+) WHERE \"articles\".\"id\" = (.+) AND \"articles\".\"deleted_at\" IS NULL").
+			WithArgs(sqlmock.AnyArg(), 1).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		expectOutboxInsert(mock, 1, article.OutboxActionDeleted)
+		mock.ExpectCommit()
+
+		repo := articleimpl.NewArticleCommandRepository(db, client)
+		err := repo.DeleteArticle(1, 0)
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Article version mismatch", func(t *testing.T) {
+		db, mock := dbMockConnection()
+
+		mock.ExpectBegin()
+		mock.ExpectExec("UPDATE \"articles\" SET \"deleted_at\"=(.+) WHERE version = (.+) AND \"articles\".\"id\" = (.+) AND \"articles\".\"deleted_at\" IS NULL").
+			WithArgs(sqlmock.AnyArg(), 2, 1).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery("SELECT count\\(\\*\\) FROM \"articles\" WHERE id = (.+) AND \"articles\".\"deleted_at\" IS NULL").
+			WithArgs(1).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+		mock.ExpectRollback()
+
+		repo := articleimpl.NewArticleCommandRepository(db, client)
+		err := repo.DeleteArticle(1, 2)
+		assert.Equal(t, article.ErrArticleVersionMismatch, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+// TestRestoreArticleDatabase tests the RestoreArticle function
+// which clears the deleted flag of an article in the database.
+func TestRestoreArticleDatabase(t *testing.T) {
+	db, mock := dbMockConnection()
+	client, _ := elasticMockConnection()
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE \"articles\" SET \"deleted_at\"=(.+),\"updated\"=(.+) WHERE id = (.+) AND deleted_at IS NOT NULL").
+		WithArgs(nil, sqlmock.AnyArg(), 1).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	repo := articleimpl.NewArticleCommandRepository(db, client)
+	err := repo.RestoreArticle(1)
+	assert.Equal(t, gorm.ErrRecordNotFound, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestPurgeDeletedArticlesDatabase tests the PurgeDeletedArticles function
+// which permanently removes the articles deleted before the given time.
+func TestPurgeDeletedArticlesDatabase(t *testing.T) {
+	db, mock := dbMockConnection()
+	client, _ := elasticMockConnection()
+	deletedBefore := time.Now()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT \"id\" FROM \"articles\" WHERE deleted_at IS NOT NULL AND deleted_at < (.+)").
+		WithArgs(deletedBefore).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3).AddRow(4))
+	mock.ExpectExec("DELETE FROM \"articles\" WHERE \"articles\".\"id\" IN (.+)").
+		WithArgs(3, 4).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec("DELETE FROM \"article_revisions\" WHERE article_id IN (.+)").
+		WithArgs(3, 4).
+		WillReturnResult(sqlmock.NewResult(0, 5))
+	mock.ExpectExec("DELETE FROM \"article_tags\" WHERE article_id IN (.+)").
+		WithArgs(3, 4).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectQuery("INSERT INTO \"article_outboxes\" (.+)").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(2))
+	mock.ExpectCommit()
+
+	repo := articleimpl.NewArticleCommandRepository(db, client)
+	ids, err := repo.PurgeDeletedArticles(deletedBefore)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{3, 4}, ids)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestPublishScheduledArticlesDatabase tests the PublishScheduledArticles function
+// which publishes the articles in review whose publish time has come.
+func TestPublishScheduledArticlesDatabase(t *testing.T) {
+	db, mock := dbMockConnection()
+	client, _ := elasticMockConnection()
+	now := time.Now()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT \"id\" FROM \"articles\" WHERE \\(status = (.+) AND publish_at <= (.+)\\) AND \"articles\".\"deleted_at\" IS NULL FOR UPDATE SKIP LOCKED").
+		WithArgs(article.StatusInReview, now).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3).AddRow(4))
+	mock.ExpectExec("UPDATE \"articles\" SET \"status\"=(.+),\"updated\"=(.+),\"version\"=version \\+ 1 WHERE id IN (.+)").
+		WithArgs(article.StatusPublished, now, 3, 4).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec("INSERT INTO article_revisions (.+) SELECT id, version, title, body, (.+) FROM articles WHERE id IN (.+)").
+		WithArgs(article.SchedulerEditor, "Published", now, 3, 4).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectQuery("INSERT INTO \"article_outboxes\" (.+)").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(2))
+	mock.ExpectCommit()
+
+	repo := articleimpl.NewArticleCommandRepository(db, client)
+	ids, err := repo.PublishScheduledArticles(now)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{3, 4}, ids)
+	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
 func TestCreateIndexArticle(t *testing.T) {
 	// TODO: Implement test cases for CreateIndexArticle function
 }
+
+// TestIndexArticleWithoutElasticsearch tests that nothing is indexed with the postgres search backend.
+func TestIndexArticleWithoutElasticsearch(t *testing.T) {
+	db, _ := dbMockConnection()
+	repo := articleimpl.NewArticleCommandRepository(db, nil)
+
+	assert.NoError(t, repo.CreateIndexArticle(context.Background(), &article.Article{ID: 1}))
+	assert.NoError(t, repo.DeleteIndexArticle(context.Background(), 1))
+}
@@ -0,0 +1,299 @@
+package articleimpl
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/elastic/go-elasticsearch/v8"
+	"github.com/elastic/go-elasticsearch/v8/esapi"
+	"github.com/redis/go-redis/v9"
+	"github.com/undercode99/article_service/config"
+	"github.com/undercode99/article_service/internal/app/article"
+	"gorm.io/gorm"
+)
+
+// consistencyScrollKeepAlive is how long the scroll context is kept between two pages of the index.
+const consistencyScrollKeepAlive = time.Minute
+
+type ArticleConsistencyChecker struct {
+	db                      *gorm.DB
+	elasticClient           *elasticsearch.TypedClient
+	redisClient             *redis.Client
+	articleOutboxRepository article.ArticleOutboxRepository
+	cacheKeys               articleCacheKeys
+	cacheCodec              *articleCacheCodec
+}
+
+// NewArticleConsistencyChecker creates a new instance of the ArticleConsistencyChecker struct.
+//
+// Parameters:
+// - db: the database, it is the source of truth.
+// - elasticClient: the Elasticsearch client the article index is read with.
+// - redisClient: the Redis client the article cache is read with.
+// - articleOutboxRepository: the repository used to schedule the re-indexing of broken entries.
+// - cfg: the application configuration, the cache entries are read with its Redis settings.
+//
+// Returns:
+// - a pointer to the newly created ArticleConsistencyChecker struct.
+func NewArticleConsistencyChecker(
+	db *gorm.DB,
+	elasticClient *elasticsearch.TypedClient,
+	redisClient *redis.Client,
+	articleOutboxRepository article.ArticleOutboxRepository,
+	cfg *config.Config,
+) article.ArticleConsistencyChecker {
+	return &ArticleConsistencyChecker{
+		db:                      db,
+		elasticClient:           elasticClient,
+		redisClient:             redisClient,
+		articleOutboxRepository: articleOutboxRepository,
+		cacheKeys:               newArticleCacheKeys(cfg.Cache),
+		cacheCodec:              newArticleCacheCodec(cfg.Cache),
+	}
+}
+
+// Check compares the articles in the database with the article index and cache.
+//
+// The IDs and content hashes of every live article, indexed document and cache
+// entry are collected and compared. In repair mode the missing, orphaned and
+// divergent documents are scheduled for re-indexing through the outbox, and the
+// orphaned and divergent cache entries are evicted.
+//
+// ctx: the context of the check.
+// batchSize: the number of entries read per batch from each store.
+// repair: whether the problems found are repaired.
+// Returns the report of the check and an error, if any.
+func (c *ArticleConsistencyChecker) Check(ctx context.Context, batchSize int, repair bool) (*article.ConsistencyReportDTO, error) {
+	database, err := c.databaseHashes(batchSize)
+	if err != nil {
+		return nil, err
+	}
+
+	index, err := c.indexHashes(ctx, batchSize)
+	if err != nil {
+		return nil, err
+	}
+
+	cache, err := c.cacheHashes(ctx, batchSize, database)
+	if err != nil {
+		return nil, err
+	}
+
+	report := article.NewConsistencyReport(database, index, cache)
+	if !repair || report.IsConsistent() {
+		return report, nil
+	}
+
+	// The dispatcher syncs the index with the current state of each article,
+	// so it handles missing, orphaned and divergent documents alike
+	var outboxes []*article.ArticleOutbox
+	for _, ids := range [][]int{report.MissingInIndex, report.OrphanedInIndex, report.DivergentInIndex} {
+		for _, id := range ids {
+			outboxes = append(outboxes, article.NewArticleOutbox(id, article.OutboxActionRepair))
+		}
+	}
+	if err := c.articleOutboxRepository.CreateOutbox(outboxes); err != nil {
+		return nil, err
+	}
+
+	var keys []string
+	var evicted []int
+	for _, ids := range [][]int{report.OrphanedInCache, report.DivergentInCache} {
+		for _, id := range ids {
+			keys = append(keys, c.cacheKeys.article(id))
+			evicted = append(evicted, id)
+		}
+	}
+	if len(keys) > 0 {
+		if err := c.redisClient.Del(ctx, keys...).Err(); err != nil {
+			return nil, err
+		}
+		// drop the copies kept in the processes of the replicas as well
+		if err := publishArticleInvalidation(ctx, c.redisClient, c.cacheKeys, evicted...); err != nil {
+			return nil, err
+		}
+	}
+
+	report.Repaired = true
+	return report, nil
+}
+
+// databaseHashes returns the content hashes of the live articles in the database keyed by ID.
+func (c *ArticleConsistencyChecker) databaseHashes(batchSize int) (map[int]string, error) {
+	hashes := map[int]string{}
+
+	var batch []article.Article
+	err := c.db.Select("id", "author", "title", "body", "version", "status", "publish_at", "category_id").
+		FindInBatches(&batch, batchSize, func(tx *gorm.DB, _ int) error {
+			if err := loadArticleTaxonomy(c.db, batch); err != nil {
+				return err
+			}
+			for i := range batch {
+				hashes[batch[i].ID] = batch[i].ContentHash()
+			}
+			return nil
+		}).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return hashes, nil
+}
+
+// indexHashes returns the content hashes of the documents in the article index keyed by ID.
+//
+// The index is read page by page with the scroll API.
+func (c *ArticleConsistencyChecker) indexHashes(ctx context.Context, batchSize int) (map[int]string, error) {
+	hashes := map[int]string{}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"size":    batchSize,
+		"_source": []string{"author", "title", "body", "version", "status", "publish_at", "tags", "category"},
+		"sort":    []string{"_doc"},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req := esapi.SearchRequest{
+		Index:  []string{article.IndexName},
+		Body:   bytes.NewReader(body),
+		Scroll: consistencyScrollKeepAlive,
+	}
+	res, err := req.Do(ctx, c.elasticClient)
+	if err != nil {
+		return nil, err
+	}
+
+	var scrollID string
+	defer func() {
+		if scrollID != "" {
+			clearScroll := esapi.ClearScrollRequest{ScrollID: []string{scrollID}}
+			if res, err := clearScroll.Do(context.Background(), c.elasticClient); err == nil {
+				res.Body.Close()
+			}
+		}
+	}()
+
+	for {
+		page, err := decodeScrollPage(res)
+		if err != nil {
+			return nil, err
+		}
+		scrollID = page.ScrollID
+
+		if len(page.Hits.Hits) == 0 {
+			return hashes, nil
+		}
+
+		for _, hit := range page.Hits.Hits {
+			id, err := strconv.Atoi(hit.ID)
+			if err != nil {
+				continue
+			}
+			hashes[id] = hit.Source.ContentHash()
+		}
+
+		next := esapi.ScrollRequest{
+			ScrollID: scrollID,
+			Scroll:   consistencyScrollKeepAlive,
+		}
+		res, err = next.Do(ctx, c.elasticClient)
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+type scrollPage struct {
+	ScrollID string `json:"_scroll_id"`
+	Hits     struct {
+		Hits []struct {
+			ID     string          `json:"_id"`
+			Source article.Article `json:"_source"`
+		} `json:"hits"`
+	} `json:"hits"`
+}
+
+// decodeScrollPage decodes and closes a page of a scrolled search response.
+func decodeScrollPage(res *esapi.Response) (*scrollPage, error) {
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return nil, fmt.Errorf("elasticsearch error: %s", res.String())
+	}
+
+	var page scrollPage
+	if err := json.NewDecoder(res.Body).Decode(&page); err != nil {
+		return nil, err
+	}
+
+	return &page, nil
+}
+
+// cacheHashes returns the content hashes of the cached articles keyed by ID.
+//
+// The cache keys are iterated with SCAN so Redis is not blocked.
+func (c *ArticleConsistencyChecker) cacheHashes(ctx context.Context, batchSize int, database map[int]string) (map[int]string, error) {
+	hashes := map[int]string{}
+
+	var cursor uint64
+	for {
+		keys, next, err := c.redisClient.Scan(ctx, cursor, c.cacheKeys.articlePattern(), int64(batchSize)).Result()
+		if err != nil {
+			return nil, err
+		}
+
+		var ids []int
+		var articleKeys []string
+		for _, key := range keys {
+			id, err := c.cacheKeys.articleID(key)
+			if err != nil {
+				continue
+			}
+			ids = append(ids, id)
+			articleKeys = append(articleKeys, key)
+		}
+
+		if len(articleKeys) > 0 {
+			values, err := c.redisClient.MGet(ctx, articleKeys...).Result()
+			if err != nil {
+				return nil, err
+			}
+
+			for i, value := range values {
+				// the entry expired between SCAN and MGET
+				raw, ok := value.(string)
+				if !ok {
+					continue
+				}
+
+				var item article.Article
+				err := c.cacheCodec.decode([]byte(raw), &item)
+				switch err {
+				case nil:
+					hashes[ids[i]] = item.ContentHash()
+				case article.ErrArticleNotFound:
+					// a missing article is cached as such, it is only wrong if the article exists
+					if _, ok := database[ids[i]]; ok {
+						hashes[ids[i]] = ""
+					}
+				case errArticleCacheVersion:
+					// an entry of another version of the schema is a cache miss, it is replaced when read
+				default:
+					// an entry that cannot be decoded never matches the database
+					hashes[ids[i]] = ""
+				}
+			}
+		}
+
+		cursor = next
+		if cursor == 0 {
+			return hashes, nil
+		}
+	}
+}
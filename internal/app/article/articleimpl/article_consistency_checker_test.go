@@ -0,0 +1,213 @@
+package articleimpl_test
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/undercode99/article_service/internal/app/article"
+	"github.com/undercode99/article_service/internal/app/article/articleimpl"
+)
+
+// redisHandlerConnection returns a Redis client whose commands are answered by the given handler.
+//
+// The handler receives the command and its arguments and returns the raw RESP reply.
+func redisHandlerConnection(handler func(args []string) string) *redis.Client {
+	return redis.NewClient(&redis.Options{
+		Dialer: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			client, server := net.Pipe()
+			go serveRedis(server, handler)
+			return client, nil
+		},
+	})
+}
+
+// serveRedis reads RESP commands from the connection and writes the replies of the handler.
+func serveRedis(conn net.Conn, handler func(args []string) string) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		count, _ := strconv.Atoi(strings.TrimSpace(line[1:]))
+
+		args := make([]string, count)
+		for i := range args {
+			header, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			// the arguments are read by length, the cache entries are binary
+			size, _ := strconv.Atoi(strings.TrimSpace(header[1:]))
+			arg := make([]byte, size+2)
+			if _, err := io.ReadFull(reader, arg); err != nil {
+				return
+			}
+			args[i] = string(arg[:size])
+		}
+
+		if _, err := io.WriteString(conn, handler(args)); err != nil {
+			return
+		}
+	}
+}
+
+// TestArticleConsistencyChecker_Check tests the Check function.
+//
+// The database holds articles 1 and 2, the index misses 1 and holds a copy of 2 without its tags and an orphaned 3,
+// and the cache holds a stale copy of 1 and an orphaned 4. In repair mode the index problems
+// are scheduled through the outbox and the bad cache entries are evicted.
+func TestArticleConsistencyChecker_Check(t *testing.T) {
+	db, sqlMock := dbMockConnection()
+	sqlMock.ExpectQuery("SELECT \"id\",\"author\",\"title\",\"body\",\"version\",\"status\",\"publish_at\",\"category_id\" FROM \"articles\" WHERE \"articles\".\"deleted_at\" IS NULL ORDER BY \"articles\".\"id\" LIMIT 100").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "author", "title", "body", "version", "status", "publish_at", "category_id"}).
+			AddRow(1, "John Doe", "First", "First body", 1, "published", nil, nil).
+			AddRow(2, "Jane Doe", "Second", "Second body", 1, "published", nil, 2))
+	expectTagsLoad(sqlMock, sqlmock.NewRows([]string{"article_id", "name"}).AddRow(2, "web"), 1, 2)
+	sqlMock.ExpectQuery("SELECT \"id\",\"path\" FROM \"categories\" WHERE id IN (.+)").
+		WithArgs(2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "path"}).AddRow(2, "tech"))
+
+	scrolled := false
+	elasticClient := elasticHandlerConnection(func(req *http.Request) (int, string) {
+		switch {
+		case req.Method == http.MethodPost && req.URL.Path == "/articles/_search":
+			return http.StatusOK, `{"_scroll_id":"scroll-1","hits":{"hits":[
+				{"_id":"2","_source":{"author":"Jane Doe","title":"Second","body":"Second body","version":1,"status":"published","category":"tech"}},
+				{"_id":"3","_source":{"author":"Jim Doe","title":"Third","body":"Third body","version":1,"status":"published"}}]}}`
+		case req.Method == http.MethodPost && req.URL.Path == "/_search/scroll":
+			scrolled = true
+			return http.StatusOK, `{"_scroll_id":"scroll-1","hits":{"hits":[]}}`
+		case req.Method == http.MethodDelete && strings.HasPrefix(req.URL.Path, "/_search/scroll"):
+			return http.StatusOK, `{"succeeded":true}`
+		}
+		t.Errorf("unexpected request %s %s", req.Method, req.URL.Path)
+		return http.StatusNotFound, `{}`
+	})
+
+	var mu sync.Mutex
+	var deleted, published []string
+	redisClient := redisHandlerConnection(func(args []string) string {
+		switch strings.ToUpper(args[0]) {
+		case "SCAN":
+			return "*2\r\n$1\r\n0\r\n*3\r\n$9\r\narticle:1\r\n$9\r\narticle:4\r\n$14\r\narticle:slug:x\r\n"
+		case "MGET":
+			stale := "\x05\x00" + `{"id":1,"author":"John Doe","title":"First","body":"Old body","version":1,"status":"published"}`
+			orphan := "\x05\x00" + `{"id":4,"author":"Jill Doe","title":"Fourth","body":"Fourth body","version":1,"status":"published"}`
+			return fmt.Sprintf("*2\r\n$%d\r\n%s\r\n$%d\r\n%s\r\n", len(stale), stale, len(orphan), orphan)
+		case "DEL":
+			mu.Lock()
+			deleted = append(deleted, args[1:]...)
+			mu.Unlock()
+			return fmt.Sprintf(":%d\r\n", len(args)-1)
+		case "PUBLISH":
+			mu.Lock()
+			published = append(published, args[2])
+			mu.Unlock()
+			return ":0\r\n"
+		}
+		return "-ERR unknown command\r\n"
+	})
+	defer redisClient.Close()
+
+	outboxRepo := &MockArticleOutboxRepository{}
+	outboxRepo.On("CreateOutbox", mock.MatchedBy(func(outboxes []*article.ArticleOutbox) bool {
+		return len(outboxes) == 3 &&
+			outboxes[0].ArticleID == 1 && outboxes[0].Action == article.OutboxActionRepair &&
+			outboxes[1].ArticleID == 3 && outboxes[1].Action == article.OutboxActionRepair &&
+			outboxes[2].ArticleID == 2 && outboxes[2].Action == article.OutboxActionRepair
+	})).Return(nil)
+
+	checker := articleimpl.NewArticleConsistencyChecker(db, elasticClient, redisClient, outboxRepo, cacheTestConfig())
+	report, err := checker.Check(context.Background(), 100, true)
+
+	assert.NoError(t, err)
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+	outboxRepo.AssertExpectations(t)
+	assert.True(t, scrolled)
+
+	assert.Equal(t, 2, report.DatabaseArticles)
+	assert.Equal(t, 2, report.IndexDocuments)
+	assert.Equal(t, 2, report.CacheEntries)
+	assert.Equal(t, []int{1}, report.MissingInIndex)
+	assert.Equal(t, []int{3}, report.OrphanedInIndex)
+	assert.Equal(t, []int{2}, report.DivergentInIndex)
+	assert.Equal(t, []int{4}, report.OrphanedInCache)
+	assert.Equal(t, []int{1}, report.DivergentInCache)
+	assert.True(t, report.Repaired)
+	assert.Equal(t, []string{"article:4", "article:1"}, deleted)
+	assert.Equal(t, []string{"4", "1"}, published)
+}
+
+// TestArticleConsistencyChecker_Check_DatabaseError tests that a database error aborts the check.
+func TestArticleConsistencyChecker_Check_DatabaseError(t *testing.T) {
+	db, sqlMock := dbMockConnection()
+	sqlMock.ExpectQuery("SELECT (.+) FROM \"articles\"").WillReturnError(errors.New("connection refused"))
+
+	elasticClient, _ := elasticMockConnection()
+	checker := articleimpl.NewArticleConsistencyChecker(db, elasticClient, nil, &MockArticleOutboxRepository{}, cacheTestConfig())
+	report, err := checker.Check(context.Background(), 100, false)
+
+	assert.Error(t, err)
+	assert.Nil(t, report)
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+}
+
+// TestArticleConsistencyChecker_Check_NotFoundMarker tests the cache entries of missing articles.
+//
+// A missing article cached for an existing article hides it and is divergent,
+// while one cached for an article that does not exist is left alone.
+func TestArticleConsistencyChecker_Check_NotFoundMarker(t *testing.T) {
+	db, sqlMock := dbMockConnection()
+	sqlMock.ExpectQuery("SELECT (.+) FROM \"articles\"").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "author", "title", "body", "version", "status", "publish_at", "category_id"}).
+			AddRow(1, "John Doe", "First", "First body", 1, "published", nil, nil))
+	expectTagsLoad(sqlMock, sqlmock.NewRows([]string{"article_id", "name"}), 1)
+
+	elasticClient := elasticHandlerConnection(func(req *http.Request) (int, string) {
+		switch {
+		case req.Method == http.MethodPost && req.URL.Path == "/articles/_search":
+			return http.StatusOK, `{"_scroll_id":"scroll-1","hits":{"hits":[
+				{"_id":"1","_source":{"author":"John Doe","title":"First","body":"First body","version":1,"status":"published"}}]}}`
+		case req.Method == http.MethodPost && req.URL.Path == "/_search/scroll":
+			return http.StatusOK, `{"_scroll_id":"scroll-1","hits":{"hits":[]}}`
+		case req.Method == http.MethodDelete && strings.HasPrefix(req.URL.Path, "/_search/scroll"):
+			return http.StatusOK, `{"succeeded":true}`
+		}
+		t.Errorf("unexpected request %s %s", req.Method, req.URL.Path)
+		return http.StatusNotFound, `{}`
+	})
+
+	redisClient := redisHandlerConnection(func(args []string) string {
+		switch strings.ToUpper(args[0]) {
+		case "SCAN":
+			return "*2\r\n$1\r\n0\r\n*2\r\n$9\r\narticle:1\r\n$9\r\narticle:2\r\n"
+		case "MGET":
+			return "*2\r\n$2\r\n\x05\x04\r\n$2\r\n\x05\x04\r\n"
+		}
+		return "-ERR unknown command\r\n"
+	})
+	defer redisClient.Close()
+
+	checker := articleimpl.NewArticleConsistencyChecker(db, elasticClient, redisClient, &MockArticleOutboxRepository{}, cacheTestConfig())
+	report, err := checker.Check(context.Background(), 100, false)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, report.CacheEntries)
+	assert.Empty(t, report.OrphanedInCache)
+	assert.Equal(t, []int{1}, report.DivergentInCache)
+}
@@ -0,0 +1,47 @@
+package articleimpl
+
+import (
+	"encoding/base64"
+	"encoding/json"
+
+	"github.com/undercode99/article_service/internal/app/article"
+)
+
+// articleCursorKeepAlive is how long the point in time of a cursor is kept between two pages.
+const articleCursorKeepAlive = "5m"
+
+// articleCursor is the position of a cursor paginated traversal of the articles.
+//
+// It is handed to clients as an opaque base64 string.
+type articleCursor struct {
+	// PIT is the ID of the point in time the traversal reads from.
+	PIT string `json:"pit"`
+	// After holds the sort values of the last article of the previous page.
+	After []json.RawMessage `json:"after,omitempty"`
+}
+
+// encode returns the opaque string form of the cursor.
+func (c *articleCursor) encode() (string, error) {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// decodeArticleCursor parses the opaque string form of a cursor.
+//
+// article.ErrInvalidCursor is returned if the string is not a cursor.
+func decodeArticleCursor(value string) (*articleCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, article.ErrInvalidCursor
+	}
+
+	var cursor articleCursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.PIT == "" {
+		return nil, article.ErrInvalidCursor
+	}
+
+	return &cursor, nil
+}
@@ -0,0 +1,146 @@
+package articleimpl
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/undercode99/article_service/internal/app/article"
+	"github.com/undercode99/article_service/internal/searching"
+)
+
+// ArticleFallbackQueryRepository searches the articles in Elasticsearch and falls back
+// to Postgres while Elasticsearch is unavailable.
+//
+// The Elasticsearch requests go through a circuit breaker, so once it keeps failing
+// the searches go straight to Postgres until the breaker lets a trial request through.
+// The results of the fallback are flagged as degraded.
+type ArticleFallbackQueryRepository struct {
+	primary  article.ArticleQueryRepository
+	fallback article.ArticleQueryRepository
+	breaker  *searching.CircuitBreaker
+}
+
+func NewArticleFallbackQueryRepository(
+	primary article.ArticleQueryRepository,
+	fallback article.ArticleQueryRepository,
+	breaker *searching.CircuitBreaker,
+) article.ArticleQueryRepository {
+	return &ArticleFallbackQueryRepository{
+		primary:  primary,
+		fallback: fallback,
+		breaker:  breaker,
+	}
+}
+
+// GetArticleByID returns an article by its ID, it is always read from the database.
+func (r *ArticleFallbackQueryRepository) GetArticleByID(id int) (*article.Article, error) {
+	return r.primary.GetArticleByID(id)
+}
+
+// GetDeletedArticleByID returns a soft-deleted article by its ID, it is always read from the database.
+func (r *ArticleFallbackQueryRepository) GetDeletedArticleByID(id int) (*article.Article, error) {
+	return r.primary.GetDeletedArticleByID(id)
+}
+
+// GetListArticles retrieves a list of articles based on the provided query.
+//
+// A cursor handed out by the fallback keeps being served by the fallback so the
+// traversal stays consistent, a cursor of Elasticsearch cannot be continued by the
+// fallback and article.ErrCursorExpired is returned for it while Elasticsearch is unavailable.
+func (r *ArticleFallbackQueryRepository) GetListArticles(ctx context.Context, qry *article.ArticleQuery) (*article.ListArticleDTO, error) {
+	listFallback := func() (*article.ListArticleDTO, error) {
+		if qry.UseCursor && qry.Cursor != "" {
+			if _, err := decodeArticleKeysetCursor(qry.Cursor); err != nil {
+				return nil, article.ErrCursorExpired
+			}
+		}
+
+		result, err := r.fallback.GetListArticles(ctx, qry)
+		if err != nil {
+			return nil, err
+		}
+		result.Degraded = true
+		return result, nil
+	}
+
+	if qry.UseCursor && qry.Cursor != "" {
+		if _, err := decodeArticleKeysetCursor(qry.Cursor); err == nil {
+			return listFallback()
+		}
+	}
+
+	if !r.breaker.Allow() {
+		return listFallback()
+	}
+
+	result, err := r.primary.GetListArticles(ctx, qry)
+	if r.failed(ctx, err) {
+		return listFallback()
+	}
+	return result, err
+}
+
+// GetArticleSuggestions returns the titles and authors starting with the prefix of the query.
+func (r *ArticleFallbackQueryRepository) GetArticleSuggestions(ctx context.Context, qry *article.ArticleSuggestQuery) (*article.ArticleSuggestionsDTO, error) {
+	suggestFallback := func() (*article.ArticleSuggestionsDTO, error) {
+		result, err := r.fallback.GetArticleSuggestions(ctx, qry)
+		if err != nil {
+			return nil, err
+		}
+		result.Degraded = true
+		return result, nil
+	}
+
+	if !r.breaker.Allow() {
+		return suggestFallback()
+	}
+
+	result, err := r.primary.GetArticleSuggestions(ctx, qry)
+	if r.failed(ctx, err) {
+		return suggestFallback()
+	}
+	return result, err
+}
+
+// GetRelatedArticles returns the articles most similar to the source article.
+func (r *ArticleFallbackQueryRepository) GetRelatedArticles(ctx context.Context, qry *article.ArticleRelatedQuery, source *article.Article) (*article.RelatedArticlesDTO, error) {
+	relatedFallback := func() (*article.RelatedArticlesDTO, error) {
+		result, err := r.fallback.GetRelatedArticles(ctx, qry, source)
+		if err != nil {
+			return nil, err
+		}
+		result.Degraded = true
+		return result, nil
+	}
+
+	if !r.breaker.Allow() {
+		return relatedFallback()
+	}
+
+	result, err := r.primary.GetRelatedArticles(ctx, qry, source)
+	if r.failed(ctx, err) {
+		return relatedFallback()
+	}
+	return result, err
+}
+
+// failed records the outcome of an allowed Elasticsearch request in the breaker
+// and reports whether the request has to be served by the fallback.
+//
+// Errors of requests Elasticsearch rejected, such as an expired cursor, are not failures.
+// A request cancelled or timed out by the caller is neither, Elasticsearch did not answer it.
+func (r *ArticleFallbackQueryRepository) failed(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		r.breaker.Release()
+		return false
+	}
+	if !errors.Is(err, searching.ErrElasticUnavailable) {
+		r.breaker.Success()
+		return false
+	}
+
+	log.Printf("elasticsearch unavailable, falling back to postgres: %v", err)
+	r.breaker.Failure()
+	return true
+}
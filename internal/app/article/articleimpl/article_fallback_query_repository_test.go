@@ -0,0 +1,146 @@
+package articleimpl_test
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/undercode99/article_service/internal/app/article"
+	"github.com/undercode99/article_service/internal/app/article/articleimpl"
+	"github.com/undercode99/article_service/internal/searching"
+)
+
+// TestArticleFallbackQueryRepository_GetListArticles tests the fallback of the GetListArticles function.
+//
+// Elasticsearch results are returned as is, an unavailable Elasticsearch is replaced by the
+// degraded results of the fallback, and once the breaker is open Elasticsearch is not called.
+func TestArticleFallbackQueryRepository_GetListArticles(t *testing.T) {
+	ctx := context.Background()
+	qry := &article.ArticleQuery{Search: "golang"}
+	unavailable := fmt.Errorf("%w: connection refused", searching.ErrElasticUnavailable)
+
+	primary := &MockArticleQueryRepository{}
+	fallback := &MockArticleQueryRepository{}
+	breaker := searching.NewCircuitBreaker(2, time.Minute)
+	repo := articleimpl.NewArticleFallbackQueryRepository(primary, fallback, breaker)
+
+	primary.On("GetListArticles", qry).Return(&article.ListArticleDTO{Total: 3}, nil).Once()
+	result, err := repo.GetListArticles(ctx, qry)
+	assert.NoError(t, err)
+	assert.False(t, result.Degraded)
+
+	primary.On("GetListArticles", qry).Return((*article.ListArticleDTO)(nil), unavailable).Twice()
+	fallback.On("GetListArticles", qry).Return(&article.ListArticleDTO{Total: 2}, nil)
+	for i := 0; i < 3; i++ {
+		result, err = repo.GetListArticles(ctx, qry)
+		assert.NoError(t, err)
+		assert.True(t, result.Degraded)
+		assert.Equal(t, int64(2), result.Total)
+	}
+
+	assert.True(t, breaker.IsOpen())
+	primary.AssertNumberOfCalls(t, "GetListArticles", 3)
+	fallback.AssertNumberOfCalls(t, "GetListArticles", 3)
+}
+
+// TestArticleFallbackQueryRepository_GetListArticles_Errors tests that rejected requests are not served by the fallback.
+func TestArticleFallbackQueryRepository_GetListArticles_Errors(t *testing.T) {
+	primary := &MockArticleQueryRepository{}
+	fallback := &MockArticleQueryRepository{}
+	breaker := searching.NewCircuitBreaker(1, time.Minute)
+	repo := articleimpl.NewArticleFallbackQueryRepository(primary, fallback, breaker)
+
+	qry := &article.ArticleQuery{UseCursor: true, Cursor: "expired"}
+	primary.On("GetListArticles", qry).Return((*article.ListArticleDTO)(nil), article.ErrCursorExpired)
+
+	_, err := repo.GetListArticles(context.Background(), qry)
+	assert.Equal(t, article.ErrCursorExpired, err)
+	assert.False(t, breaker.IsOpen())
+	fallback.AssertNotCalled(t, "GetListArticles", mock.Anything)
+}
+
+// TestArticleFallbackQueryRepository_GetListArticles_Cancelled tests a request cancelled by the caller
+// does not close the breaker during a trial.
+func TestArticleFallbackQueryRepository_GetListArticles_Cancelled(t *testing.T) {
+	primary := &MockArticleQueryRepository{}
+	fallback := &MockArticleQueryRepository{}
+	breaker := searching.NewCircuitBreaker(1, 0)
+	repo := articleimpl.NewArticleFallbackQueryRepository(primary, fallback, breaker)
+
+	breaker.Failure()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	qry := &article.ArticleQuery{Search: "golang"}
+	primary.On("GetListArticles", qry).Return((*article.ListArticleDTO)(nil), context.Canceled)
+
+	_, err := repo.GetListArticles(ctx, qry)
+	assert.Equal(t, context.Canceled, err)
+	assert.True(t, breaker.IsOpen())
+	assert.True(t, breaker.Allow())
+	fallback.AssertNotCalled(t, "GetListArticles", mock.Anything)
+}
+
+// TestArticleFallbackQueryRepository_GetListArticles_Cursor tests the cursors while falling back.
+//
+// A cursor of the fallback keeps being served by the fallback, a cursor of Elasticsearch
+// cannot be continued by the fallback and is reported as expired.
+func TestArticleFallbackQueryRepository_GetListArticles_Cursor(t *testing.T) {
+	primary := &MockArticleQueryRepository{}
+	fallback := &MockArticleQueryRepository{}
+	breaker := searching.NewCircuitBreaker(1, time.Minute)
+	repo := articleimpl.NewArticleFallbackQueryRepository(primary, fallback, breaker)
+
+	keyset := &article.ArticleQuery{
+		UseCursor: true,
+		Cursor:    base64.RawURLEncoding.EncodeToString([]byte(`{"created":"2023-07-01T08:00:00Z","id":3}`)),
+	}
+	fallback.On("GetListArticles", keyset).Return(&article.ListArticleDTO{}, nil)
+
+	result, err := repo.GetListArticles(context.Background(), keyset)
+	assert.NoError(t, err)
+	assert.True(t, result.Degraded)
+	primary.AssertNotCalled(t, "GetListArticles", mock.Anything)
+
+	breaker.Failure()
+	pit := &article.ArticleQuery{
+		UseCursor: true,
+		Cursor:    base64.RawURLEncoding.EncodeToString([]byte(`{"pit":"pit-1","after":[1688198400000,3]}`)),
+	}
+	_, err = repo.GetListArticles(context.Background(), pit)
+	assert.Equal(t, article.ErrCursorExpired, err)
+}
+
+// TestArticleFallbackQueryRepository_GetRelatedArticles tests the fallback of the GetRelatedArticles function.
+func TestArticleFallbackQueryRepository_GetRelatedArticles(t *testing.T) {
+	primary := &MockArticleQueryRepository{}
+	fallback := &MockArticleQueryRepository{}
+	repo := articleimpl.NewArticleFallbackQueryRepository(primary, fallback, searching.NewCircuitBreaker(5, time.Minute))
+
+	qry := &article.ArticleRelatedQuery{ID: 1}
+	source := &article.Article{ID: 1}
+	primary.On("GetRelatedArticles", qry, source).Return(nil, fmt.Errorf("%w: 503", searching.ErrElasticUnavailable))
+	fallback.On("GetRelatedArticles", qry, source).Return(&article.RelatedArticlesDTO{}, nil)
+
+	result, err := repo.GetRelatedArticles(context.Background(), qry, source)
+	assert.NoError(t, err)
+	assert.True(t, result.Degraded)
+}
+
+// TestGetListArticlesElastic_Unavailable tests that the server errors of Elasticsearch are reported as unavailable.
+func TestGetListArticlesElastic_Unavailable(t *testing.T) {
+	client := elasticHandlerConnection(func(req *http.Request) (int, string) {
+		return http.StatusServiceUnavailable, `{"error":"all shards failed"}`
+	})
+
+	db, _ := dbMockConnection()
+	repo := articleimpl.NewArticleQueryRepository(db, client)
+	_, err := repo.GetListArticles(context.Background(), &article.ArticleQuery{})
+
+	assert.ErrorIs(t, err, searching.ErrElasticUnavailable)
+}
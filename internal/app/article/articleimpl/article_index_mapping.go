@@ -0,0 +1,57 @@
+package articleimpl
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types"
+)
+
+// articleIndexMappingVersion is the version of the article index mapping.
+//
+// Bump it whenever the mapping below changes, the service then reports the
+// served index as outdated until it is rebuilt with the reindex command.
+const articleIndexMappingVersion = 6
+
+// NewArticleIndexMapping returns the mapping of the article index.
+//
+// The author is a keyword for exact filtering with a text subfield for full-text
+// search, the title and body are analyzed with the given analyzer, the slug, status,
+// tags and category path are keywords and the timestamps are dates so they sort chronologically.
+// The author and title have a search_as_you_type subfield for prefix suggestions.
+// The version and analyzer are stored in the _meta field of the mapping.
+func NewArticleIndexMapping(analyzer string) *types.TypeMapping {
+	analyzerJSON, _ := json.Marshal(analyzer)
+
+	return &types.TypeMapping{
+		Meta_: types.Metadata{
+			"version":  json.RawMessage(strconv.Itoa(articleIndexMappingVersion)),
+			"analyzer": json.RawMessage(analyzerJSON),
+		},
+		Properties: map[string]types.Property{
+			"id": types.NewIntegerNumberProperty(),
+			"author": &types.KeywordProperty{
+				Fields: map[string]types.Property{
+					"text":    types.NewTextProperty(),
+					"suggest": types.NewSearchAsYouTypeProperty(),
+				},
+			},
+			"title": &types.TextProperty{
+				Analyzer: &analyzer,
+				Fields: map[string]types.Property{
+					"suggest": types.NewSearchAsYouTypeProperty(),
+				},
+			},
+			"body":        &types.TextProperty{Analyzer: &analyzer},
+			"created":     types.NewDateProperty(),
+			"updated":     types.NewDateProperty(),
+			"slug":        types.NewKeywordProperty(),
+			"version":     types.NewIntegerNumberProperty(),
+			"status":      types.NewKeywordProperty(),
+			"publish_at":  types.NewDateProperty(),
+			"tags":        types.NewKeywordProperty(),
+			"category":    types.NewKeywordProperty(),
+			"category_id": types.NewIntegerNumberProperty(),
+		},
+	}
+}
@@ -0,0 +1,35 @@
+package articleimpl_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/undercode99/article_service/internal/app/article/articleimpl"
+)
+
+// TestNewArticleIndexMapping tests the NewArticleIndexMapping function.
+//
+// It checks the field types of the mapping and that the analyzer is applied to the title and body.
+func TestNewArticleIndexMapping(t *testing.T) {
+	mapping, err := json.Marshal(articleimpl.NewArticleIndexMapping("english"))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"_meta": {"version": 6, "analyzer": "english"},
+		"properties": {
+			"id": {"type": "integer"},
+			"author": {"type": "keyword", "fields": {"text": {"type": "text"}, "suggest": {"type": "search_as_you_type"}}},
+			"title": {"type": "text", "analyzer": "english", "fields": {"suggest": {"type": "search_as_you_type"}}},
+			"body": {"type": "text", "analyzer": "english"},
+			"created": {"type": "date"},
+			"updated": {"type": "date"},
+			"slug": {"type": "keyword"},
+			"version": {"type": "integer"},
+			"status": {"type": "keyword"},
+			"publish_at": {"type": "date"},
+			"tags": {"type": "keyword"},
+			"category": {"type": "keyword"},
+			"category_id": {"type": "integer"}
+		}
+	}`, string(mapping))
+}
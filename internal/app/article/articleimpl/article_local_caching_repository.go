@@ -0,0 +1,182 @@
+package articleimpl
+
+import (
+	"context"
+	"log"
+	"strconv"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/undercode99/article_service/config"
+	"github.com/undercode99/article_service/internal/app/article"
+	"github.com/undercode99/article_service/internal/caching"
+)
+
+// publishArticleInvalidation tells every replica to drop its in-process copy of the articles.
+func publishArticleInvalidation(ctx context.Context, redisClient *redis.Client, keys articleCacheKeys, ids ...int) error {
+	for _, id := range ids {
+		if err := redisClient.Publish(ctx, keys.invalidation(), strconv.Itoa(id)).Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// NewArticleTieredCachingRepository returns the caching repository of the articles for the configuration.
+//
+// The articles are cached in Redis, and unless the in-process cache is disabled
+// the hot ones are also kept in the memory of each replica.
+func NewArticleTieredCachingRepository(ctx context.Context, cfg *config.Config, redisClient *redis.Client) article.ArticleCachingRepository {
+	redisRepository := NewArticleCachingRepository(redisClient, cfg)
+	if cfg.LocalCache.Size <= 0 {
+		return redisRepository
+	}
+
+	return NewArticleLocalCachingRepository(ctx, redisRepository, redisClient, cfg)
+}
+
+// ArticleLocalCachingRepository keeps the most recently read articles in process,
+// in front of another caching repository.
+//
+// An evicted article is dropped from the other tier and its ID is published over
+// Redis pub/sub, so every replica drops its copy. The TTL of the local entries
+// bounds how stale a replica can be if it missed an invalidation.
+type ArticleLocalCachingRepository struct {
+	next        article.ArticleCachingRepository
+	redisClient *redis.Client
+	keys        articleCacheKeys
+
+	// articles holds the cached articles, a nil article is cached as missing.
+	articles *caching.LRU[int, *article.Article]
+}
+
+// NewArticleLocalCachingRepository returns a new instance of article.ArticleCachingRepository
+// caching the articles in process in front of next.
+//
+// The invalidations published by the replicas are listened to until the context is cancelled.
+func NewArticleLocalCachingRepository(
+	ctx context.Context,
+	next article.ArticleCachingRepository,
+	redisClient *redis.Client,
+	cfg *config.Config,
+) article.ArticleCachingRepository {
+	r := &ArticleLocalCachingRepository{
+		next:        next,
+		redisClient: redisClient,
+		keys:        newArticleCacheKeys(cfg.Cache),
+		articles:    caching.NewLRU[int, *article.Article](cfg.LocalCache.Size, cfg.LocalCache.TTL),
+	}
+
+	go r.listenInvalidations(ctx)
+
+	return r
+}
+
+// listenInvalidations drops the articles whose IDs are published on the invalidation channel.
+func (r *ArticleLocalCachingRepository) listenInvalidations(ctx context.Context) {
+	pubsub := r.redisClient.Subscribe(ctx, r.keys.invalidation())
+	defer pubsub.Close()
+
+	messages := pubsub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case message, ok := <-messages:
+			if !ok {
+				return
+			}
+
+			id, err := strconv.Atoi(message.Payload)
+			if err != nil {
+				log.Printf("invalid article invalidation %q: %v", message.Payload, err)
+				continue
+			}
+			r.articles.Delete(id)
+		}
+	}
+}
+
+// CreateArticle caches the article in both tiers.
+func (r *ArticleLocalCachingRepository) CreateArticle(ctx context.Context, item *article.Article) error {
+	if err := r.next.CreateArticle(ctx, item); err != nil {
+		return err
+	}
+
+	r.articles.Set(item.ID, item)
+	return nil
+}
+
+// CreateArticleNotFound caches that no article with the given ID exists in both tiers.
+func (r *ArticleLocalCachingRepository) CreateArticleNotFound(ctx context.Context, id int) error {
+	if err := r.next.CreateArticleNotFound(ctx, id); err != nil {
+		return err
+	}
+
+	r.articles.Set(id, nil)
+	return nil
+}
+
+// GetArticleByID returns the article from the process if it is there, else from the other tier.
+func (r *ArticleLocalCachingRepository) GetArticleByID(ctx context.Context, id int) (*article.Article, error) {
+	if item, ok := r.articles.Get(id); ok {
+		if item == nil {
+			return nil, article.ErrArticleNotFound
+		}
+		return item, nil
+	}
+
+	item, err := r.next.GetArticleByID(ctx, id)
+	switch err {
+	case nil:
+		r.articles.Set(id, item)
+	case article.ErrArticleNotFound:
+		r.articles.Set(id, nil)
+	}
+	return item, err
+}
+
+// DeleteArticle evicts the article from both tiers and from the processes of the other replicas.
+func (r *ArticleLocalCachingRepository) DeleteArticle(ctx context.Context, id int) error {
+	r.articles.Delete(id)
+
+	if err := r.next.DeleteArticle(ctx, id); err != nil {
+		return err
+	}
+
+	return publishArticleInvalidation(ctx, r.redisClient, r.keys, id)
+}
+
+// CreateArticleSlug caches the ID of the article with the slug in the other tier only.
+func (r *ArticleLocalCachingRepository) CreateArticleSlug(ctx context.Context, slug string, id int) error {
+	return r.next.CreateArticleSlug(ctx, slug, id)
+}
+
+// GetArticleIDBySlug returns the ID of the article with the slug cached in the other tier.
+func (r *ArticleLocalCachingRepository) GetArticleIDBySlug(ctx context.Context, slug string) (int, error) {
+	return r.next.GetArticleIDBySlug(ctx, slug)
+}
+
+// CreateRelatedArticles caches the related articles in the other tier only.
+func (r *ArticleLocalCachingRepository) CreateRelatedArticles(ctx context.Context, qry *article.ArticleRelatedQuery, related *article.RelatedArticlesDTO) error {
+	return r.next.CreateRelatedArticles(ctx, qry, related)
+}
+
+// GetRelatedArticles returns the related articles cached in the other tier.
+func (r *ArticleLocalCachingRepository) GetRelatedArticles(ctx context.Context, qry *article.ArticleRelatedQuery) (*article.RelatedArticlesDTO, error) {
+	return r.next.GetRelatedArticles(ctx, qry)
+}
+
+// CreateListArticles caches the list of articles in the other tier only.
+func (r *ArticleLocalCachingRepository) CreateListArticles(ctx context.Context, qry *article.ArticleQuery, list *article.ListArticleDTO) error {
+	return r.next.CreateListArticles(ctx, qry, list)
+}
+
+// GetListArticles returns the list of articles cached in the other tier.
+func (r *ArticleLocalCachingRepository) GetListArticles(ctx context.Context, qry *article.ArticleQuery) (*article.ListArticleDTO, error) {
+	return r.next.GetListArticles(ctx, qry)
+}
+
+// DeleteListArticles evicts the lists of articles of the authors from the other tier.
+func (r *ArticleLocalCachingRepository) DeleteListArticles(ctx context.Context, authors ...string) error {
+	return r.next.DeleteListArticles(ctx, authors...)
+}
@@ -0,0 +1,116 @@
+package articleimpl_test
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/undercode99/article_service/internal/app/article"
+	"github.com/undercode99/article_service/internal/app/article/articleimpl"
+)
+
+// TestArticleLocalCachingRepository tests the in-process tier of the cache.
+//
+// 1. Test an article read from the other tier is then served from the process.
+// 2. Test an article cached as missing is served from the process.
+// 3. Test an evicted article is dropped from both tiers and the eviction is published.
+func TestArticleLocalCachingRepository(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var mu sync.Mutex
+	var published []string
+	redisClient := redisHandlerConnection(func(args []string) string {
+		switch strings.ToUpper(args[0]) {
+		case "PUBLISH":
+			mu.Lock()
+			published = append(published, args[1]+" "+args[2])
+			mu.Unlock()
+			return ":1\r\n"
+		case "SUBSCRIBE":
+			return "*3\r\n$9\r\nsubscribe\r\n$18\r\narticle:invalidate\r\n:1\r\n"
+		case "PING":
+			return "*2\r\n$4\r\npong\r\n$0\r\n\r\n"
+		}
+		return "-ERR unknown command\r\n"
+	})
+	defer redisClient.Close()
+
+	next := &MockArticleCachingRepository{}
+	repo := articleimpl.NewArticleLocalCachingRepository(ctx, next, redisClient, cacheTestConfig())
+
+	t.Run("Article served from the process", func(t *testing.T) {
+		next.On("GetArticleByID", ctx, 1).Return(&article.Article{ID: 1, Title: "Golang testing"}, nil)
+
+		for i := 0; i < 3; i++ {
+			item, err := repo.GetArticleByID(ctx, 1)
+			assert.NoError(t, err)
+			assert.Equal(t, "Golang testing", item.Title)
+		}
+		next.AssertNumberOfCalls(t, "GetArticleByID", 1)
+	})
+
+	t.Run("Missing article served from the process", func(t *testing.T) {
+		next.On("CreateArticleNotFound", ctx, 2).Return(nil)
+
+		assert.NoError(t, repo.CreateArticleNotFound(ctx, 2))
+		_, err := repo.GetArticleByID(ctx, 2)
+		assert.Equal(t, article.ErrArticleNotFound, err)
+		next.AssertNotCalled(t, "GetArticleByID", ctx, 2)
+	})
+
+	t.Run("Article evicted", func(t *testing.T) {
+		next.On("DeleteArticle", ctx, 1).Return(nil)
+
+		assert.NoError(t, repo.DeleteArticle(ctx, 1))
+		next.AssertCalled(t, "DeleteArticle", ctx, 1)
+		mu.Lock()
+		assert.Equal(t, []string{"article:invalidate 1"}, published)
+		mu.Unlock()
+
+		_, err := repo.GetArticleByID(ctx, 1)
+		assert.NoError(t, err)
+		next.AssertNumberOfCalls(t, "GetArticleByID", 2)
+	})
+}
+
+// TestArticleLocalCachingRepository_Invalidation tests an article is dropped from the process
+// once its eviction is published by another replica.
+func TestArticleLocalCachingRepository_Invalidation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cached := make(chan struct{})
+	redisClient := redisHandlerConnection(func(args []string) string {
+		switch strings.ToUpper(args[0]) {
+		case "SUBSCRIBE":
+			// the other replica evicts the article once this one has cached it
+			<-cached
+			return "*3\r\n$9\r\nsubscribe\r\n$18\r\narticle:invalidate\r\n:1\r\n" +
+				"*3\r\n$7\r\nmessage\r\n$18\r\narticle:invalidate\r\n$1\r\n7\r\n"
+		case "PING":
+			return "*2\r\n$4\r\npong\r\n$0\r\n\r\n"
+		}
+		return "-ERR unknown command\r\n"
+	})
+	defer redisClient.Close()
+
+	next := &MockArticleCachingRepository{}
+	next.On("GetArticleByID", ctx, 7).Return(&article.Article{ID: 7, Title: "Old title"}, nil).Once()
+	next.On("GetArticleByID", ctx, 7).Return(&article.Article{ID: 7, Title: "New title"}, nil)
+
+	repo := articleimpl.NewArticleLocalCachingRepository(ctx, next, redisClient, cacheTestConfig())
+
+	item, err := repo.GetArticleByID(ctx, 7)
+	assert.NoError(t, err)
+	assert.Equal(t, "Old title", item.Title)
+	close(cached)
+
+	assert.Eventually(t, func() bool {
+		item, err := repo.GetArticleByID(ctx, 7)
+		return err == nil && item.Title == "New title"
+	}, time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,189 @@
+package articleimpl
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/undercode99/article_service/config"
+	"github.com/undercode99/article_service/internal/app/article"
+	"github.com/undercode99/article_service/internal/searching"
+	"gorm.io/gorm"
+)
+
+// outboxClaimLease is how long a claimed outbox record is hidden from other dispatchers.
+const outboxClaimLease = time.Minute
+
+type ArticleOutboxDispatcher struct {
+	articleOutboxRepository  article.ArticleOutboxRepository
+	articleCommandRepository article.ArticleCommandRepository
+	articleQueryRepository   article.ArticleQueryRepository
+	articleCachingRepository article.ArticleCachingRepository
+	cfg                      *config.OutboxConfig
+}
+
+// NewArticleOutboxDispatcher creates a new instance of the ArticleOutboxDispatcher struct.
+//
+// Parameters:
+// - articleOutboxRepository: the repository the pending changes are claimed from.
+// - articleCommandRepository: the repository used to write the search index.
+// - articleQueryRepository: the repository used to read the current state of an article.
+// - articleCachingRepository: the repository whose cached article and lists are evicted once the index changed.
+// - cfg: the application configuration, the outbox settings are used.
+//
+// Returns:
+// - a pointer to the newly created ArticleOutboxDispatcher struct.
+func NewArticleOutboxDispatcher(
+	articleOutboxRepository article.ArticleOutboxRepository,
+	articleCommandRepository article.ArticleCommandRepository,
+	articleQueryRepository article.ArticleQueryRepository,
+	articleCachingRepository article.ArticleCachingRepository,
+	cfg *config.Config,
+) article.ArticleOutboxDispatcher {
+	return &ArticleOutboxDispatcher{
+		articleOutboxRepository:  articleOutboxRepository,
+		articleCommandRepository: articleCommandRepository,
+		articleQueryRepository:   articleQueryRepository,
+		articleCachingRepository: articleCachingRepository,
+		cfg:                      cfg.Outbox,
+	}
+}
+
+// Dispatch propagates one batch of pending outbox records to the search index.
+//
+// Dispatched records are removed, failed records are scheduled for a retry
+// with exponential backoff or moved to the dead-letter state.
+//
+// While Elasticsearch is unavailable the rest of the batch is postponed without being
+// dispatched, and the postponed records keep their attempts so an outage does not
+// move them to the dead-letter state.
+// It returns the number of claimed records and any error encountered while claiming,
+// or the unavailability error when the batch was postponed.
+func (d *ArticleOutboxDispatcher) Dispatch(ctx context.Context) (int, error) {
+	outboxes, err := d.articleOutboxRepository.ClaimOutbox(d.cfg.BatchSize, outboxClaimLease)
+	if err != nil {
+		return 0, err
+	}
+
+	var unavailable error
+	for i := range outboxes {
+		outbox := &outboxes[i]
+
+		err := unavailable
+		if err == nil {
+			err = d.syncIndexArticle(ctx, outbox.ArticleID)
+		}
+		if err == nil {
+			if err := d.articleOutboxRepository.DeleteOutbox(outbox.ID); err != nil {
+				log.Printf("failed to delete dispatched outbox record %d: %v", outbox.ID, err)
+			}
+			continue
+		}
+
+		if errors.Is(err, searching.ErrElasticUnavailable) {
+			if unavailable == nil {
+				log.Printf("postponing outbox records while elasticsearch is unavailable: %v", err)
+			}
+			unavailable = err
+			outbox.Postpone(err, d.backoff(outbox.Attempts))
+			if err := d.articleOutboxRepository.UpdateOutbox(outbox); err != nil {
+				log.Printf("failed to update outbox record %d: %v", outbox.ID, err)
+			}
+			continue
+		}
+
+		log.Printf("failed to dispatch outbox record %d for article %d: %v", outbox.ID, outbox.ArticleID, err)
+		outbox.Fail(err, d.cfg.MaxAttempts, d.backoff(outbox.Attempts))
+		if outbox.Status == article.OutboxStatusDead {
+			log.Printf("outbox record %d for article %d moved to dead-letter after %d attempts", outbox.ID, outbox.ArticleID, outbox.Attempts)
+		}
+		if err := d.articleOutboxRepository.UpdateOutbox(outbox); err != nil {
+			log.Printf("failed to update outbox record %d: %v", outbox.ID, err)
+		}
+	}
+
+	return len(outboxes), unavailable
+}
+
+// Run dispatches the outbox every poll interval until the context is cancelled.
+//
+// Full batches are dispatched back to back so a backlog drains without waiting for the next tick,
+// dispatching pauses until the next tick while the search index is unavailable.
+func (d *ArticleOutboxDispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for {
+				dispatched, err := d.Dispatch(ctx)
+				if err != nil {
+					log.Printf("failed to dispatch outbox records: %v", err)
+				}
+				if err != nil || dispatched < d.cfg.BatchSize || ctx.Err() != nil {
+					break
+				}
+			}
+		}
+	}
+}
+
+// syncIndexArticle makes the search index match the current state of the article in the database.
+//
+// Live articles are (re)indexed, deleted or purged articles are removed from the index.
+// Because the current state is used, records can be dispatched in any order.
+//
+// The cached article is evicted again, its eviction by the change may have failed after the
+// change was committed, and the record is retried until it succeeds.
+// The cached lists may have been filled from the index before it changed, so they are
+// evicted again: those of the author of a live article, every list for a removed article
+// since its author is no longer known.
+func (d *ArticleOutboxDispatcher) syncIndexArticle(ctx context.Context, id int) error {
+	item, err := d.articleQueryRepository.GetArticleByID(id)
+	if err == gorm.ErrRecordNotFound {
+		if err := d.articleCommandRepository.DeleteIndexArticle(ctx, id); err != nil {
+			return err
+		}
+		if err := d.articleCachingRepository.DeleteArticle(ctx, id); err != nil {
+			return err
+		}
+		d.evictListArticles(ctx)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := d.articleCommandRepository.CreateIndexArticle(ctx, item); err != nil {
+		return err
+	}
+	if err := d.articleCachingRepository.DeleteArticle(ctx, id); err != nil {
+		return err
+	}
+	d.evictListArticles(ctx, item.Author)
+	return nil
+}
+
+// evictListArticles evicts the cached lists of the authors, a failure is only logged
+// since the index is already in sync and the lists expire shortly anyway.
+func (d *ArticleOutboxDispatcher) evictListArticles(ctx context.Context, authors ...string) {
+	if err := d.articleCachingRepository.DeleteListArticles(ctx, authors...); err != nil {
+		log.Printf("failed to evict cache for article lists: %v", err)
+	}
+}
+
+// backoff returns the delay before the next attempt of a record that already failed the given number of times.
+func (d *ArticleOutboxDispatcher) backoff(attempts int) time.Duration {
+	delay := d.cfg.RetryBackoff
+	for i := 0; i < attempts && delay < d.cfg.RetryMaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > d.cfg.RetryMaxBackoff {
+		delay = d.cfg.RetryMaxBackoff
+	}
+	return delay
+}
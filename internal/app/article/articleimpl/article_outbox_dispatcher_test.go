@@ -0,0 +1,191 @@
+package articleimpl_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/undercode99/article_service/config"
+	"github.com/undercode99/article_service/internal/app/article"
+	"github.com/undercode99/article_service/internal/app/article/articleimpl"
+	"github.com/undercode99/article_service/internal/searching"
+	"gorm.io/gorm"
+)
+
+func outboxTestConfig() *config.Config {
+	return &config.Config{
+		Outbox: &config.OutboxConfig{
+			PollInterval:    time.Second,
+			BatchSize:       10,
+			MaxAttempts:     3,
+			RetryBackoff:    time.Second,
+			RetryMaxBackoff: time.Minute,
+		},
+	}
+}
+
+// TestArticleOutboxDispatcher_Dispatch tests the Dispatch method of the ArticleOutboxDispatcher.
+//
+// 1. Test a live article is indexed, it and the lists of its author evicted and the record removed.
+// 2. Test a deleted article is removed from the index, it and every list evicted.
+// 3. Test a failed record is scheduled for a retry.
+// 4. Test a record is dead-lettered once it runs out of attempts.
+// 5. Test a record is retried when the indexed article could not be evicted from the cache.
+// 6. Test the batch is postponed without counting attempts while Elasticsearch is unavailable.
+func TestArticleOutboxDispatcher_Dispatch(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Live article indexed", func(t *testing.T) {
+		mockOutboxRepo := &MockArticleOutboxRepository{}
+		mockCommandRepo := &MockArticleCommandRepository{}
+		mockQueryRepo := &MockArticleQueryRepository{}
+		mockCachingRepo := &MockArticleCachingRepository{}
+		dispatcher := articleimpl.NewArticleOutboxDispatcher(mockOutboxRepo, mockCommandRepo, mockQueryRepo, mockCachingRepo, outboxTestConfig())
+
+		item := &article.Article{ID: 1, Title: "Test Article", Author: "John Doe"}
+		mockOutboxRepo.On("ClaimOutbox", 10, mock.Anything).Return([]article.ArticleOutbox{{ID: 7, ArticleID: 1}}, nil)
+		mockQueryRepo.On("GetArticleByID", 1).Return(item, nil)
+		mockCommandRepo.On("CreateIndexArticle", ctx, item).Return(nil)
+		mockCachingRepo.On("DeleteArticle", ctx, 1).Return(nil)
+		mockCachingRepo.On("DeleteListArticles", ctx, []string{"John Doe"}).Return(nil)
+		mockOutboxRepo.On("DeleteOutbox", 7).Return(nil)
+
+		dispatched, err := dispatcher.Dispatch(ctx)
+
+		assert.Nil(t, err)
+		assert.Equal(t, 1, dispatched)
+		mockCommandRepo.AssertCalled(t, "CreateIndexArticle", ctx, item)
+		mockOutboxRepo.AssertCalled(t, "DeleteOutbox", 7)
+		mockCachingRepo.AssertCalled(t, "DeleteArticle", ctx, 1)
+		mockCachingRepo.AssertCalled(t, "DeleteListArticles", ctx, []string{"John Doe"})
+	})
+
+	t.Run("Deleted article removed from index", func(t *testing.T) {
+		mockOutboxRepo := &MockArticleOutboxRepository{}
+		mockCommandRepo := &MockArticleCommandRepository{}
+		mockQueryRepo := &MockArticleQueryRepository{}
+		mockCachingRepo := &MockArticleCachingRepository{}
+		dispatcher := articleimpl.NewArticleOutboxDispatcher(mockOutboxRepo, mockCommandRepo, mockQueryRepo, mockCachingRepo, outboxTestConfig())
+
+		mockOutboxRepo.On("ClaimOutbox", 10, mock.Anything).Return([]article.ArticleOutbox{{ID: 8, ArticleID: 2}}, nil)
+		mockQueryRepo.On("GetArticleByID", 2).Return(nil, gorm.ErrRecordNotFound)
+		mockCommandRepo.On("DeleteIndexArticle", ctx, 2).Return(nil)
+		mockCachingRepo.On("DeleteArticle", ctx, 2).Return(nil)
+		mockCachingRepo.On("DeleteListArticles", ctx, []string(nil)).Return(nil)
+		mockOutboxRepo.On("DeleteOutbox", 8).Return(nil)
+
+		_, err := dispatcher.Dispatch(ctx)
+
+		assert.Nil(t, err)
+		mockCommandRepo.AssertCalled(t, "DeleteIndexArticle", ctx, 2)
+		mockOutboxRepo.AssertCalled(t, "DeleteOutbox", 8)
+		mockCachingRepo.AssertCalled(t, "DeleteArticle", ctx, 2)
+		mockCachingRepo.AssertCalled(t, "DeleteListArticles", ctx, []string(nil))
+	})
+
+	t.Run("Failed record retried", func(t *testing.T) {
+		mockOutboxRepo := &MockArticleOutboxRepository{}
+		mockCommandRepo := &MockArticleCommandRepository{}
+		mockQueryRepo := &MockArticleQueryRepository{}
+		mockCachingRepo := &MockArticleCachingRepository{}
+		dispatcher := articleimpl.NewArticleOutboxDispatcher(mockOutboxRepo, mockCommandRepo, mockQueryRepo, mockCachingRepo, outboxTestConfig())
+
+		item := &article.Article{ID: 3}
+		mockOutboxRepo.On("ClaimOutbox", 10, mock.Anything).Return([]article.ArticleOutbox{{ID: 9, ArticleID: 3, Status: article.OutboxStatusPending, Attempts: 1}}, nil)
+		mockQueryRepo.On("GetArticleByID", 3).Return(item, nil)
+		mockCommandRepo.On("CreateIndexArticle", ctx, item).Return(errors.New("connection refused"))
+		mockOutboxRepo.On("UpdateOutbox", mock.Anything).Return(nil)
+
+		_, err := dispatcher.Dispatch(ctx)
+
+		assert.Nil(t, err)
+		mockOutboxRepo.AssertNotCalled(t, "DeleteOutbox", 9)
+		mockCachingRepo.AssertNotCalled(t, "DeleteListArticles", mock.Anything, mock.Anything)
+		mockOutboxRepo.AssertCalled(t, "UpdateOutbox", mock.MatchedBy(func(outbox *article.ArticleOutbox) bool {
+			// second failure waits twice the base backoff
+			delay := time.Until(outbox.NextAttemptAt)
+			return outbox.Status == article.OutboxStatusPending &&
+				outbox.Attempts == 2 &&
+				outbox.LastError == "connection refused" &&
+				delay > time.Second && delay <= 2*time.Second
+		}))
+	})
+
+	t.Run("Record dead-lettered", func(t *testing.T) {
+		mockOutboxRepo := &MockArticleOutboxRepository{}
+		mockCommandRepo := &MockArticleCommandRepository{}
+		mockQueryRepo := &MockArticleQueryRepository{}
+		mockCachingRepo := &MockArticleCachingRepository{}
+		dispatcher := articleimpl.NewArticleOutboxDispatcher(mockOutboxRepo, mockCommandRepo, mockQueryRepo, mockCachingRepo, outboxTestConfig())
+
+		mockOutboxRepo.On("ClaimOutbox", 10, mock.Anything).Return([]article.ArticleOutbox{{ID: 10, ArticleID: 4, Status: article.OutboxStatusPending, Attempts: 2}}, nil)
+		mockQueryRepo.On("GetArticleByID", 4).Return(nil, errors.New("database is down"))
+		mockOutboxRepo.On("UpdateOutbox", mock.Anything).Return(nil)
+
+		_, err := dispatcher.Dispatch(ctx)
+
+		assert.Nil(t, err)
+		mockOutboxRepo.AssertCalled(t, "UpdateOutbox", mock.MatchedBy(func(outbox *article.ArticleOutbox) bool {
+			return outbox.Status == article.OutboxStatusDead && outbox.Attempts == 3
+		}))
+	})
+
+	t.Run("Failed eviction retried", func(t *testing.T) {
+		mockOutboxRepo := &MockArticleOutboxRepository{}
+		mockCommandRepo := &MockArticleCommandRepository{}
+		mockQueryRepo := &MockArticleQueryRepository{}
+		mockCachingRepo := &MockArticleCachingRepository{}
+		dispatcher := articleimpl.NewArticleOutboxDispatcher(mockOutboxRepo, mockCommandRepo, mockQueryRepo, mockCachingRepo, outboxTestConfig())
+
+		item := &article.Article{ID: 4, Author: "John Doe"}
+		mockOutboxRepo.On("ClaimOutbox", 10, mock.Anything).Return([]article.ArticleOutbox{{ID: 13, ArticleID: 4, Status: article.OutboxStatusPending}}, nil)
+		mockQueryRepo.On("GetArticleByID", 4).Return(item, nil)
+		mockCommandRepo.On("CreateIndexArticle", ctx, item).Return(nil)
+		mockCachingRepo.On("DeleteArticle", ctx, 4).Return(errors.New("redis is down"))
+		mockOutboxRepo.On("UpdateOutbox", mock.Anything).Return(nil)
+
+		_, err := dispatcher.Dispatch(ctx)
+
+		assert.Nil(t, err)
+		mockOutboxRepo.AssertNotCalled(t, "DeleteOutbox", 13)
+		mockOutboxRepo.AssertCalled(t, "UpdateOutbox", mock.MatchedBy(func(outbox *article.ArticleOutbox) bool {
+			return outbox.Attempts == 1 && outbox.LastError == "redis is down"
+		}))
+	})
+
+	t.Run("Batch postponed while Elasticsearch is unavailable", func(t *testing.T) {
+		mockOutboxRepo := &MockArticleOutboxRepository{}
+		mockCommandRepo := &MockArticleCommandRepository{}
+		mockQueryRepo := &MockArticleQueryRepository{}
+		mockCachingRepo := &MockArticleCachingRepository{}
+		dispatcher := articleimpl.NewArticleOutboxDispatcher(mockOutboxRepo, mockCommandRepo, mockQueryRepo, mockCachingRepo, outboxTestConfig())
+
+		item := &article.Article{ID: 5}
+		mockOutboxRepo.On("ClaimOutbox", 10, mock.Anything).Return([]article.ArticleOutbox{
+			{ID: 11, ArticleID: 5, Status: article.OutboxStatusPending, Attempts: 2},
+			{ID: 12, ArticleID: 6, Status: article.OutboxStatusPending, Attempts: 2},
+		}, nil)
+		mockQueryRepo.On("GetArticleByID", 5).Return(item, nil)
+		mockCommandRepo.On("CreateIndexArticle", ctx, item).Return(fmt.Errorf("%w: connection refused", searching.ErrElasticUnavailable))
+		mockOutboxRepo.On("UpdateOutbox", mock.Anything).Return(nil)
+
+		dispatched, err := dispatcher.Dispatch(ctx)
+
+		assert.ErrorIs(t, err, searching.ErrElasticUnavailable)
+		assert.Equal(t, 2, dispatched)
+		mockQueryRepo.AssertNotCalled(t, "GetArticleByID", 6)
+		for _, id := range []int{11, 12} {
+			id := id
+			mockOutboxRepo.AssertCalled(t, "UpdateOutbox", mock.MatchedBy(func(outbox *article.ArticleOutbox) bool {
+				return outbox.ID == id &&
+					outbox.Status == article.OutboxStatusPending &&
+					outbox.Attempts == 2 &&
+					outbox.NextAttemptAt.After(time.Now())
+			}))
+		}
+	})
+}
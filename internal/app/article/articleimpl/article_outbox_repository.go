@@ -0,0 +1,147 @@
+package articleimpl
+
+import (
+	"time"
+
+	"github.com/undercode99/article_service/internal/app/article"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ArticleOutboxRepository struct {
+	db *gorm.DB
+}
+
+func NewArticleOutboxRepository(db *gorm.DB) article.ArticleOutboxRepository {
+	return &ArticleOutboxRepository{
+		db: db,
+	}
+}
+
+// CreateOutbox writes the given outbox records.
+func (r *ArticleOutboxRepository) CreateOutbox(outboxes []*article.ArticleOutbox) error {
+	if len(outboxes) == 0 {
+		return nil
+	}
+	return r.db.Create(outboxes).Error
+}
+
+// ClaimOutbox claims the pending outbox records that are due for dispatching.
+//
+// The claimed records are pushed back by the lease duration so other dispatchers
+// skip them, if the dispatcher dies before finishing they become due again once
+// the lease expires.
+func (r *ArticleOutboxRepository) ClaimOutbox(limit int, lease time.Duration) ([]article.ArticleOutbox, error) {
+	var outboxes []article.ArticleOutbox
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+
+		// Lock the due records, rows locked by another dispatcher are skipped
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", article.OutboxStatusPending, now).
+			Order("id").
+			Limit(limit).
+			Find(&outboxes).Error
+		if err != nil || len(outboxes) == 0 {
+			return err
+		}
+
+		ids := make([]int, len(outboxes))
+		for i, outbox := range outboxes {
+			ids[i] = outbox.ID
+		}
+
+		return tx.Model(&article.ArticleOutbox{}).
+			Where("id IN ?", ids).
+			Update("next_attempt_at", now.Add(lease)).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return outboxes, nil
+}
+
+// UpdateOutbox saves the attempts, error and status of an outbox record.
+func (r *ArticleOutboxRepository) UpdateOutbox(outbox *article.ArticleOutbox) error {
+	return r.db.Model(outbox).Updates(map[string]interface{}{
+		"status":          outbox.Status,
+		"attempts":        outbox.Attempts,
+		"last_error":      outbox.LastError,
+		"next_attempt_at": outbox.NextAttemptAt,
+	}).Error
+}
+
+// DeleteOutbox removes an outbox record once it has been dispatched.
+func (r *ArticleOutboxRepository) DeleteOutbox(id int) error {
+	return r.db.Delete(&article.ArticleOutbox{}, id).Error
+}
+
+// RetryOutbox moves a dead outbox record back to the pending state with a fresh set of attempts.
+//
+// article.ErrOutboxNotFound is returned if no dead record with the given ID exists.
+func (r *ArticleOutboxRepository) RetryOutbox(id int) error {
+	retried := r.db.Model(&article.ArticleOutbox{}).
+		Where("id = ? AND status = ?", id, article.OutboxStatusDead).
+		Updates(map[string]interface{}{
+			"status":          article.OutboxStatusPending,
+			"attempts":        0,
+			"next_attempt_at": time.Now(),
+		})
+	if retried.Error != nil {
+		return retried.Error
+	}
+
+	if retried.RowsAffected == 0 {
+		return article.ErrOutboxNotFound
+	}
+
+	return nil
+}
+
+// GetOutboxStats returns the depth of the outbox queue and its most recent failures.
+//
+// failuresLimit is the maximum number of failing records returned.
+func (r *ArticleOutboxRepository) GetOutboxStats(failuresLimit int) (*article.ArticleOutboxStatsDTO, error) {
+	stats := &article.ArticleOutboxStatsDTO{}
+
+	err := r.db.Model(&article.ArticleOutbox{}).
+		Where("status = ?", article.OutboxStatusPending).
+		Count(&stats.Pending).Error
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.db.Model(&article.ArticleOutbox{}).
+		Where("status = ? AND attempts > 0", article.OutboxStatusPending).
+		Count(&stats.Retrying).Error
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.db.Model(&article.ArticleOutbox{}).
+		Where("status = ?", article.OutboxStatusDead).
+		Count(&stats.Dead).Error
+	if err != nil {
+		return nil, err
+	}
+
+	if stats.Pending > 0 {
+		var oldest article.ArticleOutbox
+		err = r.db.Where("status = ?", article.OutboxStatusPending).Order("id").First(&oldest).Error
+		if err != nil {
+			return nil, err
+		}
+		stats.OldestPending = &oldest.Created
+	}
+
+	err = r.db.Where("attempts > 0").
+		Order("id DESC").
+		Limit(failuresLimit).
+		Find(&stats.Failures).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
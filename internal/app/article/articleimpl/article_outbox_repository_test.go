@@ -0,0 +1,72 @@
+package articleimpl_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/undercode99/article_service/internal/app/article"
+	"github.com/undercode99/article_service/internal/app/article/articleimpl"
+)
+
+// TestArticleOutboxRepository_ClaimOutbox tests the ClaimOutbox function.
+//
+// It checks that the due records are locked, skipping rows locked by other dispatchers,
+// and pushed back by the lease in the same transaction.
+func TestArticleOutboxRepository_ClaimOutbox(t *testing.T) {
+	db, mock := dbMockConnection()
+	repo := articleimpl.NewArticleOutboxRepository(db)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT (.+) FROM \"article_outboxes\" WHERE status = (.+) AND next_attempt_at <= (.+) ORDER BY id LIMIT 10 FOR UPDATE SKIP LOCKED").
+		WithArgs(article.OutboxStatusPending, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "article_id", "status"}).
+			AddRow(1, 11, article.OutboxStatusPending).
+			AddRow(2, 12, article.OutboxStatusPending))
+	mock.ExpectExec("UPDATE \"article_outboxes\" SET \"next_attempt_at\"=(.+) WHERE id IN (.+)").
+		WithArgs(sqlmock.AnyArg(), 1, 2).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+
+	outboxes, err := repo.ClaimOutbox(10, time.Minute)
+	assert.NoError(t, err)
+	assert.Len(t, outboxes, 2)
+	assert.Equal(t, 11, outboxes[0].ArticleID)
+	assert.Equal(t, 12, outboxes[1].ArticleID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestArticleOutboxRepository_RetryOutbox tests the RetryOutbox function.
+//
+// 1. Test a dead record is moved back to pending.
+// 2. Test there is no dead record with the given ID.
+func TestArticleOutboxRepository_RetryOutbox(t *testing.T) {
+	t.Run("Dead record retried", func(t *testing.T) {
+		db, mock := dbMockConnection()
+		repo := articleimpl.NewArticleOutboxRepository(db)
+
+		mock.ExpectBegin()
+		mock.ExpectExec("UPDATE \"article_outboxes\" SET (.+) WHERE id = (.+) AND status = (.+)").
+			WithArgs(0, sqlmock.AnyArg(), article.OutboxStatusPending, 1, article.OutboxStatusDead).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		err := repo.RetryOutbox(1)
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Record not found", func(t *testing.T) {
+		db, mock := dbMockConnection()
+		repo := articleimpl.NewArticleOutboxRepository(db)
+
+		mock.ExpectBegin()
+		mock.ExpectExec("UPDATE \"article_outboxes\" SET (.+) WHERE id = (.+) AND status = (.+)").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectCommit()
+
+		err := repo.RetryOutbox(2)
+		assert.Equal(t, article.ErrOutboxNotFound, err)
+	})
+}
@@ -0,0 +1,526 @@
+package articleimpl
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"html"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/elastic/go-elasticsearch/v8"
+	"github.com/undercode99/article_service/config"
+	"github.com/undercode99/article_service/internal/app/article"
+	"github.com/undercode99/article_service/internal/searching"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// headlineStart, headlineStop and headlineDelimiter mark the highlighted terms and
+	// separate the fragments of ts_headline, they are replaced once the text is escaped.
+	headlineStart     = "\x01"
+	headlineStop      = "\x02"
+	headlineDelimiter = "\x03"
+
+	// relatedBodyTerms is the number of leading words of the body the related articles are matched on.
+	relatedBodyTerms = 50
+)
+
+// createdFacetFormats are the to_char formats of the bucket keys of the created facet per interval,
+// they match the formats of the Elasticsearch backend.
+var createdFacetFormats = map[string]string{
+	article.FacetIntervalDay:   "YYYY-MM-DD",
+	article.FacetIntervalMonth: "YYYY-MM",
+	article.FacetIntervalYear:  "YYYY",
+}
+
+// NewArticleQueryRepositoryForBackend returns the article.ArticleQueryRepository of the search backend set in the config.
+//
+// The Elasticsearch backend falls back to Postgres while Elasticsearch is unavailable.
+func NewArticleQueryRepositoryForBackend(cfg *config.Config, db *gorm.DB, elasticClient *elasticsearch.TypedClient) article.ArticleQueryRepository {
+	postgres := NewArticlePostgresQueryRepository(db, cfg)
+	if cfg.SearchBackendIsPostgres() {
+		return postgres
+	}
+
+	breaker := searching.NewCircuitBreaker(cfg.Search.BreakerThreshold, cfg.Search.BreakerCooldown)
+	return NewArticleFallbackQueryRepository(NewArticleQueryRepository(db, elasticClient), postgres, breaker)
+}
+
+// ArticlePostgresQueryRepository searches the articles with the full-text search of Postgres.
+//
+// The title and body are matched against a weighted tsvector computed with the
+// configured text search configuration, indexed by MigrateArticleSearchIndex.
+// It follows the semantics of the Elasticsearch backend with a few approximations:
+// cursors are keyset positions without a snapshot, fragment sizes are converted
+// to words and related articles are ranked by the terms shared with the source.
+type ArticlePostgresQueryRepository struct {
+	db         *gorm.DB
+	textConfig string
+}
+
+func NewArticlePostgresQueryRepository(db *gorm.DB, cfg *config.Config) article.ArticleQueryRepository {
+	return &ArticlePostgresQueryRepository{
+		db:         db,
+		textConfig: cfg.Article.SearchTextConfig,
+	}
+}
+
+// MigrateArticleSearchIndex creates the GIN index of the full-text search of the articles.
+//
+// The index is built on the same expression the searches match on, so it must be
+// recreated when the text search configuration changes.
+func MigrateArticleSearchIndex(db *gorm.DB, cfg *config.Config) error {
+	return db.Exec(fmt.Sprintf(
+		"CREATE INDEX IF NOT EXISTS idx_articles_search ON articles USING GIN (%s)",
+		articleSearchVector(cfg.Article.SearchTextConfig),
+	)).Error
+}
+
+// articleSearchVector returns the SQL expression of the weighted tsvector of the title and body.
+func articleSearchVector(textConfig string) string {
+	cfg := quoteLiteral(textConfig)
+	return fmt.Sprintf(
+		"(setweight(to_tsvector(%[1]s, coalesce(title, '')), 'A') || setweight(to_tsvector(%[1]s, coalesce(body, '')), 'B'))",
+		cfg,
+	)
+}
+
+// quoteLiteral returns the value as an SQL string literal.
+func quoteLiteral(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
+
+// GetArticleByID returns an article by its ID, with its tags and category.
+func (r ArticlePostgresQueryRepository) GetArticleByID(id int) (*article.Article, error) {
+	var item article.Article
+	if err := r.db.First(&item, id).Error; err != nil {
+		return nil, err
+	}
+
+	items := []article.Article{item}
+	if err := loadArticleTaxonomy(r.db, items); err != nil {
+		return nil, err
+	}
+	return &items[0], nil
+}
+
+// GetDeletedArticleByID returns a soft-deleted article by its ID, without its tags and category.
+func (r ArticlePostgresQueryRepository) GetDeletedArticleByID(id int) (*article.Article, error) {
+	var item article.Article
+	if err := r.db.Unscoped().Where("deleted_at IS NOT NULL").First(&item, id).Error; err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// GetListArticles retrieves a page of the articles matching the query.
+//
+// In cursor mode the page follows the (created, id) position of the cursor,
+// articles written during the traversal may shift the following pages.
+func (r ArticlePostgresQueryRepository) GetListArticles(ctx context.Context, qry *article.ArticleQuery) (*article.ListArticleDTO, error) {
+	var cursor *articleKeysetCursor
+	if qry.UseCursor && qry.Cursor != "" {
+		var err error
+		cursor, err = decodeArticleKeysetCursor(qry.Cursor)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var total int64
+	count, err := r.filter(ctx, qry)
+	if err != nil {
+		return nil, err
+	}
+	if err := count.Count(&total).Error; err != nil {
+		return nil, err
+	}
+
+	list, err := r.filter(ctx, qry)
+	if err != nil {
+		return nil, err
+	}
+
+	order := "ASC"
+	if qry.SortNewest {
+		order = "DESC"
+	}
+	list = list.Order("created " + order).Order("id " + order).Limit(qry.GetLimit())
+
+	if qry.UseCursor {
+		if cursor != nil {
+			operator := ">"
+			if qry.SortNewest {
+				operator = "<"
+			}
+			list = list.Where("(created, id) "+operator+" (?, ?)", cursor.Created, cursor.ID)
+		}
+	} else {
+		list = list.Offset((qry.GetPage() - 1) * qry.GetLimit())
+	}
+
+	articles, err := r.find(list, qry)
+	if err != nil {
+		return nil, err
+	}
+
+	facets, err := r.facets(ctx, qry)
+	if err != nil {
+		return nil, err
+	}
+
+	if !qry.UseCursor {
+		result := article.NewListArticleDTO(articles, qry.GetPage(), qry.GetLimit(), total)
+		result.Facets = facets
+		return result, nil
+	}
+
+	result := &article.ListArticleDTO{
+		Articles: articles,
+		Limit:    qry.GetLimit(),
+		Total:    total,
+		Facets:   facets,
+	}
+
+	if len(articles) == qry.GetLimit() {
+		last := articles[len(articles)-1]
+		next := &articleKeysetCursor{Created: last.Created, ID: last.ID}
+		result.NextCursor, err = next.encode()
+		if err != nil {
+			return nil, err
+		}
+		result.HasNext = true
+	}
+
+	return result, nil
+}
+
+// filter returns the query of the articles matching the search terms and filters of the article query.
+func (r ArticlePostgresQueryRepository) filter(ctx context.Context, qry *article.ArticleQuery) (*gorm.DB, error) {
+	from, to, err := qry.GetCreatedRange()
+	if err != nil {
+		return nil, err
+	}
+
+	tx := r.db.WithContext(ctx).Model(&article.Article{}).Where("status = ?", qry.GetStatus())
+
+	if qry.Search != "" {
+		tx = tx.Where("? @@ ?", gorm.Expr(articleSearchVector(r.textConfig)), r.searchQuery(qry))
+	}
+	if authors := qry.GetAuthors(); len(authors) > 0 {
+		tx = tx.Where("author IN ?", authors)
+	}
+	if excluded := qry.GetExcludeAuthors(); len(excluded) > 0 {
+		tx = tx.Where("author NOT IN ?", excluded)
+	}
+	if from != nil {
+		tx = tx.Where("created >= ?", *from)
+	}
+	if to != nil {
+		tx = tx.Where("created < ?", *to)
+	}
+	if tags := qry.GetTags(); len(tags) > 0 {
+		// the articles having every tag
+		tx = tx.Where("id IN (?)", r.db.Model(&article.ArticleTag{}).
+			Select("article_tags.article_id").
+			Joins("JOIN tags ON tags.id = article_tags.tag_id").
+			Where("tags.name IN ?", tags).
+			Group("article_tags.article_id").
+			Having("count(*) = ?", len(tags)))
+	}
+	if category := qry.GetCategory(); category != "" {
+		tx = tx.Where("category_id IN (?)", categoryIDsQuery(r.db, category))
+	}
+
+	return tx, nil
+}
+
+// searchQuery returns the tsquery of the search terms for the match mode of the article query.
+//
+// MatchModeAll requires every term, MatchModePhrase requires the terms in order
+// and MatchModeAny requires at least one of the terms.
+func (r ArticlePostgresQueryRepository) searchQuery(qry *article.ArticleQuery) clause.Expr {
+	switch qry.GetMatchMode() {
+	case article.MatchModeAll:
+		return gorm.Expr("websearch_to_tsquery(?::regconfig, ?)", r.textConfig, qry.Search)
+	case article.MatchModePhrase:
+		return gorm.Expr("phraseto_tsquery(?::regconfig, ?)", r.textConfig, qry.Search)
+	default:
+		return gorm.Expr("websearch_to_tsquery(?::regconfig, ?)", r.textConfig, strings.Join(strings.Fields(qry.Search), " or "))
+	}
+}
+
+// articleSearchRow is an article with the highlighted fragments of its title and body.
+type articleSearchRow struct {
+	article.Article `gorm:"embedded"`
+	TitleHeadline   string
+	BodyHeadline    string
+}
+
+// find returns the articles of the query with their tags and category, and their highlighted fragments if requested.
+func (r ArticlePostgresQueryRepository) find(tx *gorm.DB, qry *article.ArticleQuery) ([]article.Article, error) {
+	var columns []interface{}
+	selection := "id, title, author, created, updated, slug, version, status, publish_at, category_id"
+	if !qry.IsSummary() {
+		selection += ", body"
+	}
+
+	if qry.IsHighlight() {
+		maxWords := qry.GetFragmentSize() / 6
+		if maxWords < 2 {
+			maxWords = 2
+		}
+		options := fmt.Sprintf("StartSel=%s, StopSel=%s, MaxWords=%d, MinWords=%d, MaxFragments=%d, FragmentDelimiter=%s",
+			headlineStart, headlineStop, maxWords, maxWords/2, 3, headlineDelimiter)
+
+		selection += ", ts_headline(?::regconfig, title, ?, ?) AS title_headline, ts_headline(?::regconfig, coalesce(body, ''), ?, ?) AS body_headline"
+		query := r.searchQuery(qry)
+		columns = append(columns,
+			r.textConfig, query, fmt.Sprintf("HighlightAll=true, StartSel=%s, StopSel=%s", headlineStart, headlineStop),
+			r.textConfig, query, options,
+		)
+	}
+
+	var rows []articleSearchRow
+	if err := tx.Select(selection, columns...).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	articles := make([]article.Article, len(rows))
+	for i, row := range rows {
+		articles[i] = row.Article
+		if !qry.IsHighlight() {
+			continue
+		}
+
+		preTag, postTag := qry.GetHighlightTags()
+		highlight := map[string][]string{}
+		if fragments := headlineFragments(row.TitleHeadline, preTag, postTag); len(fragments) > 0 {
+			highlight["title"] = fragments
+		}
+		if fragments := headlineFragments(row.BodyHeadline, preTag, postTag); len(fragments) > 0 {
+			highlight["body"] = fragments
+		}
+		if len(highlight) > 0 {
+			articles[i].Highlight = highlight
+		}
+	}
+
+	if err := loadArticleTaxonomy(tx.Session(&gorm.Session{NewDB: true}), articles); err != nil {
+		return nil, err
+	}
+	return articles, nil
+}
+
+// headlineFragments returns the fragments of a ts_headline result holding a highlighted term.
+//
+// The text is escaped so the fragments are safe to render as HTML, like the
+// highlights of the Elasticsearch backend, before the markers become the tags.
+func headlineFragments(headline, preTag, postTag string) []string {
+	var fragments []string
+	for _, fragment := range strings.Split(headline, headlineDelimiter) {
+		if !strings.Contains(fragment, headlineStart) {
+			continue
+		}
+		fragment = html.EscapeString(fragment)
+		fragment = strings.ReplaceAll(fragment, headlineStart, preTag)
+		fragment = strings.ReplaceAll(fragment, headlineStop, postTag)
+		fragments = append(fragments, fragment)
+	}
+	return fragments
+}
+
+// facets returns the requested facets of the articles matching the query, nil if none were requested.
+func (r ArticlePostgresQueryRepository) facets(ctx context.Context, qry *article.ArticleQuery) (*article.ArticleFacetsDTO, error) {
+	format, createdFacet := createdFacetFormats[qry.CreatedFacet]
+	if !qry.AuthorFacet && !createdFacet && !qry.TagFacet {
+		return nil, nil
+	}
+
+	facets := &article.ArticleFacetsDTO{}
+
+	if qry.AuthorFacet {
+		tx, err := r.filter(ctx, qry)
+		if err != nil {
+			return nil, err
+		}
+		err = tx.Select(`author AS key, count(*) AS count`).
+			Group("author").
+			Order("count DESC, author").
+			Limit(qry.GetAuthorFacetSize()).
+			Scan(&facets.Authors).Error
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if createdFacet {
+		tx, err := r.filter(ctx, qry)
+		if err != nil {
+			return nil, err
+		}
+		// the buckets are computed in UTC like the date histogram of Elasticsearch
+		err = tx.Select("to_char(date_trunc(?, created AT TIME ZONE 'UTC'), ?) AS key, count(*) AS count", qry.CreatedFacet, format).
+			Group("key").
+			Order("key").
+			Scan(&facets.Created).Error
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if qry.TagFacet {
+		articles, err := r.filter(ctx, qry)
+		if err != nil {
+			return nil, err
+		}
+		err = r.db.WithContext(ctx).Model(&article.ArticleTag{}).
+			Select("tags.name AS key, count(*) AS count").
+			Joins("JOIN tags ON tags.id = article_tags.tag_id").
+			Where("article_tags.article_id IN (?)", articles.Select("id")).
+			Group("tags.name").
+			Order("count DESC, tags.name").
+			Limit(qry.GetTagFacetSize()).
+			Scan(&facets.Tags).Error
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return facets, nil
+}
+
+// GetArticleSuggestions returns the titles and authors with words starting with the words of the query.
+//
+// Only the published articles are suggested.
+func (r ArticlePostgresQueryRepository) GetArticleSuggestions(ctx context.Context, qry *article.ArticleSuggestQuery) (*article.ArticleSuggestionsDTO, error) {
+	result := &article.ArticleSuggestionsDTO{
+		Titles:  []article.TitleSuggestionDTO{},
+		Authors: []string{},
+	}
+
+	prefix := prefixTSQuery(qry.Q)
+	if prefix == "" {
+		return result, nil
+	}
+
+	err := r.db.WithContext(ctx).Model(&article.Article{}).
+		Select("id, title, slug").
+		Where("status = ?", article.StatusPublished).
+		Where("to_tsvector('simple', title) @@ to_tsquery('simple', ?)", prefix).
+		Order("created DESC").
+		Limit(qry.GetLimit()).
+		Scan(&result.Titles).Error
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.db.WithContext(ctx).Model(&article.Article{}).
+		Distinct("author").
+		Where("status = ?", article.StatusPublished).
+		Where("to_tsvector('simple', author) @@ to_tsquery('simple', ?)", prefix).
+		Order("author").
+		Limit(qry.GetLimit()).
+		Pluck("author", &result.Authors).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// prefixTSQuery returns the tsquery text matching words starting with each word of the prefix.
+//
+// Only the letters and digits of the words are kept, so the result is always a valid tsquery.
+func prefixTSQuery(prefix string) string {
+	var terms []string
+	for _, word := range strings.Fields(prefix) {
+		word = strings.Map(func(r rune) rune {
+			if unicode.IsLetter(r) || unicode.IsDigit(r) {
+				return unicode.ToLower(r)
+			}
+			return -1
+		}, word)
+		if word != "" {
+			terms = append(terms, word+":*")
+		}
+	}
+	return strings.Join(terms, " & ")
+}
+
+// GetRelatedArticles returns the published articles sharing the most terms with the source article, without their body.
+//
+// The terms are taken from the title and the first words of the body of the source article.
+func (r ArticlePostgresQueryRepository) GetRelatedArticles(ctx context.Context, qry *article.ArticleRelatedQuery, source *article.Article) (*article.RelatedArticlesDTO, error) {
+	terms := strings.Fields(source.Body)
+	if len(terms) > relatedBodyTerms {
+		terms = terms[:relatedBodyTerms]
+	}
+	terms = append(strings.Fields(source.Title), terms...)
+
+	// any of the terms of the source matches, the more shared terms the higher the rank
+	query := gorm.Expr("replace(plainto_tsquery(?::regconfig, ?)::text, '&', '|')::tsquery", r.textConfig, strings.Join(terms, " "))
+	vector := gorm.Expr(articleSearchVector(r.textConfig))
+
+	boost := 1
+	if qry.SameAuthor {
+		boost = 2
+	}
+
+	var articles []article.Article
+	err := r.db.WithContext(ctx).Model(&article.Article{}).
+		Select("id, title, author, created, updated, slug").
+		Where("id <> ? AND status = ?", source.ID, article.StatusPublished).
+		Where("? @@ ?", vector, query).
+		Clauses(clause.OrderBy{Expression: clause.Expr{
+			SQL:                "ts_rank(?, ?) * CASE WHEN author = ? THEN ? ELSE 1 END DESC, id",
+			Vars:               []interface{}{vector, query, source.Author, boost},
+			WithoutParentheses: true,
+		}}).
+		Limit(qry.GetLimit()).
+		Find(&articles).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &article.RelatedArticlesDTO{Articles: articles}, nil
+}
+
+// articleKeysetCursor is the position of a cursor paginated traversal of the articles in Postgres.
+//
+// It is handed to clients as an opaque base64 string.
+type articleKeysetCursor struct {
+	// Created and ID are the sort values of the last article of the previous page.
+	Created time.Time `json:"created"`
+	ID      int       `json:"id"`
+}
+
+// encode returns the opaque string form of the cursor.
+func (c *articleKeysetCursor) encode() (string, error) {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// decodeArticleKeysetCursor parses the opaque string form of a cursor.
+//
+// article.ErrInvalidCursor is returned if the string is not a cursor.
+func decodeArticleKeysetCursor(value string) (*articleKeysetCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, article.ErrInvalidCursor
+	}
+
+	var cursor articleKeysetCursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.ID == 0 {
+		return nil, article.ErrInvalidCursor
+	}
+
+	return &cursor, nil
+}
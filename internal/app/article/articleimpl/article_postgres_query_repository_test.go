@@ -0,0 +1,224 @@
+package articleimpl_test
+
+import (
+	"context"
+	"database/sql/driver"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/undercode99/article_service/config"
+	"github.com/undercode99/article_service/internal/app/article"
+	"github.com/undercode99/article_service/internal/app/article/articleimpl"
+)
+
+// postgresSearchConfig returns the config of the postgres search backend.
+func postgresSearchConfig() *config.Config {
+	return &config.Config{
+		SearchBackend: config.SearchBackendPostgres,
+		Article:       &config.ArticleConfig{SearchTextConfig: "english"},
+	}
+}
+
+// postgresSearchVector is the tsvector the postgres backend matches the search terms on.
+const postgresSearchVector = `(setweight(to_tsvector('english', coalesce(title, '')), 'A') || setweight(to_tsvector('english', coalesce(body, '')), 'B'))`
+
+// expectTagsLoad expects the tags of the articles to be loaded, the rows link the article IDs to the tag names.
+func expectTagsLoad(sqlMock sqlmock.Sqlmock, rows *sqlmock.Rows, ids ...driver.Value) {
+	sqlMock.ExpectQuery(regexp.QuoteMeta(`SELECT article_tags.article_id, tags.name FROM "article_tags" JOIN tags ON tags.id = article_tags.tag_id WHERE article_tags.article_id IN (`) + `.+` + regexp.QuoteMeta(`) ORDER BY tags.name`)).
+		WithArgs(ids...).
+		WillReturnRows(rows)
+}
+
+// TestNewArticleQueryRepositoryForBackend tests that the repository of the configured search backend is returned.
+func TestNewArticleQueryRepositoryForBackend(t *testing.T) {
+	db, _ := dbMockConnection()
+	elasticClient, _ := elasticMockConnection()
+
+	repo := articleimpl.NewArticleQueryRepositoryForBackend(postgresSearchConfig(), db, nil)
+	assert.IsType(t, &articleimpl.ArticlePostgresQueryRepository{}, repo)
+
+	cfg := postgresSearchConfig()
+	cfg.SearchBackend = config.SearchBackendElasticsearch
+	cfg.Search = &config.SearchConfig{BreakerThreshold: 5, BreakerCooldown: time.Minute}
+	repo = articleimpl.NewArticleQueryRepositoryForBackend(cfg, db, elasticClient)
+	assert.IsType(t, &articleimpl.ArticleFallbackQueryRepository{}, repo)
+}
+
+// TestArticlePostgresQueryRepository_GetListArticles tests the GetListArticles function of the postgres backend.
+//
+// It checks that the search terms and filters restrict the count and the page, that the
+// highlighted fragments are escaped and tagged and that the facets are grouped.
+func TestArticlePostgresQueryRepository_GetListArticles(t *testing.T) {
+	db, sqlMock := dbMockConnection()
+	// the placeholders are numbered after the ones of the selected columns
+	where := regexp.MustCompile(`\\\$\d`).ReplaceAllString(
+		regexp.QuoteMeta(`WHERE status = $1 AND `+postgresSearchVector+` @@ websearch_to_tsquery($2::regconfig, $3) AND author IN ($4) AND author NOT IN ($5) AND created >= $6 AND "articles"."deleted_at" IS NULL`),
+		`\$\d+`,
+	)
+	from := time.Date(2023, 7, 1, 0, 0, 0, 0, time.UTC)
+
+	sqlMock.ExpectQuery(`SELECT count\(\*\) FROM "articles" `+where).
+		WithArgs(article.StatusPublished, "english", "golang or generics", "John Doe", "Jim Doe", from).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(11))
+	sqlMock.ExpectQuery(`SELECT id, title, author, created, updated, slug, version, status, publish_at, category_id, ts_headline\(.+\) AS title_headline, ts_headline\(.+\) AS body_headline FROM "articles" ` + where + ` ORDER BY created DESC,id DESC LIMIT 10 OFFSET 10`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "author", "created", "updated", "title_headline", "body_headline"}).
+			AddRow(1, "Golang generics", "John Doe", from, from, "\x01Golang\x02 \x01generics\x02", "Use <T any> with \x01generics\x02\x03no match here"))
+	expectTagsLoad(sqlMock, sqlmock.NewRows([]string{"article_id", "name"}), 1)
+	sqlMock.ExpectQuery(`SELECT author AS key, count\(\*\) AS count FROM "articles" ` + where + ` GROUP BY "author" ORDER BY count DESC, author LIMIT 10`).
+		WillReturnRows(sqlmock.NewRows([]string{"key", "count"}).AddRow("John Doe", 11))
+	sqlMock.ExpectQuery(`SELECT to_char\(date_trunc\(\$1, created AT TIME ZONE 'UTC'\), \$2\) AS key, count\(\*\) AS count FROM "articles" WHERE .+ GROUP BY "key" ORDER BY key`).
+		WillReturnRows(sqlmock.NewRows([]string{"key", "count"}).AddRow("2023-07", 11))
+
+	repo := articleimpl.NewArticlePostgresQueryRepository(db, postgresSearchConfig())
+	result, err := repo.GetListArticles(context.Background(), &article.ArticleQuery{
+		Search:         "golang generics",
+		Author:         "John Doe",
+		ExcludeAuthors: []string{"Jim Doe"},
+		CreatedFrom:    "2023-07-01",
+		SortNewest:     true,
+		Page:           2,
+		Highlight:      true,
+		PreTag:         "<mark>",
+		PostTag:        "</mark>",
+		View:           article.ArticleViewSummary,
+		AuthorFacet:    true,
+		CreatedFacet:   article.FacetIntervalMonth,
+	})
+
+	assert.NoError(t, err)
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+	assert.Equal(t, int64(11), result.Total)
+	assert.Equal(t, 2, result.TotalPages)
+	assert.Len(t, result.Articles, 1)
+	assert.Equal(t, []string{"<mark>Golang</mark> <mark>generics</mark>"}, result.Articles[0].Highlight["title"])
+	assert.Equal(t, []string{"Use &lt;T any&gt; with <mark>generics</mark>"}, result.Articles[0].Highlight["body"])
+	assert.Equal(t, []article.FacetBucketDTO{{Key: "John Doe", Count: 11}}, result.Facets.Authors)
+	assert.Equal(t, []article.FacetBucketDTO{{Key: "2023-07", Count: 11}}, result.Facets.Created)
+}
+
+// TestArticlePostgresQueryRepository_GetListArticles_Taxonomy tests the tags and category filters of the postgres backend.
+//
+// It checks that an article must have every tag and belong to the category or one of its subcategories,
+// that the tag facet counts the tags of the matching articles and that the tags and category path are loaded.
+func TestArticlePostgresQueryRepository_GetListArticles_Taxonomy(t *testing.T) {
+	db, sqlMock := dbMockConnection()
+	where := regexp.QuoteMeta(`WHERE status = $1 AND id IN (SELECT article_tags.article_id FROM "article_tags" JOIN tags ON tags.id = article_tags.tag_id WHERE tags.name IN ($2,$3) GROUP BY "article_tags"."article_id" HAVING count(*) = $4) AND category_id IN (SELECT "id" FROM "categories" WHERE path = $5 OR path LIKE $6) AND "articles"."deleted_at" IS NULL`)
+	created := time.Date(2023, 7, 1, 8, 0, 0, 0, time.UTC)
+
+	sqlMock.ExpectQuery(`SELECT count\(\*\) FROM "articles" `+where).
+		WithArgs(article.StatusPublished, "golang", "web development", 2, "tech", "tech/%").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	sqlMock.ExpectQuery(`SELECT id, title, author, created, updated, slug, version, status, publish_at, category_id FROM "articles" ` + where).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "author", "created", "updated", "category_id"}).
+			AddRow(1, "Golang servers", "John Doe", created, created, 2))
+	expectTagsLoad(sqlMock, sqlmock.NewRows([]string{"article_id", "name"}).
+		AddRow(1, "golang").
+		AddRow(1, "web development"), 1)
+	sqlMock.ExpectQuery(regexp.QuoteMeta(`SELECT "id","path" FROM "categories" WHERE id IN ($1)`)).
+		WithArgs(2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "path"}).AddRow(2, "tech/golang"))
+	sqlMock.ExpectQuery(regexp.QuoteMeta(`SELECT tags.name AS key, count(*) AS count FROM "article_tags" JOIN tags ON tags.id = article_tags.tag_id WHERE article_tags.article_id IN (SELECT "id" FROM "articles" `) + `.+` + regexp.QuoteMeta(`) GROUP BY "tags"."name" ORDER BY count DESC, tags.name LIMIT 5`)).
+		WillReturnRows(sqlmock.NewRows([]string{"key", "count"}).AddRow("golang", 1).AddRow("web development", 1))
+
+	repo := articleimpl.NewArticlePostgresQueryRepository(db, postgresSearchConfig())
+	result, err := repo.GetListArticles(context.Background(), &article.ArticleQuery{
+		Tags:         []string{"Golang", "web  development", "golang"},
+		Category:     "/Tech/",
+		View:         article.ArticleViewSummary,
+		TagFacet:     true,
+		TagFacetSize: 5,
+	})
+
+	assert.NoError(t, err)
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+	assert.Len(t, result.Articles, 1)
+	assert.Equal(t, []string{"golang", "web development"}, result.Articles[0].Tags)
+	assert.Equal(t, "tech/golang", result.Articles[0].Category)
+	assert.Equal(t, []article.FacetBucketDTO{{Key: "golang", Count: 1}, {Key: "web development", Count: 1}}, result.Facets.Tags)
+}
+
+// TestArticlePostgresQueryRepository_GetListArticles_Cursor tests the cursor pagination of the postgres backend.
+//
+// A full page returns a cursor holding the position of its last article, the next page starts after it.
+func TestArticlePostgresQueryRepository_GetListArticles_Cursor(t *testing.T) {
+	db, sqlMock := dbMockConnection()
+	repo := articleimpl.NewArticlePostgresQueryRepository(db, postgresSearchConfig())
+	created := time.Date(2023, 7, 1, 8, 0, 0, 0, time.UTC)
+
+	sqlMock.ExpectQuery(`SELECT count\(\*\) FROM "articles"`).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+	sqlMock.ExpectQuery(`SELECT id, title, author, created, updated, slug, version, status, publish_at, category_id, body FROM "articles" WHERE status = \$1 AND "articles"."deleted_at" IS NULL ORDER BY created ASC,id ASC LIMIT 2$`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "author", "created", "updated", "body"}).
+			AddRow(1, "First", "John Doe", created, created, "First body").
+			AddRow(2, "Second", "John Doe", created, created, "Second body"))
+	expectTagsLoad(sqlMock, sqlmock.NewRows([]string{"article_id", "name"}), 1, 2)
+
+	first, err := repo.GetListArticles(context.Background(), &article.ArticleQuery{UseCursor: true, Limit: 2})
+	assert.NoError(t, err)
+	assert.True(t, first.HasNext)
+	assert.NotEmpty(t, first.NextCursor)
+
+	sqlMock.ExpectQuery(`SELECT count\(\*\) FROM "articles"`).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+	sqlMock.ExpectQuery(regexp.QuoteMeta(`WHERE status = $1 AND (created, id) > ($2, $3) AND "articles"."deleted_at" IS NULL ORDER BY created ASC,id ASC LIMIT 2`)).
+		WithArgs(article.StatusPublished, created, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "author", "created", "updated", "body"}).
+			AddRow(3, "Third", "John Doe", created, created, "Third body"))
+	expectTagsLoad(sqlMock, sqlmock.NewRows([]string{"article_id", "name"}), 3)
+
+	last, err := repo.GetListArticles(context.Background(), &article.ArticleQuery{UseCursor: true, Cursor: first.NextCursor, Limit: 2})
+	assert.NoError(t, err)
+	assert.False(t, last.HasNext)
+	assert.Empty(t, last.NextCursor)
+	assert.Equal(t, 3, last.Articles[0].ID)
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+
+	_, err = repo.GetListArticles(context.Background(), &article.ArticleQuery{UseCursor: true, Cursor: "not-a-cursor"})
+	assert.Equal(t, article.ErrInvalidCursor, err)
+}
+
+// TestArticlePostgresQueryRepository_GetArticleSuggestions tests the GetArticleSuggestions function of the postgres backend.
+func TestArticlePostgresQueryRepository_GetArticleSuggestions(t *testing.T) {
+	db, sqlMock := dbMockConnection()
+	sqlMock.ExpectQuery(regexp.QuoteMeta(`SELECT id, title, slug FROM "articles" WHERE status = $1 AND to_tsvector('simple', title) @@ to_tsquery('simple', $2)`)).
+		WithArgs(article.StatusPublished, "go:* & gen:*").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title"}).AddRow(3, "Golang generics"))
+	sqlMock.ExpectQuery(regexp.QuoteMeta(`SELECT DISTINCT "author" FROM "articles" WHERE status = $1 AND to_tsvector('simple', author) @@ to_tsquery('simple', $2)`)).
+		WithArgs(article.StatusPublished, "go:* & gen:*").
+		WillReturnRows(sqlmock.NewRows([]string{"author"}).AddRow("Gordon Gentry"))
+
+	repo := articleimpl.NewArticlePostgresQueryRepository(db, postgresSearchConfig())
+	result, err := repo.GetArticleSuggestions(context.Background(), &article.ArticleSuggestQuery{Q: "Go ge-n"})
+
+	assert.NoError(t, err)
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+	assert.Equal(t, []article.TitleSuggestionDTO{{ID: 3, Title: "Golang generics"}}, result.Titles)
+	assert.Equal(t, []string{"Gordon Gentry"}, result.Authors)
+
+	// nothing to search for, the database is not queried
+	result, err = repo.GetArticleSuggestions(context.Background(), &article.ArticleSuggestQuery{Q: "--"})
+	assert.NoError(t, err)
+	assert.Empty(t, result.Titles)
+}
+
+// TestArticlePostgresQueryRepository_GetRelatedArticles tests the GetRelatedArticles function of the postgres backend.
+func TestArticlePostgresQueryRepository_GetRelatedArticles(t *testing.T) {
+	db, sqlMock := dbMockConnection()
+	sqlMock.ExpectQuery(regexp.QuoteMeta(`SELECT id, title, author, created, updated, slug FROM "articles" WHERE (id <> $1 AND status = $2) AND `+postgresSearchVector+` @@ replace(plainto_tsquery($3::regconfig, $4)::text, '&', '|')::tsquery`)+`.+`+
+		regexp.QuoteMeta(`* CASE WHEN author = $7 THEN $8 ELSE 1 END DESC, id LIMIT 5`)).
+		WithArgs(1, article.StatusPublished, "english", "Golang generics Type parameters", "english", "Golang generics Type parameters", "John Doe", 2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "author"}).AddRow(7, "Golang testing", "John Doe"))
+
+	repo := articleimpl.NewArticlePostgresQueryRepository(db, postgresSearchConfig())
+	result, err := repo.GetRelatedArticles(context.Background(),
+		&article.ArticleRelatedQuery{ID: 1, SameAuthor: true},
+		&article.Article{ID: 1, Title: "Golang generics", Body: "Type parameters", Author: "John Doe"})
+
+	assert.NoError(t, err)
+	assert.NoError(t, sqlMock.ExpectationsWereMet())
+	assert.Len(t, result.Articles, 1)
+	assert.Equal(t, 7, result.Articles[0].ID)
+}
@@ -5,10 +5,15 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"log"
+	"net/http"
 
 	"github.com/elastic/go-elasticsearch/v8"
 	"github.com/elastic/go-elasticsearch/v8/esapi"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/core/search"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types"
 	"github.com/undercode99/article_service/internal/app/article"
+	"github.com/undercode99/article_service/internal/searching"
 	"gorm.io/gorm"
 )
 
@@ -24,16 +29,30 @@ func NewArticleQueryRepository(db *gorm.DB, elasticClient *elasticsearch.TypedCl
 	}
 }
 
-// GetByID returns an article by its ID.
+// GetByID returns an article by its ID, with its tags and category.
 //
 // It takes an integer parameter representing the ID of the article to retrieve.
 // The function returns a pointer to the retrieved article and an error, if any.
 func (a ArticleQueryRepository) GetArticleByID(id int) (*article.Article, error) {
-	var article article.Article
-	if err := a.db.First(&article, id).Error; err != nil {
+	var item article.Article
+	if err := a.db.First(&item, id).Error; err != nil {
 		return nil, err
 	}
-	return &article, nil
+
+	items := []article.Article{item}
+	if err := loadArticleTaxonomy(a.db, items); err != nil {
+		return nil, err
+	}
+	return &items[0], nil
+}
+
+// GetDeletedArticleByID returns a soft-deleted article by its ID, without its tags and category.
+func (a ArticleQueryRepository) GetDeletedArticleByID(id int) (*article.Article, error) {
+	var item article.Article
+	if err := a.db.Unscoped().Where("deleted_at IS NOT NULL").First(&item, id).Error; err != nil {
+		return nil, err
+	}
+	return &item, nil
 }
 
 // GetListArticles retrieves a list of articles based on the provided query.
@@ -42,52 +61,13 @@ func (a ArticleQueryRepository) GetArticleByID(id int) (*article.Article, error)
 // qry: The article query object containing the search parameters.
 // Returns a ListArticleDTO and an error.
 func (a ArticleQueryRepository) GetListArticles(ctx context.Context, qry *article.ArticleQuery) (*article.ListArticleDTO, error) {
-	query := map[string]interface{}{
-		"query": map[string]interface{}{
-			"bool": map[string]interface{}{
-				"must":   []map[string]interface{}{},
-				"should": []map[string]interface{}{},
-			},
-		},
-		"sort": map[string]interface{}{
-			"created": map[string]interface{}{
-				"order": "asc",
-			},
-		},
-		"from": (qry.GetPage() - 1) * qry.GetLimit(),
-		"size": qry.GetLimit(),
-	}
-
-	if qry.Author != "" {
-		query["query"].(map[string]interface{})["bool"].(map[string]interface{})["must"] = []map[string]interface{}{
-			{
-				"match": map[string]interface{}{
-					"author": qry.Author,
-				},
-			},
-		}
+	query, err := searching.NewArticleSearchRequest(qry)
+	if err != nil {
+		return nil, err
 	}
 
-	if qry.Search != "" {
-		query["query"].(map[string]interface{})["bool"].(map[string]interface{})["should"] = []map[string]interface{}{
-			{
-				"match": map[string]interface{}{
-					"title": qry.Search,
-				},
-			},
-			{
-				"match": map[string]interface{}{
-					"body": qry.Search,
-				},
-			},
-		}
-	}
-	if qry.SortNewest {
-		query["sort"] = map[string]interface{}{
-			"created": map[string]interface{}{
-				"order": "desc",
-			},
-		}
+	if qry.UseCursor {
+		return a.getListArticlesByCursor(ctx, qry, query)
 	}
 
 	var buf bytes.Buffer
@@ -101,36 +81,332 @@ func (a ArticleQueryRepository) GetListArticles(ctx context.Context, qry *articl
 		Body:  &buf,
 	}
 
-	res, err := req.Do(ctx, a.elasticClient)
+	docs, err := a.search(ctx, req)
 	if err != nil {
 		return nil, err
 	}
+
+	result := article.NewListArticleDTO(docs.articles(), qry.GetPage(), qry.GetLimit(), docs.Hits.Total.Value)
+	result.Facets = docs.facets()
+	return result, nil
+}
+
+// GetArticleSuggestions returns the titles and authors starting with the prefix of the query.
+//
+// Only the ID and title of the matching articles are read, never their body,
+// so the suggestions stay cheap enough to be requested on every keystroke.
+func (a ArticleQueryRepository) GetArticleSuggestions(ctx context.Context, qry *article.ArticleSuggestQuery) (*article.ArticleSuggestionsDTO, error) {
+	var buf bytes.Buffer
+
+	if err := json.NewEncoder(&buf).Encode(searching.NewArticleSuggestRequest(qry)); err != nil {
+		return nil, err
+	}
+
+	req := esapi.SearchRequest{
+		Index: []string{article.IndexName},
+		Body:  &buf,
+	}
+
+	res, err := req.Do(ctx, a.elasticClient)
+	if err != nil {
+		return nil, elasticTransportError(ctx, err)
+	}
 	defer res.Body.Close()
 
 	if res.IsError() {
-		return nil, fmt.Errorf("elasticsearch error: %s", res.String())
+		return nil, elasticResponseError(res)
 	}
 
 	var docs struct {
 		Hits struct {
 			Hits []struct {
-				Article article.Article `json:"_source"`
+				Source article.TitleSuggestionDTO `json:"_source"`
 			} `json:"hits"`
 		} `json:"hits"`
+		Aggregations struct {
+			Authors struct {
+				Matching struct {
+					Names struct {
+						Buckets []struct {
+							Key string `json:"key"`
+						} `json:"buckets"`
+					} `json:"names"`
+				} `json:"matching"`
+			} `json:"authors"`
+		} `json:"aggregations"`
 	}
-
 	if err := json.NewDecoder(res.Body).Decode(&docs); err != nil {
 		return nil, err
 	}
 
-	articles := make([]article.Article, len(docs.Hits.Hits))
+	result := &article.ArticleSuggestionsDTO{
+		Titles:  make([]article.TitleSuggestionDTO, len(docs.Hits.Hits)),
+		Authors: make([]string, len(docs.Aggregations.Authors.Matching.Names.Buckets)),
+	}
 	for i, hit := range docs.Hits.Hits {
-		articles[i] = hit.Article
+		result.Titles[i] = hit.Source
+	}
+	for i, bucket := range docs.Aggregations.Authors.Matching.Names.Buckets {
+		result.Authors[i] = bucket.Key
 	}
 
-	return &article.ListArticleDTO{
-		Articles: articles,
-		Page:     qry.GetPage(),
+	return result, nil
+}
+
+// GetRelatedArticles returns the articles most similar to the source article, without their body.
+func (a ArticleQueryRepository) GetRelatedArticles(ctx context.Context, qry *article.ArticleRelatedQuery, source *article.Article) (*article.RelatedArticlesDTO, error) {
+	var buf bytes.Buffer
+
+	if err := json.NewEncoder(&buf).Encode(searching.NewArticleRelatedRequest(qry, source)); err != nil {
+		return nil, err
+	}
+
+	req := esapi.SearchRequest{
+		Index: []string{article.IndexName},
+		Body:  &buf,
+	}
+
+	docs, err := a.search(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return &article.RelatedArticlesDTO{Articles: docs.articles()}, nil
+}
+
+// getListArticlesByCursor retrieves a page of articles after the position of the query cursor.
+//
+// The pages are read with search_after over (created, id) from a point-in-time
+// snapshot of the index, opened for the first page and carried in the cursor,
+// so articles written during the traversal do not shift the pages.
+// The point in time is closed once the last page is read.
+func (a ArticleQueryRepository) getListArticlesByCursor(ctx context.Context, qry *article.ArticleQuery, query *search.Request) (*article.ListArticleDTO, error) {
+	cursor := &articleCursor{}
+	if qry.Cursor != "" {
+		var err error
+		cursor, err = decodeArticleCursor(qry.Cursor)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		pit, err := a.openPointInTime(ctx)
+		if err != nil {
+			return nil, err
+		}
+		cursor.PIT = pit
+	}
+
+	query.Pit = &types.PointInTimeReference{
+		Id:        cursor.PIT,
+		KeepAlive: articleCursorKeepAlive,
+	}
+	for _, value := range cursor.After {
+		query.SearchAfter = append(query.SearchAfter, value)
+	}
+
+	var buf bytes.Buffer
+
+	if err := json.NewEncoder(&buf).Encode(query); err != nil {
+		return nil, err
+	}
+
+	// a search over a point in time must not name the index
+	req := esapi.SearchRequest{
+		Body: &buf,
+	}
+
+	docs, err := a.search(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &article.ListArticleDTO{
+		Articles: docs.articles(),
 		Limit:    qry.GetLimit(),
-	}, nil
+		Total:    docs.Hits.Total.Value,
+		Facets:   docs.facets(),
+	}
+
+	if len(docs.Hits.Hits) < qry.GetLimit() {
+		if err := a.closePointInTime(ctx, docs.PitID); err != nil {
+			log.Printf("failed to close point in time: %v", err)
+		}
+		return result, nil
+	}
+
+	next := &articleCursor{
+		PIT:   docs.PitID,
+		After: docs.Hits.Hits[len(docs.Hits.Hits)-1].Sort,
+	}
+	result.NextCursor, err = next.encode()
+	if err != nil {
+		return nil, err
+	}
+	result.HasNext = true
+
+	return result, nil
+}
+
+type articleSearchResponse struct {
+	PitID string `json:"pit_id"`
+	Hits  struct {
+		Total struct {
+			Value int64 `json:"value"`
+		} `json:"total"`
+		Hits []struct {
+			Article   article.Article     `json:"_source"`
+			Highlight map[string][]string `json:"highlight"`
+			Sort      []json.RawMessage   `json:"sort"`
+		} `json:"hits"`
+	} `json:"hits"`
+	Aggregations map[string]struct {
+		Buckets []struct {
+			Key         interface{} `json:"key"`
+			KeyAsString string      `json:"key_as_string"`
+			DocCount    int64       `json:"doc_count"`
+		} `json:"buckets"`
+	} `json:"aggregations"`
+}
+
+// facets returns the facets of the aggregations, nil if none were requested.
+func (r *articleSearchResponse) facets() *article.ArticleFacetsDTO {
+	if len(r.Aggregations) == 0 {
+		return nil
+	}
+
+	buckets := func(name string) []article.FacetBucketDTO {
+		agg, ok := r.Aggregations[name]
+		if !ok {
+			return nil
+		}
+
+		result := make([]article.FacetBucketDTO, len(agg.Buckets))
+		for i, bucket := range agg.Buckets {
+			key := bucket.KeyAsString
+			if key == "" {
+				key = fmt.Sprint(bucket.Key)
+			}
+			result[i] = article.FacetBucketDTO{Key: key, Count: bucket.DocCount}
+		}
+		return result
+	}
+
+	return &article.ArticleFacetsDTO{
+		Authors: buckets("authors"),
+		Created: buckets("created"),
+		Tags:    buckets("tags"),
+	}
+}
+
+// articles returns the articles of the hits.
+func (r *articleSearchResponse) articles() []article.Article {
+	articles := make([]article.Article, len(r.Hits.Hits))
+	for i, hit := range r.Hits.Hits {
+		articles[i] = hit.Article
+		articles[i].Highlight = hit.Highlight
+	}
+	return articles
+}
+
+// search runs the search request and decodes its response.
+//
+// article.ErrCursorExpired is returned if the point in time of the search no longer exists.
+func (a ArticleQueryRepository) search(ctx context.Context, req esapi.SearchRequest) (*articleSearchResponse, error) {
+	res, err := req.Do(ctx, a.elasticClient)
+	if err != nil {
+		return nil, elasticTransportError(ctx, err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusNotFound && req.Index == nil {
+		return nil, article.ErrCursorExpired
+	}
+
+	if res.IsError() {
+		return nil, elasticResponseError(res)
+	}
+
+	var docs articleSearchResponse
+	if err := json.NewDecoder(res.Body).Decode(&docs); err != nil {
+		return nil, err
+	}
+
+	return &docs, nil
+}
+
+// openPointInTime opens a point in time over the article index and returns its ID.
+func (a ArticleQueryRepository) openPointInTime(ctx context.Context) (string, error) {
+	req := esapi.OpenPointInTimeRequest{
+		Index:     []string{article.IndexName},
+		KeepAlive: articleCursorKeepAlive,
+	}
+
+	res, err := req.Do(ctx, a.elasticClient)
+	if err != nil {
+		return "", elasticTransportError(ctx, err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return "", elasticResponseError(res)
+	}
+
+	var pit struct {
+		ID string `json:"id"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&pit); err != nil {
+		return "", err
+	}
+
+	return pit.ID, nil
+}
+
+// closePointInTime releases the point in time with the given ID.
+func (a ArticleQueryRepository) closePointInTime(ctx context.Context, id string) error {
+	if id == "" {
+		return nil
+	}
+
+	body, err := json.Marshal(map[string]string{"id": id})
+	if err != nil {
+		return err
+	}
+
+	req := esapi.ClosePointInTimeRequest{
+		Body: bytes.NewReader(body),
+	}
+
+	res, err := req.Do(ctx, a.elasticClient)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	// the point in time may have expired already
+	if res.IsError() && res.StatusCode != http.StatusNotFound {
+		return fmt.Errorf("elasticsearch error: %s", res.String())
+	}
+
+	return nil
+}
+
+// elasticTransportError returns the error of a request that did not reach Elasticsearch.
+//
+// It wraps searching.ErrElasticUnavailable unless the request was cancelled by the caller.
+func elasticTransportError(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return err
+	}
+	return fmt.Errorf("%w: %v", searching.ErrElasticUnavailable, err)
+}
+
+// elasticResponseError returns the error of a failed Elasticsearch response.
+//
+// Server errors wrap searching.ErrElasticUnavailable, the request may succeed later
+// or on another backend, client errors are returned as is.
+func elasticResponseError(res *esapi.Response) error {
+	if res.StatusCode >= http.StatusInternalServerError {
+		return fmt.Errorf("%w: %s", searching.ErrElasticUnavailable, res.String())
+	}
+	return fmt.Errorf("elasticsearch error: %s", res.String())
 }
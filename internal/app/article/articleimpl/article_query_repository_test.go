@@ -1,6 +1,7 @@
 package articleimpl_test
 
 import (
+	"context"
 	"io/ioutil"
 	"net/http"
 	"strings"
@@ -9,6 +10,7 @@ import (
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/elastic/go-elasticsearch/v8"
 	"github.com/stretchr/testify/assert"
+	"github.com/undercode99/article_service/internal/app/article"
 	"github.com/undercode99/article_service/internal/app/article/articleimpl"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
@@ -53,6 +55,29 @@ func elasticMockConnection() (*elasticsearch.TypedClient, MockTransport) {
 	return client, mocktrans
 }
 
+// elasticHandlerConnection returns an Elasticsearch client whose requests are answered by the given handler.
+//
+// The handler returns the status code and the JSON body of the response.
+func elasticHandlerConnection(handler func(req *http.Request) (int, string)) *elasticsearch.TypedClient {
+	mocktrans := MockTransport{}
+	mocktrans.RoundTripFn = func(req *http.Request) (*http.Response, error) {
+		status, body := handler(req)
+		return &http.Response{
+			StatusCode: status,
+			Body:       ioutil.NopCloser(strings.NewReader(body)),
+			Header:     http.Header{"X-Elastic-Product": []string{"Elasticsearch"}, "Content-Type": []string{"application/json"}},
+		}, nil
+	}
+
+	client, err := elasticsearch.NewTypedClient(elasticsearch.Config{
+		Transport: &mocktrans,
+	})
+	if err != nil {
+		panic(err)
+	}
+	return client
+}
+
 // TestGetArticleByID tests the GetArticleByID function.
 //
 // It checks if the article with the given ID exists in the database
@@ -69,7 +94,9 @@ func TestGetArticleByID(t *testing.T) {
 	repo := articleimpl.NewArticleQueryRepository(db, client)
 
 	t.Run("Test exists article", func(t *testing.T) {
-		mock.ExpectQuery("SELECT (.+) FROM \"articles\" WHERE \"articles\".\"id\" = ?").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "title"}).AddRow(1, "Test Article"))
+		mock.ExpectQuery("SELECT (.+) FROM \"articles\" WHERE \"articles\".\"id\" = ?").WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "title", "category_id"}).AddRow(1, "Test Article", 2))
+		expectTagsLoad(mock, sqlmock.NewRows([]string{"article_id", "name"}).AddRow(1, "golang"), 1)
+		mock.ExpectQuery("SELECT \"id\",\"path\" FROM \"categories\" WHERE id IN (.+)").WithArgs(2).WillReturnRows(sqlmock.NewRows([]string{"id", "path"}).AddRow(2, "tech/golang"))
 
 		res, err := repo.GetArticleByID(1)
 		if err != nil {
@@ -78,10 +105,294 @@ func TestGetArticleByID(t *testing.T) {
 
 		assert.Equal(t, 1, res.ID)
 		assert.Equal(t, "Test Article", res.Title)
+		assert.Equal(t, []string{"golang"}, res.Tags)
+		assert.Equal(t, "tech/golang", res.Category)
 	})
 
 }
 
+// TestGetListArticlesElastic tests the GetListArticles function.
+//
+// It checks that the total hits are requested and returned with the pagination metadata.
 func TestGetListArticlesElastic(t *testing.T) {
-	// TODO: Implement test cases for GetListArticlesElastic function
+	var searchBody string
+	client := elasticHandlerConnection(func(req *http.Request) (int, string) {
+		body, _ := ioutil.ReadAll(req.Body)
+		searchBody = string(body)
+		return http.StatusOK, `{"hits":{"total":{"value":21,"relation":"eq"},"hits":[
+			{"_source":{"id":1,"title":"First","body":"First body","author":"John Doe"}}]}}`
+	})
+
+	db, _ := dbMockConnection()
+	repo := articleimpl.NewArticleQueryRepository(db, client)
+	result, err := repo.GetListArticles(context.Background(), &article.ArticleQuery{Page: 2, Limit: 10})
+
+	assert.NoError(t, err)
+	assert.Contains(t, searchBody, `"track_total_hits":true`)
+	assert.Len(t, result.Articles, 1)
+	assert.Equal(t, int64(21), result.Total)
+	assert.Equal(t, 3, result.TotalPages)
+	assert.True(t, result.HasNext)
+}
+
+// TestGetListArticlesElastic_Highlight tests the highlight and summary options of the GetListArticles function.
+//
+// It checks that the highlight and source filtering are requested and the fragments returned with the articles.
+func TestGetListArticlesElastic_Highlight(t *testing.T) {
+	var searchBody string
+	client := elasticHandlerConnection(func(req *http.Request) (int, string) {
+		body, _ := ioutil.ReadAll(req.Body)
+		searchBody = string(body)
+		return http.StatusOK, `{"hits":{"total":{"value":1},"hits":[
+			{"_source":{"id":1,"title":"Learning golang","author":"John Doe"},
+			 "highlight":{"title":["Learning <mark>golang</mark>"],"body":["... with <mark>golang</mark> ..."]}}]}}`
+	})
+
+	db, _ := dbMockConnection()
+	repo := articleimpl.NewArticleQueryRepository(db, client)
+	result, err := repo.GetListArticles(context.Background(), &article.ArticleQuery{
+		Search:       "golang",
+		Highlight:    true,
+		FragmentSize: 80,
+		PreTag:       "<mark>",
+		PostTag:      "</mark>",
+		View:         article.ArticleViewSummary,
+	})
+
+	assert.NoError(t, err)
+	assert.Contains(t, searchBody, `"_source":{"excludes":["body"]}`)
+	assert.Contains(t, searchBody, `"fragment_size":80`)
+	assert.Contains(t, searchBody, `"pre_tags":["\u003cmark\u003e"]`)
+	assert.Contains(t, searchBody, `"post_tags":["\u003c/mark\u003e"]`)
+	assert.Len(t, result.Articles, 1)
+	assert.Empty(t, result.Articles[0].Body)
+	assert.Equal(t, []string{"Learning <mark>golang</mark>"}, result.Articles[0].Highlight["title"])
+	assert.Equal(t, []string{"... with <mark>golang</mark> ..."}, result.Articles[0].Highlight["body"])
+}
+
+// TestGetListArticlesElastic_Facets tests the facets of the GetListArticles function.
+//
+// It checks that the author, created and tag aggregations are requested along the author filter
+// and that their buckets are returned as facets.
+func TestGetListArticlesElastic_Facets(t *testing.T) {
+	var searchBody string
+	client := elasticHandlerConnection(func(req *http.Request) (int, string) {
+		body, _ := ioutil.ReadAll(req.Body)
+		searchBody = string(body)
+		return http.StatusOK, `{"hits":{"total":{"value":3},"hits":[]},"aggregations":{
+			"authors":{"buckets":[{"key":"John Doe","doc_count":3}]},
+			"created":{"buckets":[{"key_as_string":"2023-06","key":1685577600000,"doc_count":1},{"key_as_string":"2023-07","key":1688169600000,"doc_count":2}]},
+			"tags":{"buckets":[{"key":"golang","doc_count":2}]}}}`
+	})
+
+	db, _ := dbMockConnection()
+	repo := articleimpl.NewArticleQueryRepository(db, client)
+	result, err := repo.GetListArticles(context.Background(), &article.ArticleQuery{
+		Author:          "John Doe",
+		AuthorFacet:     true,
+		AuthorFacetSize: 5,
+		CreatedFacet:    article.FacetIntervalMonth,
+		TagFacet:        true,
+	})
+
+	assert.NoError(t, err)
+	assert.Contains(t, searchBody, `"filter":[`+publishedFilter+`,{"terms":{"author":["John Doe"]}}]`)
+	assert.Contains(t, searchBody, `"tags":{"terms":{"field":"tags","size":10}}`)
+	assert.Contains(t, searchBody, `"authors":{"terms":{"field":"author","size":5}}`)
+	assert.Contains(t, searchBody, `"created":{"date_histogram":{"calendar_interval":"month","field":"created","format":"yyyy-MM","min_doc_count":1}}`)
+	assert.Equal(t, []article.FacetBucketDTO{{Key: "John Doe", Count: 3}}, result.Facets.Authors)
+	assert.Equal(t, []article.FacetBucketDTO{{Key: "2023-06", Count: 1}, {Key: "2023-07", Count: 2}}, result.Facets.Created)
+	assert.Equal(t, []article.FacetBucketDTO{{Key: "golang", Count: 2}}, result.Facets.Tags)
+}
+
+// publishedFilter is the filter of the published articles, including the documents indexed without a status.
+const publishedFilter = `{"bool":{"minimum_should_match":1,"should":[{"term":{"status":{"value":"published"}}},{"bool":{"must_not":[{"exists":{"field":"status"}}]}}]}}`
+
+// TestGetListArticlesElastic_Filters tests the filters of the GetListArticles function.
+//
+// It checks that the search terms restrict the articles according to the match mode,
+// and that the authors, excluded authors and created range are sent as filters.
+func TestGetListArticlesElastic_Filters(t *testing.T) {
+	tests := []struct {
+		name      string
+		matchMode string
+		search    string
+	}{
+		{"Any term", "", `{"multi_match":{"fields":["title","body"],"operator":"or","query":"golang generics","type":"best_fields"}}`},
+		{"All terms", article.MatchModeAll, `{"multi_match":{"fields":["title","body"],"operator":"and","query":"golang generics","type":"cross_fields"}}`},
+		{"Phrase", article.MatchModePhrase, `{"multi_match":{"fields":["title","body"],"query":"golang generics","type":"phrase"}}`},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			var searchBody string
+			client := elasticHandlerConnection(func(req *http.Request) (int, string) {
+				body, _ := ioutil.ReadAll(req.Body)
+				searchBody = string(body)
+				return http.StatusOK, `{"hits":{"total":{"value":0},"hits":[]}}`
+			})
+
+			db, _ := dbMockConnection()
+			repo := articleimpl.NewArticleQueryRepository(db, client)
+			_, err := repo.GetListArticles(context.Background(), &article.ArticleQuery{
+				Search:         "golang generics",
+				MatchMode:      tt.matchMode,
+				Authors:        []string{"John Doe", "Jane Doe"},
+				ExcludeAuthors: []string{"Jim Doe"},
+				CreatedFrom:    "2023-07-01",
+				CreatedTo:      "2023-09-30",
+			})
+
+			assert.NoError(t, err)
+			assert.Contains(t, searchBody, `"must":[`+tt.search+`]`)
+			assert.Contains(t, searchBody, `"filter":[`+publishedFilter+`,{"terms":{"author":["John Doe","Jane Doe"]}},{"range":{"created":{"gte":"2023-07-01T00:00:00Z","lt":"2023-10-01T00:00:00Z"}}}]`)
+			assert.Contains(t, searchBody, `"must_not":[{"terms":{"author":["Jim Doe"]}}]`)
+		})
+	}
+}
+
+// TestGetListArticlesElastic_Cursor tests the cursor pagination of the GetListArticles function.
+//
+// 1. Test the first page opens a point in time and returns a cursor after its last article.
+// 2. Test the next page searches after the cursor and closes the point in time once it is the last page.
+// 3. Test an invalid cursor is rejected.
+// 4. Test an expired point in time is reported.
+func TestGetListArticlesElastic_Cursor(t *testing.T) {
+	ctx := context.Background()
+	db, _ := dbMockConnection()
+
+	var nextCursor string
+
+	t.Run("First page", func(t *testing.T) {
+		var searchBody string
+		client := elasticHandlerConnection(func(req *http.Request) (int, string) {
+			switch {
+			case req.Method == http.MethodPost && req.URL.Path == "/articles/_pit":
+				return http.StatusOK, `{"id":"pit-1"}`
+			case req.Method == http.MethodPost && req.URL.Path == "/_search":
+				body, _ := ioutil.ReadAll(req.Body)
+				searchBody = string(body)
+				return http.StatusOK, `{"pit_id":"pit-2","hits":{"total":{"value":3},"hits":[
+					{"_source":{"id":1,"title":"First"},"sort":[1690000000000,1]},
+					{"_source":{"id":2,"title":"Second"},"sort":[1690000000000,2]}]}}`
+			}
+			t.Errorf("unexpected request %s %s", req.Method, req.URL.Path)
+			return http.StatusNotFound, `{}`
+		})
+
+		repo := articleimpl.NewArticleQueryRepository(db, client)
+		result, err := repo.GetListArticles(ctx, &article.ArticleQuery{Limit: 2, UseCursor: true})
+
+		assert.NoError(t, err)
+		assert.Contains(t, searchBody, `"pit":{"id":"pit-1","keep_alive":"5m"}`)
+		assert.Contains(t, searchBody, `"sort":[{"created":{"order":"asc"}},{"id":{"order":"asc"}}]`)
+		assert.NotContains(t, searchBody, `"from"`)
+		assert.NotContains(t, searchBody, `"search_after"`)
+		assert.Len(t, result.Articles, 2)
+		assert.Equal(t, int64(3), result.Total)
+		assert.True(t, result.HasNext)
+		assert.NotEmpty(t, result.NextCursor)
+		nextCursor = result.NextCursor
+	})
+
+	t.Run("Last page", func(t *testing.T) {
+		var searchBody, closeBody string
+		client := elasticHandlerConnection(func(req *http.Request) (int, string) {
+			switch {
+			case req.Method == http.MethodPost && req.URL.Path == "/_search":
+				body, _ := ioutil.ReadAll(req.Body)
+				searchBody = string(body)
+				return http.StatusOK, `{"pit_id":"pit-3","hits":{"total":{"value":3},"hits":[
+					{"_source":{"id":3,"title":"Third"},"sort":[1690000000001,3]}]}}`
+			case req.Method == http.MethodDelete && req.URL.Path == "/_pit":
+				body, _ := ioutil.ReadAll(req.Body)
+				closeBody = string(body)
+				return http.StatusOK, `{"succeeded":true}`
+			}
+			t.Errorf("unexpected request %s %s", req.Method, req.URL.Path)
+			return http.StatusNotFound, `{}`
+		})
+
+		repo := articleimpl.NewArticleQueryRepository(db, client)
+		result, err := repo.GetListArticles(ctx, &article.ArticleQuery{Limit: 2, Cursor: nextCursor, UseCursor: true})
+
+		assert.NoError(t, err)
+		assert.Contains(t, searchBody, `"pit":{"id":"pit-2","keep_alive":"5m"}`)
+		assert.Contains(t, searchBody, `"search_after":[1690000000000,2]`)
+		assert.JSONEq(t, `{"id":"pit-3"}`, closeBody)
+		assert.Len(t, result.Articles, 1)
+		assert.False(t, result.HasNext)
+		assert.Empty(t, result.NextCursor)
+	})
+
+	t.Run("Invalid cursor", func(t *testing.T) {
+		client, _ := elasticMockConnection()
+		repo := articleimpl.NewArticleQueryRepository(db, client)
+		_, err := repo.GetListArticles(ctx, &article.ArticleQuery{Cursor: "not a cursor", UseCursor: true})
+
+		assert.Equal(t, article.ErrInvalidCursor, err)
+	})
+
+	t.Run("Expired cursor", func(t *testing.T) {
+		client := elasticHandlerConnection(func(req *http.Request) (int, string) {
+			return http.StatusNotFound, `{"error":{"type":"search_context_missing_exception"}}`
+		})
+		repo := articleimpl.NewArticleQueryRepository(db, client)
+		_, err := repo.GetListArticles(ctx, &article.ArticleQuery{Cursor: nextCursor, UseCursor: true})
+
+		assert.Equal(t, article.ErrCursorExpired, err)
+	})
+}
+
+// TestGetArticleSuggestions tests the GetArticleSuggestions function.
+//
+// It checks that the prefix is matched on the suggest subfields without reading the bodies
+// and that the matching titles and authors are returned.
+func TestGetArticleSuggestions(t *testing.T) {
+	var searchBody string
+	client := elasticHandlerConnection(func(req *http.Request) (int, string) {
+		body, _ := ioutil.ReadAll(req.Body)
+		searchBody = string(body)
+		return http.StatusOK, `{"hits":{"hits":[
+			{"_source":{"id":3,"title":"Golang generics"}},
+			{"_source":{"id":7,"title":"Golang testing"}}]},
+			"aggregations":{"authors":{"doc_count":12,"matching":{"doc_count":2,
+				"names":{"buckets":[{"key":"Gordon","doc_count":2}]}}}}}`
+	})
+
+	db, _ := dbMockConnection()
+	repo := articleimpl.NewArticleQueryRepository(db, client)
+	result, err := repo.GetArticleSuggestions(context.Background(), &article.ArticleSuggestQuery{Q: "go", Limit: 3})
+
+	assert.NoError(t, err)
+	assert.Contains(t, searchBody, `"_source":{"includes":["id","title","slug"]}`)
+	assert.Contains(t, searchBody, `"type":"bool_prefix"`)
+	assert.Contains(t, searchBody, `"size":3`)
+	assert.Equal(t, []article.TitleSuggestionDTO{{ID: 3, Title: "Golang generics"}, {ID: 7, Title: "Golang testing"}}, result.Titles)
+	assert.Equal(t, []string{"Gordon"}, result.Authors)
+}
+
+// TestGetRelatedArticlesElastic tests the GetRelatedArticles function.
+//
+// It checks that the articles like the source article are searched without their body.
+func TestGetRelatedArticlesElastic(t *testing.T) {
+	var searchBody string
+	client := elasticHandlerConnection(func(req *http.Request) (int, string) {
+		body, _ := ioutil.ReadAll(req.Body)
+		searchBody = string(body)
+		return http.StatusOK, `{"hits":{"total":{"value":1},"hits":[
+			{"_source":{"id":7,"title":"Golang testing","author":"John Doe"}}]}}`
+	})
+
+	db, _ := dbMockConnection()
+	repo := articleimpl.NewArticleQueryRepository(db, client)
+	result, err := repo.GetRelatedArticles(context.Background(),
+		&article.ArticleRelatedQuery{ID: 3}, &article.Article{ID: 3, Author: "John Doe"})
+
+	assert.NoError(t, err)
+	assert.Contains(t, searchBody, `"like":[{"_id":"3","_index":"articles"}]`)
+	assert.Contains(t, searchBody, `"_source":{"excludes":["body"]}`)
+	assert.Len(t, result.Articles, 1)
+	assert.Equal(t, 7, result.Articles[0].ID)
 }
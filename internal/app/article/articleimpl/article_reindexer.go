@@ -0,0 +1,244 @@
+package articleimpl
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/elastic/go-elasticsearch/v8"
+	"github.com/elastic/go-elasticsearch/v8/esapi"
+	"github.com/undercode99/article_service/config"
+	"github.com/undercode99/article_service/internal/app/article"
+	"github.com/undercode99/article_service/internal/searching"
+	"gorm.io/gorm"
+)
+
+type ArticleReindexer struct {
+	db            *gorm.DB
+	elasticClient *elasticsearch.TypedClient
+	cfg           *config.ArticleConfig
+}
+
+// NewArticleReindexer creates a new instance of the ArticleReindexer struct.
+//
+// It takes the database the articles are read from, the Elasticsearch client they are written with
+// and the application configuration, the new index is created with the configured analyzer.
+func NewArticleReindexer(db *gorm.DB, elasticClient *elasticsearch.TypedClient, cfg *config.Config) article.ArticleReindexer {
+	return &ArticleReindexer{
+		db:            db,
+		elasticClient: elasticClient,
+		cfg:           cfg.Article,
+	}
+}
+
+// Reindex rebuilds the article index from the database.
+//
+// Every live article is streamed from the database in batches and written with the
+// bulk API into a fresh versioned index created with the current mapping. Once it is complete, the article.IndexName
+// alias is swapped to the new index atomically. The changes written to the old index
+// while the new one was being built are replayed afterwards, and the articles purged
+// in the meantime are removed from the new index.
+//
+// ctx: the context of the reindex.
+// batchSize: the number of articles read and written per batch.
+// deleteOld: whether the indices the alias pointed at before are deleted.
+// Returns the result of the reindex and an error, if any.
+func (r *ArticleReindexer) Reindex(ctx context.Context, batchSize int, deleteOld bool) (*article.ReindexResultDTO, error) {
+	started := time.Now()
+
+	index, err := searching.CreateVersionedIndex(ctx, r.elasticClient, article.IndexName, NewArticleIndexMapping(r.cfg.SearchAnalyzer))
+	if err != nil {
+		return nil, err
+	}
+
+	// Stream every live article into the new index
+	var indexed []int
+	var batch []article.Article
+	err = r.db.WithContext(ctx).FindInBatches(&batch, batchSize, func(tx *gorm.DB, _ int) error {
+		if err := r.bulkArticles(ctx, index, batch); err != nil {
+			return err
+		}
+		for i := range batch {
+			indexed = append(indexed, batch[i].ID)
+		}
+		log.Printf("Reindexed %d articles into %s", len(indexed), index)
+		return nil
+	}).Error
+	if err != nil {
+		return nil, err
+	}
+
+	previous, err := searching.SwapAlias(ctx, r.elasticClient, article.IndexName, index)
+	if err != nil {
+		return nil, err
+	}
+
+	// Replay the changes made while the new index was being built, they only reached the old index
+	caughtUp := 0
+	err = r.db.WithContext(ctx).Unscoped().
+		Where("updated >= ? OR deleted_at >= ?", started, started).
+		FindInBatches(&batch, batchSize, func(tx *gorm.DB, _ int) error {
+			if err := r.bulkArticles(ctx, index, batch); err != nil {
+				return err
+			}
+			caughtUp += len(batch)
+			return nil
+		}).Error
+	if err != nil {
+		return nil, err
+	}
+
+	// The articles purged meanwhile are no longer in the database to be replayed
+	purged, err := r.deletePurgedArticles(ctx, index, indexed, batchSize)
+	if err != nil {
+		return nil, err
+	}
+
+	if deleteOld {
+		if err := searching.DeleteIndices(ctx, r.elasticClient, previous...); err != nil {
+			return nil, err
+		}
+	}
+
+	return &article.ReindexResultDTO{
+		Index:           index,
+		PreviousIndices: previous,
+		Indexed:         len(indexed),
+		CaughtUp:        caughtUp,
+		Purged:          purged,
+	}, nil
+}
+
+// deletePurgedArticles removes from the index the articles of the IDs that are no longer in the database.
+//
+// It returns the number of removed articles.
+func (r *ArticleReindexer) deletePurgedArticles(ctx context.Context, index string, ids []int, batchSize int) (int, error) {
+	purged := 0
+	for start := 0; start < len(ids); start += batchSize {
+		end := start + batchSize
+		if end > len(ids) {
+			end = len(ids)
+		}
+
+		var existing []int
+		err := r.db.WithContext(ctx).Unscoped().Model(&article.Article{}).Where("id IN ?", ids[start:end]).Pluck("id", &existing).Error
+		if err != nil {
+			return 0, err
+		}
+
+		exists := make(map[int]bool, len(existing))
+		for _, id := range existing {
+			exists[id] = true
+		}
+
+		var buf bytes.Buffer
+		enc := json.NewEncoder(&buf)
+		for _, id := range ids[start:end] {
+			if exists[id] {
+				continue
+			}
+			meta := map[string]interface{}{"_index": index, "_id": strconv.Itoa(id)}
+			if err := enc.Encode(map[string]interface{}{"delete": meta}); err != nil {
+				return 0, err
+			}
+			purged++
+		}
+
+		if buf.Len() > 0 {
+			if err := r.bulk(ctx, &buf); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	return purged, nil
+}
+
+// bulkArticles writes the given articles into the index with the bulk API.
+//
+// Live articles are indexed with their tags and category, soft-deleted articles are removed from the index.
+func (r *ArticleReindexer) bulkArticles(ctx context.Context, index string, items []article.Article) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	if err := loadArticleTaxonomy(r.db.WithContext(ctx), items); err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for i := range items {
+		meta := map[string]interface{}{
+			"_index": index,
+			"_id":    strconv.Itoa(items[i].ID),
+		}
+
+		if items[i].DeletedAt.Valid {
+			if err := enc.Encode(map[string]interface{}{"delete": meta}); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := enc.Encode(map[string]interface{}{"index": meta}); err != nil {
+			return err
+		}
+		if err := enc.Encode(items[i]); err != nil {
+			return err
+		}
+	}
+
+	return r.bulk(ctx, &buf)
+}
+
+// bulk sends the actions of the body with the bulk API, a deletion of a missing document is not an error.
+func (r *ArticleReindexer) bulk(ctx context.Context, body *bytes.Buffer) error {
+	req := esapi.BulkRequest{
+		Body: body,
+	}
+
+	res, err := req.Do(ctx, r.elasticClient)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return fmt.Errorf("elasticsearch error: %s", res.String())
+	}
+
+	var bulkRes struct {
+		Errors bool `json:"errors"`
+		Items  []map[string]struct {
+			ID     string          `json:"_id"`
+			Status int             `json:"status"`
+			Error  json.RawMessage `json:"error"`
+		} `json:"items"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&bulkRes); err != nil {
+		return err
+	}
+
+	if !bulkRes.Errors {
+		return nil
+	}
+
+	for _, item := range bulkRes.Items {
+		for action, result := range item {
+			// deleting a document that is not in the new index is expected
+			if action == "delete" && result.Status == 404 {
+				continue
+			}
+			if result.Status >= 300 {
+				return fmt.Errorf("elasticsearch bulk %s of article %s failed: %s", action, result.ID, result.Error)
+			}
+		}
+	}
+
+	return nil
+}
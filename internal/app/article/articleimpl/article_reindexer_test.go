@@ -0,0 +1,85 @@
+package articleimpl_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/undercode99/article_service/config"
+	"github.com/undercode99/article_service/internal/app/article/articleimpl"
+)
+
+// TestArticleReindexer_Reindex tests the Reindex function.
+//
+// It streams two articles from a mock database into a new versioned index,
+// checks they are written with the bulk API and that the alias is swapped
+// from the legacy index to the new one afterwards. The second article is purged
+// while the index is built and is removed from the new index.
+func TestArticleReindexer_Reindex(t *testing.T) {
+	db, mock := dbMockConnection()
+
+	var bulkBodies []string
+	var aliasesBody string
+	client := elasticHandlerConnection(func(req *http.Request) (int, string) {
+		switch {
+		case req.Method == http.MethodPut && strings.HasPrefix(req.URL.Path, "/articles_"):
+			return http.StatusOK, `{"acknowledged":true,"index":"articles_new"}`
+		case req.Method == http.MethodPost && req.URL.Path == "/_bulk":
+			body, _ := ioutil.ReadAll(req.Body)
+			bulkBodies = append(bulkBodies, string(body))
+			return http.StatusOK, `{"errors":false,"items":[]}`
+		case req.Method == http.MethodHead && req.URL.Path == "/_alias/articles":
+			return http.StatusNotFound, ``
+		case req.Method == http.MethodHead && req.URL.Path == "/articles":
+			return http.StatusOK, ``
+		case req.Method == http.MethodPost && req.URL.Path == "/_aliases":
+			body, _ := ioutil.ReadAll(req.Body)
+			aliasesBody = string(body)
+			return http.StatusOK, `{"acknowledged":true}`
+		}
+		t.Errorf("unexpected request %s %s", req.Method, req.URL.Path)
+		return http.StatusNotFound, `{}`
+	})
+
+	created := time.Now()
+	mock.ExpectQuery("SELECT (.+) FROM \"articles\" WHERE \"articles\".\"deleted_at\" IS NULL ORDER BY \"articles\".\"id\" LIMIT 100").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "body", "author", "created", "updated"}).
+			AddRow(1, "First", "First body", "John Doe", created, created).
+			AddRow(2, "Second", "Second body", "Jane Doe", created, created))
+	expectTagsLoad(mock, sqlmock.NewRows([]string{"article_id", "name"}).AddRow(2, "golang"), 1, 2)
+	mock.ExpectQuery("SELECT (.+) FROM \"articles\" WHERE updated >= (.+) OR deleted_at >= (.+) ORDER BY \"articles\".\"id\" LIMIT 100").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectQuery("SELECT \"id\" FROM \"articles\" WHERE id IN \\((.+)\\)$").
+		WithArgs(1, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+
+	reindexer := articleimpl.NewArticleReindexer(db, client, &config.Config{Article: &config.ArticleConfig{SearchAnalyzer: "english"}})
+	result, err := reindexer.Reindex(context.Background(), 100, false)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+	assert.True(t, strings.HasPrefix(result.Index, "articles_"))
+	assert.Equal(t, 2, result.Indexed)
+	assert.Equal(t, 0, result.CaughtUp)
+	assert.Equal(t, 1, result.Purged)
+
+	// both articles are written to the new index, not the alias
+	assert.Len(t, bulkBodies, 2)
+	bulkBody := bulkBodies[0]
+	assert.Equal(t, 2, strings.Count(bulkBody, `{"index":{"_id":`))
+	assert.Contains(t, bulkBody, `"_index":"`+result.Index+`"`)
+	assert.Contains(t, bulkBody, `"title":"First"`)
+	assert.Contains(t, bulkBody, `"title":"Second"`)
+	assert.Contains(t, bulkBody, `"tags":["golang"]`)
+	// then the purged article is deleted from it
+	assert.Equal(t, `{"delete":{"_id":"2","_index":"`+result.Index+`"}}`+"\n", bulkBodies[1])
+
+	// the legacy index is replaced by the alias in one request
+	assert.Contains(t, aliasesBody, `"remove_index":{"index":"articles"}`)
+	assert.Contains(t, aliasesBody, `"add":{"alias":"articles","index":"`+result.Index+`"}`)
+}
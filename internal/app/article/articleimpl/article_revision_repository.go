@@ -0,0 +1,45 @@
+package articleimpl
+
+import (
+	"github.com/undercode99/article_service/internal/app/article"
+	"gorm.io/gorm"
+)
+
+type ArticleRevisionRepository struct {
+	db *gorm.DB
+}
+
+func NewArticleRevisionRepository(db *gorm.DB) article.ArticleRevisionRepository {
+	return &ArticleRevisionRepository{
+		db: db,
+	}
+}
+
+// GetArticleRevisions returns the revisions of an article, the latest first.
+//
+// The bodies are left out, a revision is read whole with GetArticleRevision.
+func (r *ArticleRevisionRepository) GetArticleRevisions(articleID int) ([]article.ArticleRevision, error) {
+	var revisions []article.ArticleRevision
+	err := r.db.Select("article_id", "revision", "title", "editor", "note", "created").
+		Where("article_id = ?", articleID).
+		Order("revision DESC").
+		Find(&revisions).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return revisions, nil
+}
+
+// GetArticleRevision returns a revision of an article.
+//
+// gorm.ErrRecordNotFound is returned if the article has no such revision.
+func (r *ArticleRevisionRepository) GetArticleRevision(articleID, revision int) (*article.ArticleRevision, error) {
+	var item article.ArticleRevision
+	err := r.db.Where("article_id = ? AND revision = ?", articleID, revision).First(&item).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &item, nil
+}
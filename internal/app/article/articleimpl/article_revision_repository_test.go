@@ -0,0 +1,63 @@
+package articleimpl_test
+
+import (
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/undercode99/article_service/internal/app/article/articleimpl"
+	"gorm.io/gorm"
+)
+
+// TestArticleRevisionRepository_GetArticleRevisions tests the revisions are listed latest first without their bodies.
+func TestArticleRevisionRepository_GetArticleRevisions(t *testing.T) {
+	db, mock := dbMockConnection()
+	repo := articleimpl.NewArticleRevisionRepository(db)
+
+	mock.ExpectQuery("SELECT \"article_id\",\"revision\",\"title\",\"editor\",\"note\",\"created\" FROM \"article_revisions\" WHERE article_id = (.+) ORDER BY revision DESC").
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"article_id", "revision", "title", "editor"}).
+			AddRow(1, 2, "Second title", "jane").
+			AddRow(1, 1, "First title", "john"))
+
+	revisions, err := repo.GetArticleRevisions(1)
+	assert.NoError(t, err)
+	assert.Len(t, revisions, 2)
+	assert.Equal(t, 2, revisions[0].Revision)
+	assert.Equal(t, "jane", revisions[0].Editor)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestArticleRevisionRepository_GetArticleRevision tests the GetArticleRevision function.
+//
+// 1. Test a revision is read whole.
+// 2. Test the article has no such revision.
+func TestArticleRevisionRepository_GetArticleRevision(t *testing.T) {
+	t.Run("Revision found", func(t *testing.T) {
+		db, mock := dbMockConnection()
+		repo := articleimpl.NewArticleRevisionRepository(db)
+
+		mock.ExpectQuery("SELECT (.+) FROM \"article_revisions\" WHERE article_id = (.+) AND revision = (.+) ORDER BY (.+) LIMIT 1").
+			WithArgs(1, 2).
+			WillReturnRows(sqlmock.NewRows([]string{"article_id", "revision", "title", "body"}).
+				AddRow(1, 2, "Second title", "Second body"))
+
+		revision, err := repo.GetArticleRevision(1, 2)
+		assert.NoError(t, err)
+		assert.Equal(t, "Second body", revision.Body)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Revision not found", func(t *testing.T) {
+		db, mock := dbMockConnection()
+		repo := articleimpl.NewArticleRevisionRepository(db)
+
+		mock.ExpectQuery("SELECT (.+) FROM \"article_revisions\" WHERE article_id = (.+) AND revision = (.+) ORDER BY (.+) LIMIT 1").
+			WithArgs(1, 3).
+			WillReturnRows(sqlmock.NewRows([]string{"article_id", "revision"}))
+
+		_, err := repo.GetArticleRevision(1, 3)
+		assert.Equal(t, gorm.ErrRecordNotFound, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
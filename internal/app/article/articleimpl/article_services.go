@@ -2,17 +2,51 @@ package articleimpl
 
 import (
 	"context"
+	"fmt"
 	"log"
+	"strconv"
+	"time"
 
+	"golang.org/x/sync/singleflight"
 	"gorm.io/gorm"
 
 	"github.com/undercode99/article_service/internal/app/article"
 )
 
+// outboxFailuresLimit is the number of failing outbox records reported by GetOutboxStats.
+const outboxFailuresLimit = 20
+
+// cacheWriteTimeout bounds the writes to the cache made in the background of a request.
+const cacheWriteTimeout = 5 * time.Second
+
+// cacheInBackground runs a write to the cache once the request is answered.
+//
+// The write gets its own context rather than the one of the request, which is
+// canceled, and recycled by gin, as soon as the response is written.
+func cacheInBackground(what string, write func(ctx context.Context) error) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), cacheWriteTimeout)
+		defer cancel()
+
+		if err := write(ctx); err != nil {
+			log.Printf("failed to create cache for %s: %v", what, err)
+		}
+	}()
+}
+
 type ArticleService struct {
 	articleCommandRepository article.ArticleCommandRepository
 	articleQueryRepository   article.ArticleQueryRepository
 	articleCachingRepository article.ArticleCachingRepository
+	articleOutboxRepository  article.ArticleOutboxRepository
+
+	articleRevisionRepository article.ArticleRevisionRepository
+	articleSlugRepository     article.ArticleSlugRepository
+	articleTaxonomyRepository article.ArticleTaxonomyRepository
+
+	// articleLoads coalesces the concurrent database loads of the same article,
+	// so a burst of cache misses for one article reaches the database once.
+	articleLoads singleflight.Group
 }
 
 // NewArticleService creates a new instance of the ArticleService struct.
@@ -20,6 +54,11 @@ type ArticleService struct {
 // Parameters:
 // - articleCommandRepository: an instance of the ArticleCommandRepository interface.
 // - articleQueryRepository: an instance of the ArticleQueryRepository interface.
+// - articleCachingRepository: an instance of the ArticleCachingRepository interface.
+// - articleOutboxRepository: an instance of the ArticleOutboxRepository interface.
+// - articleRevisionRepository: an instance of the ArticleRevisionRepository interface.
+// - articleSlugRepository: an instance of the ArticleSlugRepository interface.
+// - articleTaxonomyRepository: an instance of the ArticleTaxonomyRepository interface.
 //
 // Returns:
 // - a pointer to the newly created ArticleService struct.
@@ -27,16 +66,26 @@ func NewArticleService(
 	articleCommandRepository article.ArticleCommandRepository,
 	articleQueryRepository article.ArticleQueryRepository,
 	articleCachingRepository article.ArticleCachingRepository,
+	articleOutboxRepository article.ArticleOutboxRepository,
+	articleRevisionRepository article.ArticleRevisionRepository,
+	articleSlugRepository article.ArticleSlugRepository,
+	articleTaxonomyRepository article.ArticleTaxonomyRepository,
 ) article.ArticleService {
 	return &ArticleService{
-		articleCommandRepository: articleCommandRepository,
-		articleQueryRepository:   articleQueryRepository,
-		articleCachingRepository: articleCachingRepository,
+		articleCommandRepository:  articleCommandRepository,
+		articleQueryRepository:    articleQueryRepository,
+		articleCachingRepository:  articleCachingRepository,
+		articleOutboxRepository:   articleOutboxRepository,
+		articleRevisionRepository: articleRevisionRepository,
+		articleSlugRepository:     articleSlugRepository,
+		articleTaxonomyRepository: articleTaxonomyRepository,
 	}
 }
 
 // CreateArticle creates a new article.
 // It takes an article create command as a parameter and returns the created article and any error encountered.
+//
+// The article is saved with its first revision and indexed in the background by the outbox dispatcher.
 func (s *ArticleService) CreateArticle(ctx context.Context, cmd *article.ArticleCreateCommand) (*article.Article, error) {
 	// Validate the article create command
 	if err := cmd.Validate(); err != nil {
@@ -47,26 +96,383 @@ func (s *ArticleService) CreateArticle(ctx context.Context, cmd *article.Article
 	createdArticle := article.NewArticle(cmd)
 
 	// Save the created article using the article command repository
-	err := s.articleCommandRepository.CreateArticle(createdArticle)
+	err := s.articleCommandRepository.CreateArticle(createdArticle, article.NewArticleRevision(createdArticle, cmd.Editor, cmd.Note))
 	if err != nil {
 		return nil, err
 	}
 
-	// Create the index for the article asynchronously
-	go func() {
-		err := s.articleCommandRepository.CreateIndexArticle(ctx, createdArticle)
-		if err != nil {
-			log.Printf("failed to create index for article: %v", err)
-		}
-	}()
+	// Evict the entry of a request for the ID before the article existed
+	err = s.articleCachingRepository.DeleteArticle(ctx, createdArticle.ID)
+	if err != nil {
+		log.Printf("failed to evict cache for article: %v", err)
+	}
+	s.evictListArticles(ctx, createdArticle.Author)
 
 	// Return the created article and no error
 	return createdArticle, nil
 }
 
+// UpdateArticle updates an existing article.
+// It takes an article update command as a parameter and returns the updated article and any error encountered.
+//
+// The change is saved in the database first, with a revision of the new content, then the cached
+// copy is evicted so readers never see the old content. The search index document is replaced
+// by the outbox dispatcher.
+// article.ErrArticleVersionMismatch is returned if the article is no longer at the version of the command,
+// article.ErrEditForbidden if the actor of the command may not change it and article.ErrAuthorForbidden
+// if they are not an editor and change its author.
+func (s *ArticleService) UpdateArticle(ctx context.Context, cmd *article.ArticleUpdateCommand) (*article.Article, error) {
+	// Validate the article update command
+	if err := cmd.Validate(); err != nil {
+		return nil, article.ErrArticleValidation
+	}
+
+	// Get the current article from the database
+	updatedArticle, err := s.getWritableArticle(cmd.Actor, cmd.ID, cmd.Version, cmd.Actor.CanEdit, article.ErrEditForbidden)
+	if err != nil {
+		return nil, err
+	}
+	if cmd.Author != updatedArticle.Author && !cmd.Actor.IsEditor() {
+		return nil, article.ErrAuthorForbidden
+	}
+
+	// Apply the changes and save them
+	previousAuthor := updatedArticle.Author
+	updatedArticle.Update(cmd)
+	err = s.saveArticle(ctx, updatedArticle, previousAuthor, article.NewArticleRevision(updatedArticle, cmd.Editor, cmd.Note))
+	if err != nil {
+		return nil, err
+	}
+
+	return updatedArticle, nil
+}
+
+// RevertArticle restores the title and body of an article to those of one of its revisions.
+// It takes an article revert command as a parameter and returns the reverted article and any error encountered.
+//
+// The revert is saved like an update, as a new revision, so it can be reverted in turn.
+// article.ErrRevisionNotFound is returned if the article has no such revision,
+// and article.ErrEditForbidden if the actor of the command may not change it.
+func (s *ArticleService) RevertArticle(ctx context.Context, cmd *article.ArticleRevertCommand) (*article.Article, error) {
+	revertedArticle, err := s.getWritableArticle(cmd.Actor, cmd.ID, cmd.Version, cmd.Actor.CanEdit, article.ErrEditForbidden)
+	if err != nil {
+		return nil, err
+	}
+
+	revision, err := s.getArticleRevision(cmd.ID, cmd.Revision)
+	if err != nil {
+		return nil, err
+	}
+
+	note := cmd.Note
+	if note == "" {
+		note = fmt.Sprintf("Reverted to revision %d", revision.Revision)
+	}
+
+	revertedArticle.Update(&article.ArticleUpdateCommand{
+		Author:     revertedArticle.Author,
+		Title:      revision.Title,
+		Body:       revision.Body,
+		Tags:       revertedArticle.Tags,
+		CategoryID: revertedArticle.CategoryID,
+	})
+	err = s.saveArticle(ctx, revertedArticle, revertedArticle.Author, article.NewArticleRevision(revertedArticle, cmd.Editor, note))
+	if err != nil {
+		return nil, err
+	}
+
+	return revertedArticle, nil
+}
+
+// TransitionArticle moves an article through a transition of its lifecycle.
+// It takes an article transition command as a parameter and returns the article and any error encountered.
+//
+// The transition is saved like an update, as a new revision of the unchanged title and body
+// noting the transition, so the history shows who moved the article and when.
+// article.ErrArticleNotFound is returned if the actor of the command may not read the article,
+// article.ErrTransitionForbidden if they may not perform the transition, and
+// article.ErrInvalidTransition if the article is not in a status it starts from.
+func (s *ArticleService) TransitionArticle(ctx context.Context, cmd *article.ArticleTransitionCommand) (*article.Article, error) {
+	if err := cmd.Validate(); err != nil {
+		return nil, err
+	}
+
+	canTransition := func(item *article.Article) bool { return cmd.Actor.CanTransition(item, cmd.Transition) }
+	transitionedArticle, err := s.getWritableArticle(cmd.Actor, cmd.ID, cmd.Version, canTransition, article.ErrTransitionForbidden)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := transitionedArticle.Transition(cmd); err != nil {
+		return nil, err
+	}
+
+	note := cmd.Note
+	if note == "" {
+		note = article.ArticleTransitions[cmd.Transition].Note
+		if transitionedArticle.IsScheduled() {
+			note = fmt.Sprintf("Scheduled for publication at %s", transitionedArticle.PublishAt.Format(time.RFC3339))
+		}
+	}
+
+	err = s.saveArticle(ctx, transitionedArticle, transitionedArticle.Author, article.NewArticleRevision(transitionedArticle, cmd.Editor, note))
+	if err != nil {
+		return nil, err
+	}
+
+	return transitionedArticle, nil
+}
+
+// getArticleVersion returns the article from the database if it is at the version, 0 matches any version.
+func (s *ArticleService) getArticleVersion(id, version int) (*article.Article, error) {
+	item, err := s.articleQueryRepository.GetArticleByID(id)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, article.ErrArticleNotFound
+		}
+		return nil, err
+	}
+
+	if version != 0 && version != item.Version {
+		return nil, article.ErrArticleVersionMismatch
+	}
+	return item, nil
+}
+
+// getWritableArticle returns the article from the database if the actor may write it and it is at the version.
+//
+// article.ErrArticleNotFound is returned if the actor may not read the article, so its existence
+// is not disclosed, and the forbidden error if they may read it but not write it.
+func (s *ArticleService) getWritableArticle(actor article.Actor, id, version int, canWrite func(*article.Article) bool, forbidden error) (*article.Article, error) {
+	item, err := s.getArticleVersion(id, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := authorizeArticle(actor, item, canWrite, forbidden); err != nil {
+		return nil, err
+	}
+	if version != 0 && version != item.Version {
+		return nil, article.ErrArticleVersionMismatch
+	}
+	return item, nil
+}
+
+// getDeletedWritableArticle returns the soft-deleted article from the database if the actor may write it,
+// with the errors of getWritableArticle.
+func (s *ArticleService) getDeletedWritableArticle(actor article.Actor, id int, canWrite func(*article.Article) bool, forbidden error) (*article.Article, error) {
+	item, err := s.articleQueryRepository.GetDeletedArticleByID(id)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, article.ErrArticleNotFound
+		}
+		return nil, err
+	}
+
+	if err := authorizeArticle(actor, item, canWrite, forbidden); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// authorizeArticle returns article.ErrArticleNotFound if the actor may not read the article,
+// and the forbidden error if they may read it but not write it.
+func authorizeArticle(actor article.Actor, item *article.Article, canWrite func(*article.Article) bool, forbidden error) error {
+	if !actor.CanView(item) {
+		return article.ErrArticleNotFound
+	}
+	if !canWrite(item) {
+		return forbidden
+	}
+	return nil
+}
+
+// saveArticle saves the changes of an article with their revision, it fails if the article
+// is updated in between, and evicts the cached copy and the lists it may appear in.
+//
+// The changes are committed by then, so a failed eviction is only logged: the outbox
+// dispatcher evicts the cached copy again once it re-indexed the article.
+func (s *ArticleService) saveArticle(ctx context.Context, item *article.Article, previousAuthor string, revision *article.ArticleRevision) error {
+	err := s.articleCommandRepository.UpdateArticle(item, revision)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return article.ErrArticleNotFound
+		}
+		return err
+	}
+
+	// Evict the cached article so the next read loads the new content
+	err = s.articleCachingRepository.DeleteArticle(ctx, item.ID)
+	if err != nil {
+		log.Printf("failed to evict cache for article: %v", err)
+	}
+	// The lists of both authors are evicted if the article changed hands
+	authors := []string{item.Author}
+	if previousAuthor != item.Author {
+		authors = append(authors, previousAuthor)
+	}
+	s.evictListArticles(ctx, authors...)
+
+	return nil
+}
+
+// DeleteArticle soft-deletes an article.
+// It takes an article delete command as a parameter and returns any error encountered.
+//
+// The deleted article is evicted from the cache and removed from the search index by the
+// outbox dispatcher, it stays recoverable in the database until it is purged.
+// A failed eviction is only logged, the outbox dispatcher evicts the article again.
+// article.ErrArticleVersionMismatch is returned if the article is no longer at the version of the command,
+// and article.ErrDeleteForbidden if the actor of the command may not delete it.
+func (s *ArticleService) DeleteArticle(ctx context.Context, cmd *article.ArticleDeleteCommand) error {
+	id := cmd.ID
+	_, err := s.getWritableArticle(cmd.Actor, id, cmd.Version, cmd.Actor.CanDelete, article.ErrDeleteForbidden)
+	if err != nil {
+		return err
+	}
+
+	err = s.articleCommandRepository.DeleteArticle(id, cmd.Version)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return article.ErrArticleNotFound
+		}
+		return err
+	}
+
+	// Evict the cached article so it is no longer served by ID
+	err = s.articleCachingRepository.DeleteArticle(ctx, id)
+	if err != nil {
+		log.Printf("failed to evict cache for article: %v", err)
+	}
+	// The author may change until the article is deleted, every list is evicted
+	s.evictListArticles(ctx)
+
+	return nil
+}
+
+// RestoreArticle restores a soft-deleted article.
+// It takes an article restore command as a parameter and returns the restored article and any error encountered.
+// The article is put back into the search index by the outbox dispatcher.
+//
+// The editors and the author of the article may restore it, article.ErrArticleNotFound is returned
+// if the actor of the command may not read it and article.ErrRestoreForbidden if they may not restore it.
+func (s *ArticleService) RestoreArticle(ctx context.Context, cmd *article.ArticleRestoreCommand) (*article.Article, error) {
+	id := cmd.ID
+	_, err := s.getDeletedWritableArticle(cmd.Actor, id, cmd.Actor.CanDelete, article.ErrRestoreForbidden)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.articleCommandRepository.RestoreArticle(id)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, article.ErrArticleNotFound
+		}
+		return nil, err
+	}
+
+	// Evict the entry of a request for the article while it was deleted
+	err = s.articleCachingRepository.DeleteArticle(ctx, id)
+	if err != nil {
+		log.Printf("failed to evict cache for article: %v", err)
+	}
+
+	// Get the restored article from the database
+	restoredArticle, err := s.articleQueryRepository.GetArticleByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	s.evictListArticles(ctx, restoredArticle.Author)
+	return restoredArticle, nil
+}
+
+// evictListArticles evicts the cached lists a change to an article of the authors may affect,
+// or every cached list if no author is given.
+//
+// A failed eviction is only logged, the lists expire shortly anyway.
+func (s *ArticleService) evictListArticles(ctx context.Context, authors ...string) {
+	err := s.articleCachingRepository.DeleteListArticles(ctx, authors...)
+	if err != nil {
+		log.Printf("failed to evict cache for article lists: %v", err)
+	}
+}
+
+// PurgeArticle permanently removes a soft-deleted article.
+// It takes an article purge command as a parameter and returns any error encountered.
+//
+// Only the editors may purge an article, article.ErrArticleNotFound is returned if the actor
+// of the command may not read it and article.ErrPurgeForbidden if they may read it.
+func (s *ArticleService) PurgeArticle(ctx context.Context, cmd *article.ArticlePurgeCommand) error {
+	isEditor := func(*article.Article) bool { return cmd.Actor.IsEditor() }
+	_, err := s.getDeletedWritableArticle(cmd.Actor, cmd.ID, isEditor, article.ErrPurgeForbidden)
+	if err != nil {
+		return err
+	}
+
+	err = s.articleCommandRepository.PurgeArticle(cmd.ID)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return article.ErrArticleNotFound
+		}
+		return err
+	}
+
+	return nil
+}
+
+// PurgeDeletedArticles permanently removes every article deleted longer than the retention ago.
+// It returns the number of purged articles and any error encountered.
+func (s *ArticleService) PurgeDeletedArticles(ctx context.Context, retention time.Duration) (int, error) {
+	ids, err := s.articleCommandRepository.PurgeDeletedArticles(time.Now().Add(-retention))
+	if err != nil {
+		return 0, err
+	}
+
+	return len(ids), nil
+}
+
+// PublishScheduledArticles publishes every article whose scheduled publish time has come.
+// It returns the number of published articles and any error encountered.
+//
+// The cached copies of the published articles and every cached list are evicted.
+func (s *ArticleService) PublishScheduledArticles(ctx context.Context) (int, error) {
+	ids, err := s.articleCommandRepository.PublishScheduledArticles(time.Now())
+	if err != nil {
+		return 0, err
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	for _, id := range ids {
+		if err := s.articleCachingRepository.DeleteArticle(ctx, id); err != nil {
+			log.Printf("failed to evict cache for article: %v", err)
+		}
+	}
+	// The authors of the published articles are not loaded, every list is evicted
+	s.evictListArticles(ctx)
+
+	return len(ids), nil
+}
+
+// GetOutboxStats returns the depth of the indexing outbox and its most recent failures.
+func (s *ArticleService) GetOutboxStats(ctx context.Context) (*article.ArticleOutboxStatsDTO, error) {
+	return s.articleOutboxRepository.GetOutboxStats(outboxFailuresLimit)
+}
+
+// RetryOutbox moves a dead-lettered outbox record back to the queue.
+func (s *ArticleService) RetryOutbox(ctx context.Context, id int) error {
+	return s.articleOutboxRepository.RetryOutbox(id)
+}
+
 // GetArticleByID retrieves an article by its ID.
 // It takes a context.Context and an integer ID as parameters.
 // It returns a pointer to an article.Article struct and an error.
+//
+// Concurrent cache misses for the same ID are coalesced into one database load,
+// and a missing article is cached for a short time so repeated requests for
+// nonexistent IDs do not reach the database.
 func (s *ArticleService) GetArticleByID(ctx context.Context, id int) (*article.Article, error) {
 	// Get the article from the cache
 	articleCache, err := s.articleCachingRepository.GetArticleByID(ctx, id)
@@ -79,34 +485,440 @@ func (s *ArticleService) GetArticleByID(ctx context.Context, id int) (*article.A
 		return articleCache, nil
 	}
 
-	// Get the article from the database
-	articleDb, err := s.articleQueryRepository.GetArticleByID(id)
+	loaded, err, _ := s.articleLoads.Do(strconv.Itoa(id), func() (interface{}, error) {
+		// Get the article from the database
+		articleDb, err := s.articleQueryRepository.GetArticleByID(id)
+		if err != nil {
+			// check if the article is not found
+			// gorm returns an error if the article is not found
+			if err == gorm.ErrRecordNotFound {
+				err := s.articleCachingRepository.CreateArticleNotFound(ctx, id)
+				if err != nil {
+					log.Printf("failed to create cache for missing article: %v", err)
+				}
+				return nil, article.ErrArticleNotFound
+			}
+
+			return nil, err
+		}
+
+		// Create cache for the article asynchronously, the load may be shared
+		// by several requests so it does not use the context of any of them
+		cacheInBackground("article", func(ctx context.Context) error {
+			return s.articleCachingRepository.CreateArticle(ctx, articleDb)
+		})
+
+		// Return the article
+		return articleDb, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return loaded.(*article.Article), nil
+}
+
+// GetArticleBySlug retrieves an article by one of its slugs, current or former.
+//
+// The slug is resolved to the ID of the article through the cache, or the database
+// on a miss, then the article is read like GetArticleByID. The article of a former
+// slug has another current Slug, the caller redirects to it.
+// article.ErrArticleNotFound is returned if no existing article ever had the slug.
+func (s *ArticleService) GetArticleBySlug(ctx context.Context, slug string) (*article.Article, error) {
+	id, err := s.articleCachingRepository.GetArticleIDBySlug(ctx, slug)
+	if err != article.ErrArticleCachingNotFound {
+		if err != nil {
+			return nil, err
+		}
+		return s.GetArticleByID(ctx, id)
+	}
+
+	articleSlug, err := s.articleSlugRepository.GetArticleSlug(slug)
 	if err != nil {
-		// check if the article is not found
-		// gorm returns an error if the article is not found
 		if err == gorm.ErrRecordNotFound {
 			return nil, article.ErrArticleNotFound
 		}
+		return nil, err
+	}
 
+	cacheInBackground("article slug", func(ctx context.Context) error {
+		return s.articleCachingRepository.CreateArticleSlug(ctx, slug, articleSlug.ArticleID)
+	})
+
+	return s.GetArticleByID(ctx, articleSlug.ArticleID)
+}
+
+// GetArticleRevisions returns the revisions of an article, the latest first and without their bodies.
+func (s *ArticleService) GetArticleRevisions(ctx context.Context, id int) (*article.ArticleRevisionsDTO, error) {
+	if _, err := s.GetArticleByID(ctx, id); err != nil {
 		return nil, err
 	}
 
-	// Create cache for the article asynchronously
-	go func() {
-		err := s.articleCachingRepository.CreateArticle(ctx, articleDb)
-		if err != nil {
-			log.Printf("failed to create cache for article: %v", err)
+	revisions, err := s.articleRevisionRepository.GetArticleRevisions(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &article.ArticleRevisionsDTO{Revisions: revisions}, nil
+}
+
+// GetArticleRevision returns a revision of an article.
+//
+// article.ErrRevisionNotFound is returned if the article has no such revision.
+func (s *ArticleService) GetArticleRevision(ctx context.Context, id, revision int) (*article.ArticleRevision, error) {
+	if _, err := s.GetArticleByID(ctx, id); err != nil {
+		return nil, err
+	}
+
+	return s.getArticleRevision(id, revision)
+}
+
+// GetArticleRevisionDiff returns the line-level diff of the title and body between two revisions of an article.
+//
+// Unless another revision is queried the diff starts from the previous revision, or from
+// an empty article if there is none.
+func (s *ArticleService) GetArticleRevisionDiff(ctx context.Context, query *article.ArticleRevisionDiffQuery) (*article.ArticleRevisionDiffDTO, error) {
+	to, err := s.GetArticleRevision(ctx, query.ID, query.Revision)
+	if err != nil {
+		return nil, err
+	}
+
+	var from *article.ArticleRevision
+	switch {
+	case query.From != 0:
+		if from, err = s.getArticleRevision(query.ID, query.From); err != nil {
+			return nil, err
 		}
-	}()
+	case query.Revision > 1:
+		from, err = s.getArticleRevision(query.ID, query.Revision-1)
+		if err != nil && err != article.ErrRevisionNotFound {
+			return nil, err
+		}
+	}
 
-	// Return the article
-	return articleDb, nil
+	return article.NewArticleRevisionDiffDTO(from, to), nil
+}
+
+// getArticleRevision returns a revision of an article from the database.
+func (s *ArticleService) getArticleRevision(id, revision int) (*article.ArticleRevision, error) {
+	item, err := s.articleRevisionRepository.GetArticleRevision(id, revision)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, article.ErrRevisionNotFound
+		}
+		return nil, err
+	}
+
+	return item, nil
 }
 
 // GetListArticles retrieves a list of articles based on the given query.
 //
 // query: The article query parameters.
 // Returns a slice of article pointers and an error if any.
+//
+// The pages of a list are read from the cache, or searched and cached for a short time.
+// The pages of a cursor are not cached.
 func (s *ArticleService) GetListArticles(ctx context.Context, query *article.ArticleQuery) (*article.ListArticleDTO, error) {
-	return s.articleQueryRepository.GetListArticles(ctx, query)
+	if query.UseCursor {
+		return s.articleQueryRepository.GetListArticles(ctx, query)
+	}
+
+	list, err := s.articleCachingRepository.GetListArticles(ctx, query)
+	if err != article.ErrArticleCachingNotFound {
+		if err != nil {
+			return nil, err
+		}
+		return list, nil
+	}
+
+	list, err = s.articleQueryRepository.GetListArticles(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	// degraded results are not cached so they are replaced once the search recovers
+	if list.Degraded {
+		return list, nil
+	}
+
+	cacheInBackground("article list", func(ctx context.Context) error {
+		return s.articleCachingRepository.CreateListArticles(ctx, query, list)
+	})
+
+	return list, nil
+}
+
+// GetArticleSuggestions returns the titles and authors starting with the prefix of the query.
+func (s *ArticleService) GetArticleSuggestions(ctx context.Context, query *article.ArticleSuggestQuery) (*article.ArticleSuggestionsDTO, error) {
+	return s.articleQueryRepository.GetArticleSuggestions(ctx, query)
+}
+
+// GetRelatedArticles returns the articles most similar to the article of the query.
+//
+// The related articles are read from the cache, or searched and cached for a short time.
+// article.ErrArticleNotFound is returned if the article of the query does not exist.
+func (s *ArticleService) GetRelatedArticles(ctx context.Context, query *article.ArticleRelatedQuery) (*article.RelatedArticlesDTO, error) {
+	related, err := s.articleCachingRepository.GetRelatedArticles(ctx, query)
+	if err != article.ErrArticleCachingNotFound {
+		if err != nil {
+			return nil, err
+		}
+		return related, nil
+	}
+
+	source, err := s.GetArticleByID(ctx, query.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	related, err = s.articleQueryRepository.GetRelatedArticles(ctx, query, source)
+	if err != nil {
+		return nil, err
+	}
+
+	// degraded results are not cached so they are replaced once the search recovers
+	if related.Degraded {
+		return related, nil
+	}
+
+	cacheInBackground("related articles", func(ctx context.Context) error {
+		return s.articleCachingRepository.CreateRelatedArticles(ctx, query, related)
+	})
+
+	return related, nil
+}
+
+// GetTags returns every tag sorted by name.
+func (s *ArticleService) GetTags(ctx context.Context) (*article.TagsDTO, error) {
+	tags, err := s.articleTaxonomyRepository.GetTags()
+	if err != nil {
+		return nil, err
+	}
+
+	return &article.TagsDTO{Tags: tags}, nil
+}
+
+// CreateTag creates a tag ahead of the articles using it, the articles also create their missing tags.
+//
+// article.ErrTagExists is returned if a tag already has the normalized name.
+func (s *ArticleService) CreateTag(ctx context.Context, cmd *article.TagCommand) (*article.Tag, error) {
+	if err := cmd.Validate(); err != nil {
+		return nil, err
+	}
+
+	tag := article.NewTag(cmd.Name)
+	if err := s.articleTaxonomyRepository.CreateTag(tag); err != nil {
+		return nil, err
+	}
+
+	return tag, nil
+}
+
+// UpdateTag renames a tag.
+//
+// The articles of the tag are evicted from the cache and re-indexed by the outbox dispatcher.
+// article.ErrTagExists is returned if another tag has the new name, the tags are merged instead.
+func (s *ArticleService) UpdateTag(ctx context.Context, cmd *article.TagCommand) (*article.Tag, error) {
+	if err := cmd.Validate(); err != nil {
+		return nil, err
+	}
+
+	tag, err := s.getTag(cmd.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	tag.Name = article.NormalizeTag(cmd.Name)
+	ids, err := s.articleTaxonomyRepository.UpdateTag(tag)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, article.ErrTagNotFound
+		}
+		return nil, err
+	}
+
+	s.evictTaxonomyArticles(ctx, ids)
+	return tag, nil
+}
+
+// MergeTag moves the articles of a tag to another tag and deletes the tag.
+// It returns the tag the articles were moved to.
+//
+// The articles of the merged tag are evicted from the cache and re-indexed by the outbox dispatcher.
+func (s *ArticleService) MergeTag(ctx context.Context, cmd *article.TagMergeCommand) (*article.Tag, error) {
+	if err := cmd.Validate(); err != nil {
+		return nil, err
+	}
+
+	tag, err := s.getTag(cmd.ID)
+	if err != nil {
+		return nil, err
+	}
+	into, err := s.getTag(cmd.Into)
+	if err != nil {
+		return nil, err
+	}
+
+	ids, err := s.articleTaxonomyRepository.MergeTag(tag, into)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, article.ErrTagNotFound
+		}
+		return nil, err
+	}
+
+	s.evictTaxonomyArticles(ctx, ids)
+	return into, nil
+}
+
+// DeleteTag deletes a tag and removes it from its articles.
+//
+// The articles of the tag are evicted from the cache and re-indexed by the outbox dispatcher.
+func (s *ArticleService) DeleteTag(ctx context.Context, id int) error {
+	ids, err := s.articleTaxonomyRepository.DeleteTag(id)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return article.ErrTagNotFound
+		}
+		return err
+	}
+
+	s.evictTaxonomyArticles(ctx, ids)
+	return nil
+}
+
+// getTag returns a tag from the database.
+func (s *ArticleService) getTag(id int) (*article.Tag, error) {
+	tag, err := s.articleTaxonomyRepository.GetTag(id)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, article.ErrTagNotFound
+		}
+		return nil, err
+	}
+	return tag, nil
+}
+
+// GetCategories returns the category tree.
+func (s *ArticleService) GetCategories(ctx context.Context) (*article.CategoriesDTO, error) {
+	categories, err := s.articleTaxonomyRepository.GetCategories()
+	if err != nil {
+		return nil, err
+	}
+
+	return &article.CategoriesDTO{Categories: article.NewCategoryTree(categories)}, nil
+}
+
+// CreateCategory creates a category under the parent of the command, or a root category without one.
+//
+// article.ErrCategoryParentNotFound is returned if the parent does not exist, and
+// article.ErrCategoryExists if the parent already has a category of the same slug.
+func (s *ArticleService) CreateCategory(ctx context.Context, cmd *article.CategoryCommand) (*article.Category, error) {
+	if err := cmd.Validate(); err != nil {
+		return nil, err
+	}
+
+	parent, err := s.getParentCategory(cmd.ParentID)
+	if err != nil {
+		return nil, err
+	}
+
+	category := article.NewCategory(cmd)
+	if err := category.MoveTo(cmd.Name, parent); err != nil {
+		return nil, err
+	}
+	if err := s.articleTaxonomyRepository.CreateCategory(category); err != nil {
+		return nil, err
+	}
+
+	return category, nil
+}
+
+// UpdateCategory renames a category and moves it under the parent of the command.
+//
+// The subcategories move along. If the path of the category changes, its articles and the articles
+// of its subcategories are evicted from the cache and re-indexed by the outbox dispatcher.
+// article.ErrCategoryCycle is returned if the parent is the category or one of its subcategories.
+func (s *ArticleService) UpdateCategory(ctx context.Context, cmd *article.CategoryCommand) (*article.Category, error) {
+	if err := cmd.Validate(); err != nil {
+		return nil, err
+	}
+
+	category, err := s.getCategory(cmd.ID)
+	if err != nil {
+		return nil, err
+	}
+	parent, err := s.getParentCategory(cmd.ParentID)
+	if err != nil {
+		return nil, err
+	}
+
+	previousPath := category.Path
+	if err := category.MoveTo(cmd.Name, parent); err != nil {
+		return nil, err
+	}
+
+	ids, err := s.articleTaxonomyRepository.UpdateCategory(category, previousPath)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, article.ErrCategoryNotFound
+		}
+		return nil, err
+	}
+
+	s.evictTaxonomyArticles(ctx, ids)
+	return category, nil
+}
+
+// DeleteCategory deletes a category.
+//
+// article.ErrCategoryNotEmpty is returned if the category still has subcategories or articles.
+func (s *ArticleService) DeleteCategory(ctx context.Context, id int) error {
+	err := s.articleTaxonomyRepository.DeleteCategory(id)
+	if err == gorm.ErrRecordNotFound {
+		return article.ErrCategoryNotFound
+	}
+	return err
+}
+
+// getCategory returns a category from the database.
+func (s *ArticleService) getCategory(id int) (*article.Category, error) {
+	category, err := s.articleTaxonomyRepository.GetCategory(id)
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, article.ErrCategoryNotFound
+		}
+		return nil, err
+	}
+	return category, nil
+}
+
+// getParentCategory returns the parent category of the ID, nil for a root category.
+func (s *ArticleService) getParentCategory(id *int) (*article.Category, error) {
+	if id == nil {
+		return nil, nil
+	}
+
+	parent, err := s.getCategory(*id)
+	if err == article.ErrCategoryNotFound {
+		return nil, article.ErrCategoryParentNotFound
+	}
+	return parent, err
+}
+
+// evictTaxonomyArticles evicts the cached articles changed by a change of their tags or category,
+// and every cached list if there are any.
+//
+// A failed eviction is only logged, like the eviction of the published scheduled articles.
+func (s *ArticleService) evictTaxonomyArticles(ctx context.Context, ids []int) {
+	if len(ids) == 0 {
+		return
+	}
+
+	for _, id := range ids {
+		if err := s.articleCachingRepository.DeleteArticle(ctx, id); err != nil {
+			log.Printf("failed to evict cache for article: %v", err)
+		}
+	}
+	s.evictListArticles(ctx)
 }
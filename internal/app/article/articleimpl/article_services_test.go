@@ -2,12 +2,17 @@ package articleimpl_test
 
 import (
 	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/undercode99/article_service/internal/app/article"
 	"github.com/undercode99/article_service/internal/app/article/articleimpl"
+	"github.com/undercode99/article_service/internal/diffing"
 	"gorm.io/gorm"
 )
 
@@ -16,14 +21,50 @@ type MockArticleCommandRepository struct {
 	mock.Mock
 }
 
-func (m *MockArticleCommandRepository) CreateArticle(article *article.Article) error {
-	return m.Called(article).Error(0)
+func (m *MockArticleCommandRepository) CreateArticle(article *article.Article, revision *article.ArticleRevision) error {
+	return m.Called(article, revision).Error(0)
+}
+
+func (m *MockArticleCommandRepository) UpdateArticle(article *article.Article, revision *article.ArticleRevision) error {
+	return m.Called(article, revision).Error(0)
+}
+
+func (m *MockArticleCommandRepository) DeleteArticle(id int, version int) error {
+	return m.Called(id, version).Error(0)
+}
+
+func (m *MockArticleCommandRepository) RestoreArticle(id int) error {
+	return m.Called(id).Error(0)
+}
+
+func (m *MockArticleCommandRepository) PurgeArticle(id int) error {
+	return m.Called(id).Error(0)
+}
+
+func (m *MockArticleCommandRepository) PurgeDeletedArticles(deletedBefore time.Time) ([]int, error) {
+	args := m.Called(deletedBefore)
+	if args.Error(1) != nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]int), args.Error(1)
+}
+
+func (m *MockArticleCommandRepository) PublishScheduledArticles(now time.Time) ([]int, error) {
+	args := m.Called(now)
+	if args.Error(1) != nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]int), args.Error(1)
 }
 
 func (m *MockArticleCommandRepository) CreateIndexArticle(ctx context.Context, item *article.Article) error {
 	return m.Called(ctx, item).Error(0)
 }
 
+func (m *MockArticleCommandRepository) DeleteIndexArticle(ctx context.Context, id int) error {
+	return m.Called(ctx, id).Error(0)
+}
+
 // Mocking ArticleQueryRepository
 type MockArticleQueryRepository struct {
 	mock.Mock
@@ -37,11 +78,32 @@ func (m *MockArticleQueryRepository) GetArticleByID(id int) (*article.Article, e
 	return args.Get(0).(*article.Article), args.Error(1)
 }
 
+func (m *MockArticleQueryRepository) GetDeletedArticleByID(id int) (*article.Article, error) {
+	args := m.Called(id)
+	if args.Error(1) != nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*article.Article), args.Error(1)
+}
+
 func (m *MockArticleQueryRepository) GetListArticles(ctx context.Context, query *article.ArticleQuery) (*article.ListArticleDTO, error) {
 	args := m.Called(query)
 	return args.Get(0).(*article.ListArticleDTO), args.Error(1)
 }
 
+func (m *MockArticleQueryRepository) GetRelatedArticles(ctx context.Context, query *article.ArticleRelatedQuery, source *article.Article) (*article.RelatedArticlesDTO, error) {
+	args := m.Called(query, source)
+	if args.Error(1) != nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*article.RelatedArticlesDTO), args.Error(1)
+}
+
+func (m *MockArticleQueryRepository) GetArticleSuggestions(ctx context.Context, query *article.ArticleSuggestQuery) (*article.ArticleSuggestionsDTO, error) {
+	args := m.Called(query)
+	return args.Get(0).(*article.ArticleSuggestionsDTO), args.Error(1)
+}
+
 // Mocking ArticleCachingRepository
 type MockArticleCachingRepository struct {
 	mock.Mock
@@ -51,6 +113,10 @@ func (m *MockArticleCachingRepository) CreateArticle(ctx context.Context, articl
 	return m.Called(ctx, article).Error(0)
 }
 
+func (m *MockArticleCachingRepository) CreateArticleNotFound(ctx context.Context, id int) error {
+	return m.Called(ctx, id).Error(0)
+}
+
 func (m *MockArticleCachingRepository) GetArticleByID(ctx context.Context, id int) (*article.Article, error) {
 	args := m.Called(ctx, id)
 	if args.Error(1) != nil {
@@ -59,12 +125,212 @@ func (m *MockArticleCachingRepository) GetArticleByID(ctx context.Context, id in
 	return args.Get(0).(*article.Article), args.Error(1)
 }
 
+func (m *MockArticleCachingRepository) DeleteArticle(ctx context.Context, id int) error {
+	return m.Called(ctx, id).Error(0)
+}
+
+func (m *MockArticleCachingRepository) CreateListArticles(ctx context.Context, query *article.ArticleQuery, list *article.ListArticleDTO) error {
+	return m.Called(ctx, query, list).Error(0)
+}
+
+func (m *MockArticleCachingRepository) GetListArticles(ctx context.Context, query *article.ArticleQuery) (*article.ListArticleDTO, error) {
+	args := m.Called(ctx, query)
+	if args.Error(1) != nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*article.ListArticleDTO), args.Error(1)
+}
+
+func (m *MockArticleCachingRepository) DeleteListArticles(ctx context.Context, authors ...string) error {
+	return m.Called(ctx, authors).Error(0)
+}
+
+func (m *MockArticleCachingRepository) CreateRelatedArticles(ctx context.Context, query *article.ArticleRelatedQuery, related *article.RelatedArticlesDTO) error {
+	return m.Called(ctx, query, related).Error(0)
+}
+
+func (m *MockArticleCachingRepository) GetRelatedArticles(ctx context.Context, query *article.ArticleRelatedQuery) (*article.RelatedArticlesDTO, error) {
+	args := m.Called(ctx, query)
+	if args.Error(1) != nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*article.RelatedArticlesDTO), args.Error(1)
+}
+
+func (m *MockArticleCachingRepository) CreateArticleSlug(ctx context.Context, slug string, id int) error {
+	return m.Called(ctx, slug, id).Error(0)
+}
+
+func (m *MockArticleCachingRepository) GetArticleIDBySlug(ctx context.Context, slug string) (int, error) {
+	args := m.Called(ctx, slug)
+	return args.Int(0), args.Error(1)
+}
+
+// Mocking ArticleOutboxRepository
+type MockArticleOutboxRepository struct {
+	mock.Mock
+}
+
+func (m *MockArticleOutboxRepository) CreateOutbox(outboxes []*article.ArticleOutbox) error {
+	return m.Called(outboxes).Error(0)
+}
+
+func (m *MockArticleOutboxRepository) ClaimOutbox(limit int, lease time.Duration) ([]article.ArticleOutbox, error) {
+	args := m.Called(limit, lease)
+	if args.Error(1) != nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]article.ArticleOutbox), args.Error(1)
+}
+
+func (m *MockArticleOutboxRepository) UpdateOutbox(outbox *article.ArticleOutbox) error {
+	return m.Called(outbox).Error(0)
+}
+
+func (m *MockArticleOutboxRepository) DeleteOutbox(id int) error {
+	return m.Called(id).Error(0)
+}
+
+func (m *MockArticleOutboxRepository) RetryOutbox(id int) error {
+	return m.Called(id).Error(0)
+}
+
+func (m *MockArticleOutboxRepository) GetOutboxStats(failuresLimit int) (*article.ArticleOutboxStatsDTO, error) {
+	args := m.Called(failuresLimit)
+	if args.Error(1) != nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*article.ArticleOutboxStatsDTO), args.Error(1)
+}
+
+// Mocking ArticleRevisionRepository
+type MockArticleRevisionRepository struct {
+	mock.Mock
+}
+
+func (m *MockArticleRevisionRepository) GetArticleRevisions(articleID int) ([]article.ArticleRevision, error) {
+	args := m.Called(articleID)
+	if args.Error(1) != nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]article.ArticleRevision), args.Error(1)
+}
+
+func (m *MockArticleRevisionRepository) GetArticleRevision(articleID, revision int) (*article.ArticleRevision, error) {
+	args := m.Called(articleID, revision)
+	if args.Error(1) != nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*article.ArticleRevision), args.Error(1)
+}
+
+// Mocking ArticleSlugRepository
+type MockArticleSlugRepository struct {
+	mock.Mock
+}
+
+func (m *MockArticleSlugRepository) GetArticleSlug(slug string) (*article.ArticleSlug, error) {
+	args := m.Called(slug)
+	if args.Error(1) != nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*article.ArticleSlug), args.Error(1)
+}
+
+// Mocking ArticleTaxonomyRepository
+type MockArticleTaxonomyRepository struct {
+	mock.Mock
+}
+
+func (m *MockArticleTaxonomyRepository) GetTags() ([]article.Tag, error) {
+	args := m.Called()
+	if args.Error(1) != nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]article.Tag), args.Error(1)
+}
+
+func (m *MockArticleTaxonomyRepository) GetTag(id int) (*article.Tag, error) {
+	args := m.Called(id)
+	if args.Error(1) != nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*article.Tag), args.Error(1)
+}
+
+func (m *MockArticleTaxonomyRepository) CreateTag(tag *article.Tag) error {
+	args := m.Called(tag)
+	return args.Error(0)
+}
+
+func (m *MockArticleTaxonomyRepository) UpdateTag(tag *article.Tag) ([]int, error) {
+	args := m.Called(tag)
+	if args.Error(1) != nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]int), args.Error(1)
+}
+
+func (m *MockArticleTaxonomyRepository) MergeTag(tag *article.Tag, into *article.Tag) ([]int, error) {
+	args := m.Called(tag, into)
+	if args.Error(1) != nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]int), args.Error(1)
+}
+
+func (m *MockArticleTaxonomyRepository) DeleteTag(id int) ([]int, error) {
+	args := m.Called(id)
+	if args.Error(1) != nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]int), args.Error(1)
+}
+
+func (m *MockArticleTaxonomyRepository) GetCategories() ([]article.Category, error) {
+	args := m.Called()
+	if args.Error(1) != nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]article.Category), args.Error(1)
+}
+
+func (m *MockArticleTaxonomyRepository) GetCategory(id int) (*article.Category, error) {
+	args := m.Called(id)
+	if args.Error(1) != nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*article.Category), args.Error(1)
+}
+
+func (m *MockArticleTaxonomyRepository) CreateCategory(category *article.Category) error {
+	args := m.Called(category)
+	return args.Error(0)
+}
+
+func (m *MockArticleTaxonomyRepository) UpdateCategory(category *article.Category, previousPath string) ([]int, error) {
+	args := m.Called(category, previousPath)
+	if args.Error(1) != nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]int), args.Error(1)
+}
+
+func (m *MockArticleTaxonomyRepository) DeleteCategory(id int) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+// isLiveContext matches the context of a write to the cache made in the background,
+// which must outlive the request it was made for.
+var isLiveContext = mock.MatchedBy(func(ctx context.Context) bool { return ctx.Err() == nil })
+
 func TestNewArticleService(t *testing.T) {
 	mockArticleCommandRepository := &MockArticleCommandRepository{}
 	mockArticleQueryRepository := &MockArticleQueryRepository{}
 	mockArticleCachingRepository := &MockArticleCachingRepository{}
 
-	articleService := articleimpl.NewArticleService(mockArticleCommandRepository, mockArticleQueryRepository, mockArticleCachingRepository)
+	articleService := articleimpl.NewArticleService(mockArticleCommandRepository, mockArticleQueryRepository, mockArticleCachingRepository, &MockArticleOutboxRepository{}, &MockArticleRevisionRepository{}, &MockArticleSlugRepository{}, &MockArticleTaxonomyRepository{})
 
 	// Testing that the returned ArticleService is not nil
 	if articleService == nil {
@@ -98,11 +364,16 @@ func TestCreateArticle(t *testing.T) {
 		mockArticleCommandRepository,
 		mockArticleQueryRepository,
 		mockArticleCachingRepository,
+		&MockArticleOutboxRepository{},
+		&MockArticleRevisionRepository{},
+		&MockArticleSlugRepository{},
+		&MockArticleTaxonomyRepository{},
 	)
 
 	// Set up expectations for the mock repositories
-	mockArticleCommandRepository.On("CreateArticle", mock.Anything).Return(nil)
-	mockArticleCommandRepository.On("CreateIndexArticle", ctx, mock.Anything).Return(nil)
+	mockArticleCommandRepository.On("CreateArticle", mock.Anything, mock.Anything).Return(nil)
+	mockArticleCachingRepository.On("DeleteArticle", ctx, mock.Anything).Return(nil)
+	mockArticleCachingRepository.On("DeleteListArticles", ctx, []string{"John Doe"}).Return(nil)
 
 	// Create the article using the article service's CreateArticle method
 	createdArticle, err := articleService.CreateArticle(ctx, cmd)
@@ -110,6 +381,629 @@ func TestCreateArticle(t *testing.T) {
 	// Check that no error is returned and that the created article is not nil
 	assert.Nil(t, err)
 	assert.NotNil(t, createdArticle)
+
+	// Check that indexing is left to the outbox dispatcher
+	mockArticleCommandRepository.AssertNotCalled(t, "CreateIndexArticle", mock.Anything, mock.Anything)
+
+	// Check that a cached missing entry for the ID is evicted
+	mockArticleCachingRepository.AssertCalled(t, "DeleteArticle", ctx, createdArticle.ID)
+
+	// Check that the cached lists of the author are evicted
+	mockArticleCachingRepository.AssertCalled(t, "DeleteListArticles", ctx, []string{"John Doe"})
+}
+
+// TestUpdateArticle tests the UpdateArticle function.
+//
+// 1. Test the article is updated and evicted from the cache.
+// 2. Test the lists of the previous and new author are evicted.
+// 3. Test the article does not exist.
+// 4. Test the command is invalid.
+// 5. Test only the editors and the author of a draft may update it, and only the editors change its author.
+// 6. Test a failed eviction of the updated article does not fail the update.
+func TestUpdateArticle(t *testing.T) {
+	ctx := context.Background()
+	author := article.Actor{User: "John Doe"}
+	editor := article.Actor{User: "jane", Role: article.RoleEditor}
+
+	t.Run("Article updated", func(t *testing.T) {
+		mockArticleCommandRepo := &MockArticleCommandRepository{}
+		mockArticleQueryRepo := &MockArticleQueryRepository{}
+		mockArticleCachingRepo := &MockArticleCachingRepository{}
+		articleService := articleimpl.NewArticleService(mockArticleCommandRepo, mockArticleQueryRepo, mockArticleCachingRepo, &MockArticleOutboxRepository{}, &MockArticleRevisionRepository{}, &MockArticleSlugRepository{}, &MockArticleTaxonomyRepository{})
+
+		cmd := &article.ArticleUpdateCommand{ID: 1, Author: "John Doe", Title: "New Title", Body: "New body.", Actor: author, Editor: "jane", Note: "Typo"}
+		isUpdated := mock.MatchedBy(func(item *article.Article) bool {
+			return item.ID == 1 && item.Title == "New Title" && item.Body == "New body." && !item.Updated.IsZero()
+		})
+		isRevision := mock.MatchedBy(func(revision *article.ArticleRevision) bool {
+			return revision.Title == "New Title" && revision.Body == "New body." && revision.Editor == "jane" && revision.Note == "Typo"
+		})
+
+		mockArticleQueryRepo.On("GetArticleByID", 1).Return(&article.Article{ID: 1, Author: "John Doe", Title: "Old Title", Body: "Old body.", Status: article.StatusDraft}, nil)
+		mockArticleCommandRepo.On("UpdateArticle", isUpdated, isRevision).Return(nil)
+		mockArticleCachingRepo.On("DeleteArticle", ctx, 1).Return(nil)
+		mockArticleCachingRepo.On("DeleteListArticles", ctx, []string{"John Doe"}).Return(nil)
+
+		updatedArticle, err := articleService.UpdateArticle(ctx, cmd)
+
+		assert.Nil(t, err)
+		assert.Equal(t, "New Title", updatedArticle.Title)
+		assert.Equal(t, "New body.", updatedArticle.Body)
+		mockArticleCommandRepo.AssertCalled(t, "UpdateArticle", isUpdated, isRevision)
+		mockArticleCachingRepo.AssertCalled(t, "DeleteArticle", ctx, 1)
+		mockArticleCachingRepo.AssertCalled(t, "DeleteListArticles", ctx, []string{"John Doe"})
+	})
+
+	t.Run("Article moved to another author", func(t *testing.T) {
+		mockArticleCommandRepo := &MockArticleCommandRepository{}
+		mockArticleQueryRepo := &MockArticleQueryRepository{}
+		mockArticleCachingRepo := &MockArticleCachingRepository{}
+		articleService := articleimpl.NewArticleService(mockArticleCommandRepo, mockArticleQueryRepo, mockArticleCachingRepo, &MockArticleOutboxRepository{}, &MockArticleRevisionRepository{}, &MockArticleSlugRepository{}, &MockArticleTaxonomyRepository{})
+
+		mockArticleQueryRepo.On("GetArticleByID", 3).Return(&article.Article{ID: 3, Author: "John Doe", Title: "Title", Body: "Body"}, nil)
+		mockArticleCommandRepo.On("UpdateArticle", mock.Anything, mock.Anything).Return(nil)
+		mockArticleCachingRepo.On("DeleteArticle", ctx, 3).Return(nil)
+		mockArticleCachingRepo.On("DeleteListArticles", ctx, []string{"Jane Doe", "John Doe"}).Return(nil)
+
+		_, err := articleService.UpdateArticle(ctx, &article.ArticleUpdateCommand{ID: 3, Author: "Jane Doe", Title: "Title", Body: "Body", Actor: editor})
+
+		assert.Nil(t, err)
+		mockArticleCachingRepo.AssertCalled(t, "DeleteListArticles", ctx, []string{"Jane Doe", "John Doe"})
+	})
+
+	t.Run("Article not found", func(t *testing.T) {
+		mockArticleCommandRepo := &MockArticleCommandRepository{}
+		mockArticleQueryRepo := &MockArticleQueryRepository{}
+		mockArticleCachingRepo := &MockArticleCachingRepository{}
+		articleService := articleimpl.NewArticleService(mockArticleCommandRepo, mockArticleQueryRepo, mockArticleCachingRepo, &MockArticleOutboxRepository{}, &MockArticleRevisionRepository{}, &MockArticleSlugRepository{}, &MockArticleTaxonomyRepository{})
+
+		mockArticleQueryRepo.On("GetArticleByID", 2).Return(nil, gorm.ErrRecordNotFound)
+
+		updatedArticle, err := articleService.UpdateArticle(ctx, &article.ArticleUpdateCommand{ID: 2, Author: "John Doe", Title: "Title", Body: "Body"})
+
+		assert.Nil(t, updatedArticle)
+		assert.Equal(t, article.ErrArticleNotFound, err)
+		mockArticleCommandRepo.AssertNotCalled(t, "UpdateArticle", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Article version mismatch", func(t *testing.T) {
+		mockArticleCommandRepo := &MockArticleCommandRepository{}
+		mockArticleQueryRepo := &MockArticleQueryRepository{}
+		articleService := articleimpl.NewArticleService(mockArticleCommandRepo, mockArticleQueryRepo, &MockArticleCachingRepository{}, &MockArticleOutboxRepository{}, &MockArticleRevisionRepository{}, &MockArticleSlugRepository{}, &MockArticleTaxonomyRepository{})
+
+		mockArticleQueryRepo.On("GetArticleByID", 1).Return(&article.Article{ID: 1, Author: "John Doe", Title: "Title", Body: "Body", Version: 3}, nil)
+
+		updatedArticle, err := articleService.UpdateArticle(ctx, &article.ArticleUpdateCommand{ID: 1, Author: "John Doe", Title: "Title", Body: "Body", Actor: editor, Version: 2})
+
+		assert.Nil(t, updatedArticle)
+		assert.Equal(t, article.ErrArticleVersionMismatch, err)
+		mockArticleCommandRepo.AssertNotCalled(t, "UpdateArticle", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Invalid command", func(t *testing.T) {
+		articleService := articleimpl.NewArticleService(&MockArticleCommandRepository{}, &MockArticleQueryRepository{}, &MockArticleCachingRepository{}, &MockArticleOutboxRepository{}, &MockArticleRevisionRepository{}, &MockArticleSlugRepository{}, &MockArticleTaxonomyRepository{})
+
+		updatedArticle, err := articleService.UpdateArticle(ctx, &article.ArticleUpdateCommand{ID: 1, Author: "John Doe"})
+
+		assert.Nil(t, updatedArticle)
+		assert.Equal(t, article.ErrArticleValidation, err)
+	})
+
+	t.Run("Update forbidden", func(t *testing.T) {
+		mockArticleCommandRepo := &MockArticleCommandRepository{}
+		mockArticleQueryRepo := &MockArticleQueryRepository{}
+		articleService := articleimpl.NewArticleService(mockArticleCommandRepo, mockArticleQueryRepo, &MockArticleCachingRepository{}, &MockArticleOutboxRepository{}, &MockArticleRevisionRepository{}, &MockArticleSlugRepository{}, &MockArticleTaxonomyRepository{})
+
+		mockArticleQueryRepo.On("GetArticleByID", 1).Return(&article.Article{ID: 1, Author: "John Doe", Title: "Title", Body: "Body", Status: article.StatusDraft}, nil)
+		mockArticleQueryRepo.On("GetArticleByID", 2).Return(&article.Article{ID: 2, Author: "John Doe", Title: "Title", Body: "Body", Status: article.StatusPublished}, nil)
+
+		// the draft of another author is not disclosed
+		_, err := articleService.UpdateArticle(ctx, &article.ArticleUpdateCommand{ID: 1, Author: "John Doe", Title: "Title", Body: "Body", Actor: article.Actor{User: "Jim Doe"}})
+		assert.Equal(t, article.ErrArticleNotFound, err)
+		// the published article of the author goes through the review
+		_, err = articleService.UpdateArticle(ctx, &article.ArticleUpdateCommand{ID: 2, Author: "John Doe", Title: "Title", Body: "Body", Actor: author})
+		assert.Equal(t, article.ErrEditForbidden, err)
+		_, err = articleService.UpdateArticle(ctx, &article.ArticleUpdateCommand{ID: 1, Author: "Jim Doe", Title: "Title", Body: "Body", Actor: author})
+		assert.Equal(t, article.ErrAuthorForbidden, err)
+		mockArticleCommandRepo.AssertNotCalled(t, "UpdateArticle", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Eviction failed", func(t *testing.T) {
+		mockArticleCommandRepo := &MockArticleCommandRepository{}
+		mockArticleQueryRepo := &MockArticleQueryRepository{}
+		mockArticleCachingRepo := &MockArticleCachingRepository{}
+		articleService := articleimpl.NewArticleService(mockArticleCommandRepo, mockArticleQueryRepo, mockArticleCachingRepo, &MockArticleOutboxRepository{}, &MockArticleRevisionRepository{}, &MockArticleSlugRepository{}, &MockArticleTaxonomyRepository{})
+
+		mockArticleQueryRepo.On("GetArticleByID", 1).Return(&article.Article{ID: 1, Author: "John Doe", Title: "Title", Body: "Body", Status: article.StatusDraft}, nil)
+		mockArticleCommandRepo.On("UpdateArticle", mock.Anything, mock.Anything).Return(nil)
+		mockArticleCachingRepo.On("DeleteArticle", ctx, 1).Return(errors.New("redis is down"))
+		mockArticleCachingRepo.On("DeleteListArticles", ctx, []string{"John Doe"}).Return(nil)
+
+		updatedArticle, err := articleService.UpdateArticle(ctx, &article.ArticleUpdateCommand{ID: 1, Author: "John Doe", Title: "New Title", Body: "Body", Actor: author})
+
+		assert.Nil(t, err)
+		assert.Equal(t, "New Title", updatedArticle.Title)
+		mockArticleCachingRepo.AssertCalled(t, "DeleteListArticles", ctx, []string{"John Doe"})
+	})
+}
+
+// TestDeleteArticle tests the DeleteArticle function.
+//
+// 1. Test the article is soft-deleted and evicted from the cache.
+// 2. Test the article does not exist.
+// 3. Test the article is at another version.
+// 4. Test only the editors and the author may delete it.
+func TestDeleteArticle(t *testing.T) {
+	ctx := context.Background()
+	author := article.Actor{User: "John Doe"}
+
+	t.Run("Article deleted", func(t *testing.T) {
+		mockArticleCommandRepo := &MockArticleCommandRepository{}
+		mockArticleQueryRepo := &MockArticleQueryRepository{}
+		mockArticleCachingRepo := &MockArticleCachingRepository{}
+		articleService := articleimpl.NewArticleService(mockArticleCommandRepo, mockArticleQueryRepo, mockArticleCachingRepo, &MockArticleOutboxRepository{}, &MockArticleRevisionRepository{}, &MockArticleSlugRepository{}, &MockArticleTaxonomyRepository{})
+
+		mockArticleQueryRepo.On("GetArticleByID", 1).Return(&article.Article{ID: 1, Author: "John Doe", Status: article.StatusPublished, Version: 4}, nil)
+		mockArticleCommandRepo.On("DeleteArticle", 1, 4).Return(nil)
+		mockArticleCachingRepo.On("DeleteArticle", ctx, 1).Return(nil)
+		mockArticleCachingRepo.On("DeleteListArticles", ctx, []string(nil)).Return(nil)
+
+		err := articleService.DeleteArticle(ctx, &article.ArticleDeleteCommand{ID: 1, Actor: author, Version: 4})
+
+		assert.Nil(t, err)
+		mockArticleCommandRepo.AssertCalled(t, "DeleteArticle", 1, 4)
+		mockArticleCachingRepo.AssertCalled(t, "DeleteArticle", ctx, 1)
+	})
+
+	t.Run("Article not found", func(t *testing.T) {
+		mockArticleCommandRepo := &MockArticleCommandRepository{}
+		mockArticleQueryRepo := &MockArticleQueryRepository{}
+		mockArticleCachingRepo := &MockArticleCachingRepository{}
+		articleService := articleimpl.NewArticleService(mockArticleCommandRepo, mockArticleQueryRepo, mockArticleCachingRepo, &MockArticleOutboxRepository{}, &MockArticleRevisionRepository{}, &MockArticleSlugRepository{}, &MockArticleTaxonomyRepository{})
+
+		mockArticleQueryRepo.On("GetArticleByID", 2).Return(nil, gorm.ErrRecordNotFound)
+
+		err := articleService.DeleteArticle(ctx, &article.ArticleDeleteCommand{ID: 2, Actor: author})
+
+		assert.Equal(t, article.ErrArticleNotFound, err)
+		mockArticleCommandRepo.AssertNotCalled(t, "DeleteArticle", mock.Anything, mock.Anything)
+		mockArticleCachingRepo.AssertNotCalled(t, "DeleteArticle", ctx, 2)
+	})
+
+	t.Run("Article version mismatch", func(t *testing.T) {
+		mockArticleCommandRepo := &MockArticleCommandRepository{}
+		mockArticleQueryRepo := &MockArticleQueryRepository{}
+		mockArticleCachingRepo := &MockArticleCachingRepository{}
+		articleService := articleimpl.NewArticleService(mockArticleCommandRepo, mockArticleQueryRepo, mockArticleCachingRepo, &MockArticleOutboxRepository{}, &MockArticleRevisionRepository{}, &MockArticleSlugRepository{}, &MockArticleTaxonomyRepository{})
+
+		// the article changes between the read and the deletion
+		mockArticleQueryRepo.On("GetArticleByID", 1).Return(&article.Article{ID: 1, Author: "John Doe", Status: article.StatusPublished, Version: 2}, nil)
+		mockArticleCommandRepo.On("DeleteArticle", 1, 2).Return(article.ErrArticleVersionMismatch)
+
+		err := articleService.DeleteArticle(ctx, &article.ArticleDeleteCommand{ID: 1, Actor: author, Version: 2})
+
+		assert.Equal(t, article.ErrArticleVersionMismatch, err)
+		mockArticleCachingRepo.AssertNotCalled(t, "DeleteArticle", ctx, 1)
+	})
+
+	t.Run("Deletion forbidden", func(t *testing.T) {
+		mockArticleCommandRepo := &MockArticleCommandRepository{}
+		mockArticleQueryRepo := &MockArticleQueryRepository{}
+		articleService := articleimpl.NewArticleService(mockArticleCommandRepo, mockArticleQueryRepo, &MockArticleCachingRepository{}, &MockArticleOutboxRepository{}, &MockArticleRevisionRepository{}, &MockArticleSlugRepository{}, &MockArticleTaxonomyRepository{})
+
+		mockArticleQueryRepo.On("GetArticleByID", 1).Return(&article.Article{ID: 1, Author: "John Doe", Status: article.StatusPublished}, nil)
+
+		err := articleService.DeleteArticle(ctx, &article.ArticleDeleteCommand{ID: 1, Actor: article.Actor{User: "Jim Doe"}})
+
+		assert.Equal(t, article.ErrDeleteForbidden, err)
+		mockArticleCommandRepo.AssertNotCalled(t, "DeleteArticle", mock.Anything, mock.Anything)
+	})
+}
+
+// TestRevertArticle tests the RevertArticle function.
+//
+// 1. Test the title and body of the revision are saved as a new revision, the tags and category are kept.
+// 2. Test the article has no such revision.
+func TestRevertArticle(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Article reverted", func(t *testing.T) {
+		mockArticleCommandRepo := &MockArticleCommandRepository{}
+		mockArticleQueryRepo := &MockArticleQueryRepository{}
+		mockArticleCachingRepo := &MockArticleCachingRepository{}
+		mockArticleRevisionRepo := &MockArticleRevisionRepository{}
+		articleService := articleimpl.NewArticleService(mockArticleCommandRepo, mockArticleQueryRepo, mockArticleCachingRepo, &MockArticleOutboxRepository{}, mockArticleRevisionRepo, &MockArticleSlugRepository{}, &MockArticleTaxonomyRepository{})
+
+		categoryID := 2
+		isReverted := mock.MatchedBy(func(item *article.Article) bool {
+			return item.Title == "Good title" && item.Body == "Good body" && item.Author == "John Doe" &&
+				len(item.Tags) == 1 && item.Tags[0] == "golang" && item.CategoryID == &categoryID && item.Category == "tech"
+		})
+		isRevision := mock.MatchedBy(func(revision *article.ArticleRevision) bool {
+			return revision.Title == "Good title" && revision.Editor == "jane" && revision.Note == "Reverted to revision 2"
+		})
+
+		mockArticleQueryRepo.On("GetArticleByID", 1).Return(&article.Article{
+			ID: 1, Author: "John Doe", Title: "Spam", Body: "Spam", Version: 3,
+			Tags: []string{"golang"}, CategoryID: &categoryID, Category: "tech",
+		}, nil)
+		mockArticleRevisionRepo.On("GetArticleRevision", 1, 2).Return(&article.ArticleRevision{ArticleID: 1, Revision: 2, Title: "Good title", Body: "Good body"}, nil)
+		mockArticleCommandRepo.On("UpdateArticle", isReverted, isRevision).Return(nil)
+		mockArticleCachingRepo.On("DeleteArticle", ctx, 1).Return(nil)
+		mockArticleCachingRepo.On("DeleteListArticles", ctx, []string{"John Doe"}).Return(nil)
+
+		revertedArticle, err := articleService.RevertArticle(ctx, &article.ArticleRevertCommand{ID: 1, Revision: 2, Version: 3, Actor: article.Actor{User: "jane", Role: article.RoleEditor}, Editor: "jane"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Good title", revertedArticle.Title)
+		mockArticleCommandRepo.AssertCalled(t, "UpdateArticle", isReverted, isRevision)
+		mockArticleCachingRepo.AssertCalled(t, "DeleteArticle", ctx, 1)
+	})
+
+	t.Run("Revision not found", func(t *testing.T) {
+		mockArticleCommandRepo := &MockArticleCommandRepository{}
+		mockArticleQueryRepo := &MockArticleQueryRepository{}
+		mockArticleRevisionRepo := &MockArticleRevisionRepository{}
+		articleService := articleimpl.NewArticleService(mockArticleCommandRepo, mockArticleQueryRepo, &MockArticleCachingRepository{}, &MockArticleOutboxRepository{}, mockArticleRevisionRepo, &MockArticleSlugRepository{}, &MockArticleTaxonomyRepository{})
+
+		mockArticleQueryRepo.On("GetArticleByID", 1).Return(&article.Article{ID: 1, Version: 3}, nil)
+		mockArticleRevisionRepo.On("GetArticleRevision", 1, 7).Return(nil, gorm.ErrRecordNotFound)
+
+		_, err := articleService.RevertArticle(ctx, &article.ArticleRevertCommand{ID: 1, Revision: 7, Actor: article.Actor{Role: article.RoleAdmin}})
+
+		assert.Equal(t, article.ErrRevisionNotFound, err)
+		mockArticleCommandRepo.AssertNotCalled(t, "UpdateArticle", mock.Anything, mock.Anything)
+	})
+}
+
+// TestTransitionArticle tests the TransitionArticle function.
+//
+// 1. Test an article is published with a revision noting the transition.
+// 2. Test a publication in the future is scheduled.
+// 3. Test an author may not publish.
+// 4. Test an archived article cannot be published.
+// 5. Test the draft of another author is not found, whatever the version of the command.
+func TestTransitionArticle(t *testing.T) {
+	ctx := context.Background()
+	editor := article.Actor{User: "jane", Role: article.RoleEditor}
+
+	t.Run("Article published", func(t *testing.T) {
+		mockArticleCommandRepo := &MockArticleCommandRepository{}
+		mockArticleQueryRepo := &MockArticleQueryRepository{}
+		mockArticleCachingRepo := &MockArticleCachingRepository{}
+		articleService := articleimpl.NewArticleService(mockArticleCommandRepo, mockArticleQueryRepo, mockArticleCachingRepo, &MockArticleOutboxRepository{}, &MockArticleRevisionRepository{}, &MockArticleSlugRepository{}, &MockArticleTaxonomyRepository{})
+
+		isPublished := mock.MatchedBy(func(item *article.Article) bool {
+			return item.Status == article.StatusPublished && item.PublishAt != nil
+		})
+		isRevision := mock.MatchedBy(func(revision *article.ArticleRevision) bool {
+			return revision.Title == "Title" && revision.Editor == "jane" && revision.Note == "Published"
+		})
+
+		mockArticleQueryRepo.On("GetArticleByID", 1).Return(&article.Article{ID: 1, Author: "John Doe", Title: "Title", Status: article.StatusInReview, Version: 2}, nil)
+		mockArticleCommandRepo.On("UpdateArticle", isPublished, isRevision).Return(nil)
+		mockArticleCachingRepo.On("DeleteArticle", ctx, 1).Return(nil)
+		mockArticleCachingRepo.On("DeleteListArticles", ctx, []string{"John Doe"}).Return(nil)
+
+		publishedArticle, err := articleService.TransitionArticle(ctx, &article.ArticleTransitionCommand{
+			ID: 1, Transition: article.TransitionPublish, Actor: editor, Editor: "jane", Version: 2,
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, article.StatusPublished, publishedArticle.Status)
+		mockArticleCommandRepo.AssertCalled(t, "UpdateArticle", isPublished, isRevision)
+		mockArticleCachingRepo.AssertCalled(t, "DeleteArticle", ctx, 1)
+	})
+
+	t.Run("Publication scheduled", func(t *testing.T) {
+		mockArticleCommandRepo := &MockArticleCommandRepository{}
+		mockArticleQueryRepo := &MockArticleQueryRepository{}
+		mockArticleCachingRepo := &MockArticleCachingRepository{}
+		articleService := articleimpl.NewArticleService(mockArticleCommandRepo, mockArticleQueryRepo, mockArticleCachingRepo, &MockArticleOutboxRepository{}, &MockArticleRevisionRepository{}, &MockArticleSlugRepository{}, &MockArticleTaxonomyRepository{})
+
+		publishAt := time.Date(2100, 1, 1, 9, 0, 0, 0, time.UTC)
+		isScheduled := mock.MatchedBy(func(item *article.Article) bool {
+			return item.Status == article.StatusInReview && item.PublishAt.Equal(publishAt)
+		})
+		isRevision := mock.MatchedBy(func(revision *article.ArticleRevision) bool {
+			return revision.Note == "Scheduled for publication at 2100-01-01T09:00:00Z"
+		})
+
+		mockArticleQueryRepo.On("GetArticleByID", 1).Return(&article.Article{ID: 1, Author: "John Doe", Status: article.StatusInReview, Version: 2}, nil)
+		mockArticleCommandRepo.On("UpdateArticle", isScheduled, isRevision).Return(nil)
+		mockArticleCachingRepo.On("DeleteArticle", ctx, 1).Return(nil)
+		mockArticleCachingRepo.On("DeleteListArticles", ctx, []string{"John Doe"}).Return(nil)
+
+		scheduledArticle, err := articleService.TransitionArticle(ctx, &article.ArticleTransitionCommand{
+			ID: 1, Transition: article.TransitionPublish, PublishAt: &publishAt, Actor: editor,
+		})
+
+		assert.NoError(t, err)
+		assert.True(t, scheduledArticle.IsScheduled())
+		mockArticleCommandRepo.AssertCalled(t, "UpdateArticle", isScheduled, isRevision)
+	})
+
+	for _, tt := range []struct {
+		name    string
+		status  string
+		actor   article.Actor
+		version int
+		err     error
+	}{
+		{name: "Author may not publish", status: article.StatusInReview, actor: article.Actor{User: "John Doe"}, err: article.ErrTransitionForbidden},
+		{name: "Archived article not published", status: article.StatusArchived, actor: editor, err: article.ErrInvalidTransition},
+		{name: "Draft of another author not found", status: article.StatusDraft, actor: article.Actor{User: "Jim Doe"}, version: 1, err: article.ErrArticleNotFound},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			mockArticleCommandRepo := &MockArticleCommandRepository{}
+			mockArticleQueryRepo := &MockArticleQueryRepository{}
+			articleService := articleimpl.NewArticleService(mockArticleCommandRepo, mockArticleQueryRepo, &MockArticleCachingRepository{}, &MockArticleOutboxRepository{}, &MockArticleRevisionRepository{}, &MockArticleSlugRepository{}, &MockArticleTaxonomyRepository{})
+
+			mockArticleQueryRepo.On("GetArticleByID", 1).Return(&article.Article{ID: 1, Author: "John Doe", Status: tt.status, Version: 2}, nil)
+
+			_, err := articleService.TransitionArticle(ctx, &article.ArticleTransitionCommand{ID: 1, Transition: article.TransitionPublish, Actor: tt.actor, Version: tt.version})
+
+			assert.Equal(t, tt.err, err)
+			mockArticleCommandRepo.AssertNotCalled(t, "UpdateArticle", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+// TestGetArticleRevisionDiff tests the GetArticleRevisionDiff function.
+//
+// 1. Test a revision is diffed from the previous one by default.
+// 2. Test the first revision is diffed from an empty article.
+// 3. Test a revision is diffed from the queried one.
+func TestGetArticleRevisionDiff(t *testing.T) {
+	ctx := context.Background()
+
+	mockArticleCachingRepo := &MockArticleCachingRepository{}
+	mockArticleRevisionRepo := &MockArticleRevisionRepository{}
+	articleService := articleimpl.NewArticleService(&MockArticleCommandRepository{}, &MockArticleQueryRepository{}, mockArticleCachingRepo, &MockArticleOutboxRepository{}, mockArticleRevisionRepo, &MockArticleSlugRepository{}, &MockArticleTaxonomyRepository{})
+
+	mockArticleCachingRepo.On("GetArticleByID", ctx, 1).Return(&article.Article{ID: 1, Version: 3}, nil)
+	mockArticleRevisionRepo.On("GetArticleRevision", 1, 1).Return(&article.ArticleRevision{Revision: 1, Title: "Title", Body: "one\ntwo"}, nil)
+	mockArticleRevisionRepo.On("GetArticleRevision", 1, 2).Return(&article.ArticleRevision{Revision: 2, Title: "Title", Body: "one\n2"}, nil)
+	mockArticleRevisionRepo.On("GetArticleRevision", 1, 3).Return(&article.ArticleRevision{Revision: 3, Title: "New title", Body: "one\n2"}, nil)
+
+	t.Run("From the previous revision", func(t *testing.T) {
+		diff, err := articleService.GetArticleRevisionDiff(ctx, &article.ArticleRevisionDiffQuery{ID: 1, Revision: 2})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, diff.From)
+		assert.Equal(t, 2, diff.To)
+		assert.Equal(t, []diffing.Line{{Op: diffing.OpEqual, Text: "Title"}}, diff.Title)
+		assert.Equal(t, []diffing.Line{
+			{Op: diffing.OpEqual, Text: "one"},
+			{Op: diffing.OpDelete, Text: "two"},
+			{Op: diffing.OpInsert, Text: "2"},
+		}, diff.Body)
+	})
+
+	t.Run("First revision", func(t *testing.T) {
+		diff, err := articleService.GetArticleRevisionDiff(ctx, &article.ArticleRevisionDiffQuery{ID: 1, Revision: 1})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 0, diff.From)
+		assert.Equal(t, []diffing.Line{{Op: diffing.OpInsert, Text: "Title"}}, diff.Title)
+	})
+
+	t.Run("From the queried revision", func(t *testing.T) {
+		diff, err := articleService.GetArticleRevisionDiff(ctx, &article.ArticleRevisionDiffQuery{ID: 1, Revision: 3, From: 1})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, diff.From)
+		assert.Equal(t, []diffing.Line{{Op: diffing.OpDelete, Text: "Title"}, {Op: diffing.OpInsert, Text: "New title"}}, diff.Title)
+	})
+}
+
+// TestRestoreArticle tests the RestoreArticle function.
+//
+// 1. Test the article is restored.
+// 2. Test there is no deleted article with the given ID.
+// 3. Test only the editors and the author may restore it, and the draft of another author is not found.
+func TestRestoreArticle(t *testing.T) {
+	ctx := context.Background()
+	author := article.Actor{User: "John Doe"}
+
+	t.Run("Article restored", func(t *testing.T) {
+		mockArticleCommandRepo := &MockArticleCommandRepository{}
+		mockArticleQueryRepo := &MockArticleQueryRepository{}
+		mockArticleCachingRepo := &MockArticleCachingRepository{}
+		articleService := articleimpl.NewArticleService(mockArticleCommandRepo, mockArticleQueryRepo, mockArticleCachingRepo, &MockArticleOutboxRepository{}, &MockArticleRevisionRepository{}, &MockArticleSlugRepository{}, &MockArticleTaxonomyRepository{})
+
+		restored := &article.Article{ID: 1, Title: "Test Article", Author: "John Doe"}
+		mockArticleQueryRepo.On("GetDeletedArticleByID", 1).Return(restored, nil)
+		mockArticleCommandRepo.On("RestoreArticle", 1).Return(nil)
+		mockArticleCachingRepo.On("DeleteArticle", ctx, 1).Return(nil)
+		mockArticleCachingRepo.On("DeleteListArticles", ctx, []string{"John Doe"}).Return(nil)
+		mockArticleQueryRepo.On("GetArticleByID", 1).Return(restored, nil)
+
+		restoredArticle, err := articleService.RestoreArticle(ctx, &article.ArticleRestoreCommand{ID: 1, Actor: author})
+
+		assert.Nil(t, err)
+		assert.Equal(t, restored, restoredArticle)
+		mockArticleCommandRepo.AssertCalled(t, "RestoreArticle", 1)
+		mockArticleCachingRepo.AssertCalled(t, "DeleteArticle", ctx, 1)
+		mockArticleCachingRepo.AssertCalled(t, "DeleteListArticles", ctx, []string{"John Doe"})
+	})
+
+	t.Run("Article not found", func(t *testing.T) {
+		mockArticleCommandRepo := &MockArticleCommandRepository{}
+		mockArticleQueryRepo := &MockArticleQueryRepository{}
+		articleService := articleimpl.NewArticleService(mockArticleCommandRepo, mockArticleQueryRepo, &MockArticleCachingRepository{}, &MockArticleOutboxRepository{}, &MockArticleRevisionRepository{}, &MockArticleSlugRepository{}, &MockArticleTaxonomyRepository{})
+
+		mockArticleQueryRepo.On("GetDeletedArticleByID", 2).Return(nil, gorm.ErrRecordNotFound)
+
+		restoredArticle, err := articleService.RestoreArticle(ctx, &article.ArticleRestoreCommand{ID: 2, Actor: author})
+
+		assert.Nil(t, restoredArticle)
+		assert.Equal(t, article.ErrArticleNotFound, err)
+		mockArticleCommandRepo.AssertNotCalled(t, "RestoreArticle", 2)
+	})
+
+	for _, tt := range []struct {
+		name   string
+		status string
+		err    error
+	}{
+		{name: "Published article forbidden", status: article.StatusPublished, err: article.ErrRestoreForbidden},
+		{name: "Draft of another author not found", status: article.StatusDraft, err: article.ErrArticleNotFound},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			mockArticleCommandRepo := &MockArticleCommandRepository{}
+			mockArticleQueryRepo := &MockArticleQueryRepository{}
+			articleService := articleimpl.NewArticleService(mockArticleCommandRepo, mockArticleQueryRepo, &MockArticleCachingRepository{}, &MockArticleOutboxRepository{}, &MockArticleRevisionRepository{}, &MockArticleSlugRepository{}, &MockArticleTaxonomyRepository{})
+
+			mockArticleQueryRepo.On("GetDeletedArticleByID", 1).Return(&article.Article{ID: 1, Author: "John Doe", Status: tt.status}, nil)
+
+			_, err := articleService.RestoreArticle(ctx, &article.ArticleRestoreCommand{ID: 1, Actor: article.Actor{User: "Jim Doe"}})
+
+			assert.Equal(t, tt.err, err)
+			mockArticleCommandRepo.AssertNotCalled(t, "RestoreArticle", mock.Anything)
+		})
+	}
+}
+
+// TestPurgeArticle tests the PurgeArticle function.
+//
+// 1. Test the article is purged by an editor.
+// 2. Test its author may not purge it.
+// 3. Test the draft of another author is not found.
+func TestPurgeArticle(t *testing.T) {
+	ctx := context.Background()
+
+	for _, tt := range []struct {
+		name  string
+		actor article.Actor
+		err   error
+	}{
+		{name: "Article purged", actor: article.Actor{User: "jane", Role: article.RoleEditor}},
+		{name: "Author forbidden", actor: article.Actor{User: "John Doe"}, err: article.ErrPurgeForbidden},
+		{name: "Draft of another author not found", actor: article.Actor{User: "Jim Doe"}, err: article.ErrArticleNotFound},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			mockArticleCommandRepo := &MockArticleCommandRepository{}
+			mockArticleQueryRepo := &MockArticleQueryRepository{}
+			articleService := articleimpl.NewArticleService(mockArticleCommandRepo, mockArticleQueryRepo, &MockArticleCachingRepository{}, &MockArticleOutboxRepository{}, &MockArticleRevisionRepository{}, &MockArticleSlugRepository{}, &MockArticleTaxonomyRepository{})
+
+			mockArticleQueryRepo.On("GetDeletedArticleByID", 1).Return(&article.Article{ID: 1, Author: "John Doe", Status: article.StatusDraft}, nil)
+			mockArticleCommandRepo.On("PurgeArticle", 1).Return(nil)
+
+			err := articleService.PurgeArticle(ctx, &article.ArticlePurgeCommand{ID: 1, Actor: tt.actor})
+
+			assert.Equal(t, tt.err, err)
+			if tt.err == nil {
+				mockArticleCommandRepo.AssertCalled(t, "PurgeArticle", 1)
+			} else {
+				mockArticleCommandRepo.AssertNotCalled(t, "PurgeArticle", 1)
+			}
+		})
+	}
+}
+
+// TestPurgeDeletedArticles tests the PurgeDeletedArticles function.
+//
+// It checks that the retention is turned into a cutoff time and that the purged articles are counted.
+func TestPurgeDeletedArticles(t *testing.T) {
+	ctx := context.Background()
+
+	mockArticleCommandRepo := &MockArticleCommandRepository{}
+	articleService := articleimpl.NewArticleService(mockArticleCommandRepo, &MockArticleQueryRepository{}, &MockArticleCachingRepository{}, &MockArticleOutboxRepository{}, &MockArticleRevisionRepository{}, &MockArticleSlugRepository{}, &MockArticleTaxonomyRepository{})
+
+	retention := 24 * time.Hour
+	mockArticleCommandRepo.On("PurgeDeletedArticles", mock.MatchedBy(func(deletedBefore time.Time) bool {
+		return time.Since(deletedBefore) >= retention
+	})).Return([]int{3, 4}, nil)
+
+	purged, err := articleService.PurgeDeletedArticles(ctx, retention)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 2, purged)
+}
+
+// TestPublishScheduledArticles tests the PublishScheduledArticles function.
+//
+// It checks that the published articles are counted and evicted with every list.
+func TestPublishScheduledArticles(t *testing.T) {
+	ctx := context.Background()
+
+	mockArticleCommandRepo := &MockArticleCommandRepository{}
+	mockArticleCachingRepo := &MockArticleCachingRepository{}
+	articleService := articleimpl.NewArticleService(mockArticleCommandRepo, &MockArticleQueryRepository{}, mockArticleCachingRepo, &MockArticleOutboxRepository{}, &MockArticleRevisionRepository{}, &MockArticleSlugRepository{}, &MockArticleTaxonomyRepository{})
+
+	mockArticleCommandRepo.On("PublishScheduledArticles", mock.AnythingOfType("time.Time")).Return([]int{3, 4}, nil)
+	mockArticleCachingRepo.On("DeleteArticle", ctx, mock.AnythingOfType("int")).Return(nil)
+	mockArticleCachingRepo.On("DeleteListArticles", ctx, []string(nil)).Return(nil)
+
+	published, err := articleService.PublishScheduledArticles(ctx)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 2, published)
+	mockArticleCachingRepo.AssertCalled(t, "DeleteArticle", ctx, 3)
+	mockArticleCachingRepo.AssertCalled(t, "DeleteArticle", ctx, 4)
+	mockArticleCachingRepo.AssertCalled(t, "DeleteListArticles", ctx, []string(nil))
+}
+
+// TestArticleService_GetArticleBySlug tests the GetArticleBySlug function.
+//
+// The slug is resolved through the cache, or the slugs of the database on a miss,
+// and the article is then read by its ID.
+func TestArticleService_GetArticleBySlug(t *testing.T) {
+	ctx := context.TODO()
+
+	t.Run("Slug found in cache", func(t *testing.T) {
+		mockArticleQueryRepo := &MockArticleQueryRepository{}
+		mockArticleCachingRepo := &MockArticleCachingRepository{}
+		mockArticleSlugRepo := &MockArticleSlugRepository{}
+		articleService := articleimpl.NewArticleService(&MockArticleCommandRepository{}, mockArticleQueryRepo, mockArticleCachingRepo, &MockArticleOutboxRepository{}, &MockArticleRevisionRepository{}, mockArticleSlugRepo, &MockArticleTaxonomyRepository{})
+
+		mockArticleCachingRepo.On("GetArticleIDBySlug", ctx, "golang-testing").Return(1, nil)
+		mockArticleCachingRepo.On("GetArticleByID", ctx, 1).Return(&article.Article{ID: 1, Title: "Golang testing", Slug: "golang-testing"}, nil)
+
+		item, err := articleService.GetArticleBySlug(ctx, "golang-testing")
+		assert.NoError(t, err)
+		assert.Equal(t, 1, item.ID)
+		mockArticleSlugRepo.AssertNotCalled(t, "GetArticleSlug", mock.Anything)
+	})
+
+	t.Run("Slug not found in cache", func(t *testing.T) {
+		mockArticleQueryRepo := &MockArticleQueryRepository{}
+		mockArticleCachingRepo := &MockArticleCachingRepository{}
+		mockArticleSlugRepo := &MockArticleSlugRepository{}
+		articleService := articleimpl.NewArticleService(&MockArticleCommandRepository{}, mockArticleQueryRepo, mockArticleCachingRepo, &MockArticleOutboxRepository{}, &MockArticleRevisionRepository{}, mockArticleSlugRepo, &MockArticleTaxonomyRepository{})
+
+		cached := make(chan struct{})
+		mockArticleCachingRepo.On("GetArticleIDBySlug", ctx, "old-golang-testing").Return(0, article.ErrArticleCachingNotFound)
+		mockArticleSlugRepo.On("GetArticleSlug", "old-golang-testing").Return(&article.ArticleSlug{ArticleID: 1, Slug: "old-golang-testing"}, nil)
+		mockArticleCachingRepo.On("CreateArticleSlug", isLiveContext, "old-golang-testing", 1).Return(nil).Run(func(mock.Arguments) { close(cached) })
+		mockArticleCachingRepo.On("GetArticleByID", ctx, 1).Return(&article.Article{ID: 1, Title: "Golang testing", Slug: "golang-testing"}, nil)
+
+		item, err := articleService.GetArticleBySlug(ctx, "old-golang-testing")
+		assert.NoError(t, err)
+		// the article of a former slug has its current slug
+		assert.Equal(t, "golang-testing", item.Slug)
+
+		select {
+		case <-cached:
+		case <-time.After(time.Second):
+			t.Fatal("expected the slug to be cached")
+		}
+	})
+
+	t.Run("Slug not found in database", func(t *testing.T) {
+		mockArticleCachingRepo := &MockArticleCachingRepository{}
+		mockArticleSlugRepo := &MockArticleSlugRepository{}
+		articleService := articleimpl.NewArticleService(&MockArticleCommandRepository{}, &MockArticleQueryRepository{}, mockArticleCachingRepo, &MockArticleOutboxRepository{}, &MockArticleRevisionRepository{}, mockArticleSlugRepo, &MockArticleTaxonomyRepository{})
+
+		mockArticleCachingRepo.On("GetArticleIDBySlug", ctx, "unknown").Return(0, article.ErrArticleCachingNotFound)
+		mockArticleSlugRepo.On("GetArticleSlug", "unknown").Return(nil, gorm.ErrRecordNotFound)
+
+		item, err := articleService.GetArticleBySlug(ctx, "unknown")
+		assert.Nil(t, item)
+		assert.Equal(t, article.ErrArticleNotFound, err)
+		mockArticleCachingRepo.AssertNotCalled(t, "CreateArticleSlug", mock.Anything, mock.Anything, mock.Anything)
+	})
 }
 
 // TestArticleService_GetArticleByID tests the GetArticleByID method of the ArticleService struct.
@@ -124,7 +1018,7 @@ func TestArticleService_GetArticleByID(t *testing.T) {
 	mockArticleQueryRepo := &MockArticleQueryRepository{}
 	mockArticleCachingRepo := &MockArticleCachingRepository{}
 
-	articleService := articleimpl.NewArticleService(mockArticleCommandRepo, mockArticleQueryRepo, mockArticleCachingRepo)
+	articleService := articleimpl.NewArticleService(mockArticleCommandRepo, mockArticleQueryRepo, mockArticleCachingRepo, &MockArticleOutboxRepository{}, &MockArticleRevisionRepository{}, &MockArticleSlugRepository{}, &MockArticleTaxonomyRepository{})
 
 	t.Run("Article found in cache", func(t *testing.T) {
 		// Mock the GetArticleByID method of the articleCachingRepository to return a non-nil article
@@ -153,7 +1047,7 @@ func TestArticleService_GetArticleByID(t *testing.T) {
 		mockArticleQueryRepo.On("GetArticleByID", 2).Return(&article.Article{ID: 2, Title: "Test Article 2"}, nil)
 
 		// Mock the CreateArticle method of the articleCachingRepository
-		mockArticleCachingRepo.On("CreateArticle", ctx, &article.Article{ID: 2, Title: "Test Article 2"}).Return(nil)
+		mockArticleCachingRepo.On("CreateArticle", isLiveContext, &article.Article{ID: 2, Title: "Test Article 2"}).Return(nil)
 
 		// Call the GetArticleByID method
 		itemsArticle, err := articleService.GetArticleByID(ctx, 2)
@@ -183,6 +1077,9 @@ func TestArticleService_GetArticleByID(t *testing.T) {
 		// Mock the GetArticleByID method of the articleQueryRepository to return an error
 		mockArticleQueryRepo.On("GetArticleByID", 3).Return(nil, gorm.ErrRecordNotFound)
 
+		// Mock the CreateArticleNotFound method of the articleCachingRepository
+		mockArticleCachingRepo.On("CreateArticleNotFound", ctx, 3).Return(nil)
+
 		// Call the GetArticleByID method
 		itemsArticle, err := articleService.GetArticleByID(ctx, 3)
 
@@ -195,9 +1092,79 @@ func TestArticleService_GetArticleByID(t *testing.T) {
 
 		// Assert that the GetArticleByID method of the articleQueryRepository was called with the correct parameters
 		mockArticleQueryRepo.AssertCalled(t, "GetArticleByID", 3)
+
+		// Assert that the missing article was cached
+		mockArticleCachingRepo.AssertCalled(t, "CreateArticleNotFound", ctx, 3)
+	})
+
+	t.Run("Article cached as not found", func(t *testing.T) {
+		mockArticleCachingRepo.On("GetArticleByID", ctx, 4).Return(nil, article.ErrArticleNotFound)
+
+		itemsArticle, err := articleService.GetArticleByID(ctx, 4)
+
+		assert.Nil(t, itemsArticle)
+		assert.Equal(t, article.ErrArticleNotFound, err)
+		mockArticleQueryRepo.AssertNotCalled(t, "GetArticleByID", 4)
 	})
 }
 
+// TestArticleService_GetArticleByIDCoalesced tests the concurrent loads of the same article
+// reach the database once.
+func TestArticleService_GetArticleByIDCoalesced(t *testing.T) {
+	ctx := context.TODO()
+
+	mockArticleQueryRepo := &MockArticleQueryRepository{}
+	mockArticleCachingRepo := &MockArticleCachingRepository{}
+	articleService := articleimpl.NewArticleService(&MockArticleCommandRepository{}, mockArticleQueryRepo, mockArticleCachingRepo, &MockArticleOutboxRepository{}, &MockArticleRevisionRepository{}, &MockArticleSlugRepository{}, &MockArticleTaxonomyRepository{})
+
+	loaded := &article.Article{ID: 5, Title: "Test Article 5"}
+	release := make(chan time.Time)
+	var misses int32
+	mockArticleCachingRepo.On("GetArticleByID", ctx, 5).Run(func(mock.Arguments) {
+		atomic.AddInt32(&misses, 1)
+	}).Return(nil, article.ErrArticleCachingNotFound)
+	mockArticleCachingRepo.On("CreateArticle", isLiveContext, loaded).Return(nil)
+	mockArticleQueryRepo.On("GetArticleByID", 5).WaitUntil(release).Return(loaded, nil)
+
+	const callers = 10
+	var wg sync.WaitGroup
+	results := make([]*article.Article, callers)
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], _ = articleService.GetArticleByID(ctx, 5)
+		}(i)
+	}
+
+	// let the callers reach the load in flight before it completes
+	assert.Eventually(t, func() bool {
+		return atomic.LoadInt32(&misses) == callers
+	}, time.Second, time.Millisecond)
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	for _, result := range results {
+		assert.Equal(t, loaded, result)
+	}
+	mockArticleQueryRepo.AssertNumberOfCalls(t, "GetArticleByID", 1)
+}
+
+// TestArticleService_GetArticleByIDPanic tests a load that panics is not returned as a missing article.
+func TestArticleService_GetArticleByIDPanic(t *testing.T) {
+	ctx := context.TODO()
+
+	mockArticleQueryRepo := &MockArticleQueryRepository{}
+	mockArticleCachingRepo := &MockArticleCachingRepository{}
+	articleService := articleimpl.NewArticleService(&MockArticleCommandRepository{}, mockArticleQueryRepo, mockArticleCachingRepo, &MockArticleOutboxRepository{}, &MockArticleRevisionRepository{}, &MockArticleSlugRepository{}, &MockArticleTaxonomyRepository{})
+
+	mockArticleCachingRepo.On("GetArticleByID", ctx, 6).Return(nil, article.ErrArticleCachingNotFound)
+	mockArticleQueryRepo.On("GetArticleByID", 6).Run(func(mock.Arguments) { panic("connection lost") })
+
+	assert.Panics(t, func() { _, _ = articleService.GetArticleByID(ctx, 6) })
+}
+
 // TestGetListArticles is a test function for the GetListArticles method of the ArticleService.
 //
 // It tests various scenarios of querying the article repository and asserts the returned results and errors.
@@ -257,7 +1224,11 @@ func TestGetListArticles(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			// Create a new instance of the ArticleService
-			articleService := articleimpl.NewArticleService(mockArticleCommandRepo, mockArticleQueryRepo, mockArticleCachingRepo)
+			articleService := articleimpl.NewArticleService(mockArticleCommandRepo, mockArticleQueryRepo, mockArticleCachingRepo, &MockArticleOutboxRepository{}, &MockArticleRevisionRepository{}, &MockArticleSlugRepository{}, &MockArticleTaxonomyRepository{})
+
+			// Mock the GetListArticles method of the articleCachingRepository to miss
+			mockArticleCachingRepo.On("GetListArticles", ctx, tt.query).Return(nil, article.ErrArticleCachingNotFound)
+			mockArticleCachingRepo.On("CreateListArticles", mock.Anything, tt.query, tt.result).Return(nil)
 
 			// Mock the GetListArticles method of the articleQueryRepository to return a non-nil article
 			mockArticleQueryRepo.On("GetListArticles", tt.query).Return(tt.result, tt.err)
@@ -270,3 +1241,264 @@ func TestGetListArticles(t *testing.T) {
 		})
 	}
 }
+
+// TestGetListArticles_Cache tests the caching of the lists of articles.
+//
+// 1. Test a cached list is returned without searching.
+// 2. Test a searched list is cached.
+// 3. Test the pages of a cursor and degraded lists are not cached.
+func TestGetListArticles_Cache(t *testing.T) {
+	ctx := context.Background()
+	list := &article.ListArticleDTO{Articles: []article.Article{{ID: 1, Title: "Test Golang", Author: "cena"}}}
+
+	t.Run("Cached", func(t *testing.T) {
+		query := &article.ArticleQuery{Page: 1, Limit: 10, SortNewest: true}
+		mockArticleQueryRepo := &MockArticleQueryRepository{}
+		mockArticleCachingRepo := &MockArticleCachingRepository{}
+		mockArticleCachingRepo.On("GetListArticles", ctx, query).Return(list, nil)
+
+		articleService := articleimpl.NewArticleService(&MockArticleCommandRepository{}, mockArticleQueryRepo, mockArticleCachingRepo, &MockArticleOutboxRepository{}, &MockArticleRevisionRepository{}, &MockArticleSlugRepository{}, &MockArticleTaxonomyRepository{})
+		result, err := articleService.GetListArticles(ctx, query)
+
+		assert.NoError(t, err)
+		assert.Equal(t, list, result)
+		mockArticleQueryRepo.AssertNotCalled(t, "GetListArticles", mock.Anything)
+	})
+
+	t.Run("Searched and cached", func(t *testing.T) {
+		query := &article.ArticleQuery{Search: "golang"}
+		mockArticleQueryRepo := &MockArticleQueryRepository{}
+		mockArticleQueryRepo.On("GetListArticles", query).Return(list, nil)
+		mockArticleCachingRepo := &MockArticleCachingRepository{}
+		mockArticleCachingRepo.On("GetListArticles", mock.Anything, query).Return(nil, article.ErrArticleCachingNotFound)
+		cached := make(chan struct{})
+		// the list is cached after the request context is canceled
+		requestCtx, cancel := context.WithCancel(ctx)
+		mockArticleCachingRepo.On("CreateListArticles", isLiveContext, query, list).Run(func(mock.Arguments) { close(cached) }).Return(nil)
+
+		articleService := articleimpl.NewArticleService(&MockArticleCommandRepository{}, mockArticleQueryRepo, mockArticleCachingRepo, &MockArticleOutboxRepository{}, &MockArticleRevisionRepository{}, &MockArticleSlugRepository{}, &MockArticleTaxonomyRepository{})
+		result, err := articleService.GetListArticles(requestCtx, query)
+		cancel()
+
+		assert.NoError(t, err)
+		assert.Equal(t, list, result)
+		<-cached
+	})
+
+	t.Run("Not cached", func(t *testing.T) {
+		cursorQuery := &article.ArticleQuery{UseCursor: true}
+		degradedQuery := &article.ArticleQuery{Search: "golang"}
+		degraded := &article.ListArticleDTO{Articles: list.Articles, Degraded: true}
+		mockArticleQueryRepo := &MockArticleQueryRepository{}
+		mockArticleQueryRepo.On("GetListArticles", cursorQuery).Return(list, nil)
+		mockArticleQueryRepo.On("GetListArticles", degradedQuery).Return(degraded, nil)
+		mockArticleCachingRepo := &MockArticleCachingRepository{}
+		mockArticleCachingRepo.On("GetListArticles", ctx, degradedQuery).Return(nil, article.ErrArticleCachingNotFound)
+
+		articleService := articleimpl.NewArticleService(&MockArticleCommandRepository{}, mockArticleQueryRepo, mockArticleCachingRepo, &MockArticleOutboxRepository{}, &MockArticleRevisionRepository{}, &MockArticleSlugRepository{}, &MockArticleTaxonomyRepository{})
+
+		_, err := articleService.GetListArticles(ctx, cursorQuery)
+		assert.NoError(t, err)
+		result, err := articleService.GetListArticles(ctx, degradedQuery)
+		assert.NoError(t, err)
+		assert.True(t, result.Degraded)
+
+		mockArticleCachingRepo.AssertNotCalled(t, "GetListArticles", ctx, cursorQuery)
+		mockArticleCachingRepo.AssertNotCalled(t, "CreateListArticles", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+// TestGetRelatedArticles tests the GetRelatedArticles method of the ArticleService.
+//
+// Cached related articles are returned as is, otherwise the source article is loaded,
+// its related articles searched and cached, and a missing source article is reported.
+func TestGetRelatedArticles(t *testing.T) {
+	ctx := context.Background()
+	source := &article.Article{ID: 1, Title: "Golang generics", Author: "cena"}
+	related := &article.RelatedArticlesDTO{Articles: []article.Article{{ID: 2, Title: "Golang testing", Author: "cena"}}}
+
+	t.Run("Cached", func(t *testing.T) {
+		query := &article.ArticleRelatedQuery{ID: 1}
+		mockArticleQueryRepo := &MockArticleQueryRepository{}
+		mockArticleCachingRepo := &MockArticleCachingRepository{}
+		mockArticleCachingRepo.On("GetRelatedArticles", ctx, query).Return(related, nil)
+
+		articleService := articleimpl.NewArticleService(&MockArticleCommandRepository{}, mockArticleQueryRepo, mockArticleCachingRepo, &MockArticleOutboxRepository{}, &MockArticleRevisionRepository{}, &MockArticleSlugRepository{}, &MockArticleTaxonomyRepository{})
+		result, err := articleService.GetRelatedArticles(ctx, query)
+
+		assert.NoError(t, err)
+		assert.Equal(t, related, result)
+		mockArticleQueryRepo.AssertNotCalled(t, "GetRelatedArticles", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Searched", func(t *testing.T) {
+		query := &article.ArticleRelatedQuery{ID: 1, SameAuthor: true}
+		cached := make(chan struct{})
+		mockArticleQueryRepo := &MockArticleQueryRepository{}
+		mockArticleQueryRepo.On("GetRelatedArticles", query, source).Return(related, nil)
+		mockArticleCachingRepo := &MockArticleCachingRepository{}
+		mockArticleCachingRepo.On("GetRelatedArticles", ctx, query).Return(nil, article.ErrArticleCachingNotFound)
+		mockArticleCachingRepo.On("GetArticleByID", ctx, 1).Return(source, nil)
+		mockArticleCachingRepo.On("CreateRelatedArticles", isLiveContext, query, related).Return(nil).Run(func(args mock.Arguments) {
+			close(cached)
+		})
+
+		articleService := articleimpl.NewArticleService(&MockArticleCommandRepository{}, mockArticleQueryRepo, mockArticleCachingRepo, &MockArticleOutboxRepository{}, &MockArticleRevisionRepository{}, &MockArticleSlugRepository{}, &MockArticleTaxonomyRepository{})
+		result, err := articleService.GetRelatedArticles(ctx, query)
+
+		assert.NoError(t, err)
+		assert.Equal(t, related, result)
+		<-cached
+		mockArticleCachingRepo.AssertExpectations(t)
+	})
+
+	t.Run("Degraded", func(t *testing.T) {
+		query := &article.ArticleRelatedQuery{ID: 1, Limit: 3}
+		degraded := &article.RelatedArticlesDTO{Degraded: true}
+		mockArticleQueryRepo := &MockArticleQueryRepository{}
+		mockArticleQueryRepo.On("GetRelatedArticles", query, source).Return(degraded, nil)
+		mockArticleCachingRepo := &MockArticleCachingRepository{}
+		mockArticleCachingRepo.On("GetRelatedArticles", ctx, query).Return(nil, article.ErrArticleCachingNotFound)
+		mockArticleCachingRepo.On("GetArticleByID", ctx, 1).Return(source, nil)
+
+		articleService := articleimpl.NewArticleService(&MockArticleCommandRepository{}, mockArticleQueryRepo, mockArticleCachingRepo, &MockArticleOutboxRepository{}, &MockArticleRevisionRepository{}, &MockArticleSlugRepository{}, &MockArticleTaxonomyRepository{})
+		result, err := articleService.GetRelatedArticles(ctx, query)
+
+		assert.NoError(t, err)
+		assert.True(t, result.Degraded)
+		mockArticleCachingRepo.AssertNotCalled(t, "CreateRelatedArticles", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Article not found", func(t *testing.T) {
+		query := &article.ArticleRelatedQuery{ID: 9}
+		mockArticleQueryRepo := &MockArticleQueryRepository{}
+		mockArticleQueryRepo.On("GetArticleByID", 9).Return(nil, gorm.ErrRecordNotFound)
+		mockArticleCachingRepo := &MockArticleCachingRepository{}
+		mockArticleCachingRepo.On("GetRelatedArticles", ctx, query).Return(nil, article.ErrArticleCachingNotFound)
+		mockArticleCachingRepo.On("GetArticleByID", ctx, 9).Return(nil, article.ErrArticleCachingNotFound)
+		mockArticleCachingRepo.On("CreateArticleNotFound", ctx, 9).Return(nil)
+
+		articleService := articleimpl.NewArticleService(&MockArticleCommandRepository{}, mockArticleQueryRepo, mockArticleCachingRepo, &MockArticleOutboxRepository{}, &MockArticleRevisionRepository{}, &MockArticleSlugRepository{}, &MockArticleTaxonomyRepository{})
+		result, err := articleService.GetRelatedArticles(ctx, query)
+
+		assert.Equal(t, article.ErrArticleNotFound, err)
+		assert.Nil(t, result)
+	})
+}
+
+// TestArticleService_Tags tests the tag functions of the ArticleService.
+//
+// 1. Test the articles of a renamed tag are evicted with every list.
+// 2. Test a tag is merged into another existing tag.
+// 3. Test a missing tag is not found.
+func TestArticleService_Tags(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Tag renamed", func(t *testing.T) {
+		mockArticleCachingRepo := &MockArticleCachingRepository{}
+		mockArticleTaxonomyRepo := &MockArticleTaxonomyRepository{}
+		articleService := articleimpl.NewArticleService(&MockArticleCommandRepository{}, &MockArticleQueryRepository{}, mockArticleCachingRepo, &MockArticleOutboxRepository{}, &MockArticleRevisionRepository{}, &MockArticleSlugRepository{}, mockArticleTaxonomyRepo)
+
+		isRenamed := mock.MatchedBy(func(tag *article.Tag) bool { return tag.ID == 1 && tag.Name == "go lang" })
+		mockArticleTaxonomyRepo.On("GetTag", 1).Return(&article.Tag{ID: 1, Name: "golang"}, nil)
+		mockArticleTaxonomyRepo.On("UpdateTag", isRenamed).Return([]int{3, 4}, nil)
+		mockArticleCachingRepo.On("DeleteArticle", ctx, mock.AnythingOfType("int")).Return(nil)
+		mockArticleCachingRepo.On("DeleteListArticles", ctx, []string(nil)).Return(nil)
+
+		tag, err := articleService.UpdateTag(ctx, &article.TagCommand{ID: 1, Name: " Go  Lang"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "go lang", tag.Name)
+		mockArticleCachingRepo.AssertCalled(t, "DeleteArticle", ctx, 3)
+		mockArticleCachingRepo.AssertCalled(t, "DeleteArticle", ctx, 4)
+		mockArticleCachingRepo.AssertCalled(t, "DeleteListArticles", ctx, []string(nil))
+	})
+
+	t.Run("Tag merged", func(t *testing.T) {
+		mockArticleCachingRepo := &MockArticleCachingRepository{}
+		mockArticleTaxonomyRepo := &MockArticleTaxonomyRepository{}
+		articleService := articleimpl.NewArticleService(&MockArticleCommandRepository{}, &MockArticleQueryRepository{}, mockArticleCachingRepo, &MockArticleOutboxRepository{}, &MockArticleRevisionRepository{}, &MockArticleSlugRepository{}, mockArticleTaxonomyRepo)
+
+		tag, into := &article.Tag{ID: 2, Name: "go"}, &article.Tag{ID: 1, Name: "golang"}
+		mockArticleTaxonomyRepo.On("GetTag", 2).Return(tag, nil)
+		mockArticleTaxonomyRepo.On("GetTag", 1).Return(into, nil)
+		mockArticleTaxonomyRepo.On("MergeTag", tag, into).Return([]int{}, nil)
+
+		merged, err := articleService.MergeTag(ctx, &article.TagMergeCommand{ID: 2, Into: 1})
+
+		assert.NoError(t, err)
+		assert.Equal(t, into, merged)
+		mockArticleCachingRepo.AssertNotCalled(t, "DeleteListArticles", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Tag not found", func(t *testing.T) {
+		mockArticleTaxonomyRepo := &MockArticleTaxonomyRepository{}
+		articleService := articleimpl.NewArticleService(&MockArticleCommandRepository{}, &MockArticleQueryRepository{}, &MockArticleCachingRepository{}, &MockArticleOutboxRepository{}, &MockArticleRevisionRepository{}, &MockArticleSlugRepository{}, mockArticleTaxonomyRepo)
+
+		mockArticleTaxonomyRepo.On("GetTag", 9).Return(nil, gorm.ErrRecordNotFound)
+		mockArticleTaxonomyRepo.On("DeleteTag", 9).Return(nil, gorm.ErrRecordNotFound)
+
+		_, err := articleService.MergeTag(ctx, &article.TagMergeCommand{ID: 9, Into: 1})
+		assert.Equal(t, article.ErrTagNotFound, err)
+		assert.Equal(t, article.ErrTagNotFound, articleService.DeleteTag(ctx, 9))
+	})
+}
+
+// TestArticleService_Categories tests the category functions of the ArticleService.
+//
+// 1. Test a category is created under its parent.
+// 2. Test a category cannot be created under a missing parent.
+// 3. Test a moved category re-indexes its articles and cannot move under itself.
+func TestArticleService_Categories(t *testing.T) {
+	ctx := context.Background()
+	techID := 1
+
+	t.Run("Category created", func(t *testing.T) {
+		mockArticleTaxonomyRepo := &MockArticleTaxonomyRepository{}
+		articleService := articleimpl.NewArticleService(&MockArticleCommandRepository{}, &MockArticleQueryRepository{}, &MockArticleCachingRepository{}, &MockArticleOutboxRepository{}, &MockArticleRevisionRepository{}, &MockArticleSlugRepository{}, mockArticleTaxonomyRepo)
+
+		mockArticleTaxonomyRepo.On("GetCategory", 1).Return(&article.Category{ID: 1, Name: "Tech", Path: "tech"}, nil)
+		mockArticleTaxonomyRepo.On("CreateCategory", mock.Anything).Return(nil)
+
+		category, err := articleService.CreateCategory(ctx, &article.CategoryCommand{Name: "Go Lang", ParentID: &techID})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "tech/go-lang", category.Path)
+		assert.Equal(t, 1, *category.ParentID)
+	})
+
+	t.Run("Parent not found", func(t *testing.T) {
+		mockArticleTaxonomyRepo := &MockArticleTaxonomyRepository{}
+		articleService := articleimpl.NewArticleService(&MockArticleCommandRepository{}, &MockArticleQueryRepository{}, &MockArticleCachingRepository{}, &MockArticleOutboxRepository{}, &MockArticleRevisionRepository{}, &MockArticleSlugRepository{}, mockArticleTaxonomyRepo)
+
+		mockArticleTaxonomyRepo.On("GetCategory", 1).Return(nil, gorm.ErrRecordNotFound)
+
+		_, err := articleService.CreateCategory(ctx, &article.CategoryCommand{Name: "Golang", ParentID: &techID})
+
+		assert.Equal(t, article.ErrCategoryParentNotFound, err)
+		mockArticleTaxonomyRepo.AssertNotCalled(t, "CreateCategory", mock.Anything)
+	})
+
+	t.Run("Category moved", func(t *testing.T) {
+		mockArticleCachingRepo := &MockArticleCachingRepository{}
+		mockArticleTaxonomyRepo := &MockArticleTaxonomyRepository{}
+		articleService := articleimpl.NewArticleService(&MockArticleCommandRepository{}, &MockArticleQueryRepository{}, mockArticleCachingRepo, &MockArticleOutboxRepository{}, &MockArticleRevisionRepository{}, &MockArticleSlugRepository{}, mockArticleTaxonomyRepo)
+
+		isMoved := mock.MatchedBy(func(category *article.Category) bool { return category.Path == "tech/golang" })
+		mockArticleTaxonomyRepo.On("GetCategory", 1).Return(&article.Category{ID: 1, Name: "Tech", Path: "tech"}, nil)
+		mockArticleTaxonomyRepo.On("GetCategory", 2).Return(&article.Category{ID: 2, Name: "Golang", Path: "golang"}, nil)
+		mockArticleTaxonomyRepo.On("GetCategory", 3).Return(&article.Category{ID: 3, ParentID: &techID, Name: "Web", Path: "tech/golang/web"}, nil)
+		mockArticleTaxonomyRepo.On("UpdateCategory", isMoved, "golang").Return([]int{8}, nil)
+		mockArticleCachingRepo.On("DeleteArticle", ctx, 8).Return(nil)
+		mockArticleCachingRepo.On("DeleteListArticles", ctx, []string(nil)).Return(nil)
+
+		category, err := articleService.UpdateCategory(ctx, &article.CategoryCommand{ID: 2, Name: "Golang", ParentID: &techID})
+		assert.NoError(t, err)
+		assert.Equal(t, "tech/golang", category.Path)
+		mockArticleCachingRepo.AssertCalled(t, "DeleteArticle", ctx, 8)
+
+		thirdID := 3
+		_, err = articleService.UpdateCategory(ctx, &article.CategoryCommand{ID: 1, Name: "Tech", ParentID: &thirdID})
+		assert.Equal(t, article.ErrCategoryCycle, err)
+	})
+}
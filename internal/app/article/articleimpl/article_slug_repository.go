@@ -0,0 +1,107 @@
+package articleimpl
+
+import (
+	"fmt"
+
+	"github.com/undercode99/article_service/internal/app/article"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// slugAttempts is the number of times a slug is picked again when another article takes it first.
+	slugAttempts = 5
+	// slugMigrationBatchSize is the number of articles given a slug per transaction by MigrateArticleSlugs.
+	slugMigrationBatchSize = 100
+)
+
+type ArticleSlugRepository struct {
+	db *gorm.DB
+}
+
+func NewArticleSlugRepository(db *gorm.DB) article.ArticleSlugRepository {
+	return &ArticleSlugRepository{
+		db: db,
+	}
+}
+
+// GetArticleSlug returns a current or former slug of an article.
+//
+// gorm.ErrRecordNotFound is returned if no article ever had the slug.
+func (r *ArticleSlugRepository) GetArticleSlug(slug string) (*article.ArticleSlug, error) {
+	var item article.ArticleSlug
+	err := r.db.Where("slug = ?", slug).First(&item).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &item, nil
+}
+
+// MigrateArticleSlugs gives a slug to the articles written before the slugs, deleted ones included.
+func MigrateArticleSlugs(db *gorm.DB) error {
+	for {
+		var items []article.Article
+		err := db.Unscoped().Select("id", "title").Where("slug = ''").Order("id").Limit(slugMigrationBatchSize).Find(&items).Error
+		if err != nil || len(items) == 0 {
+			return err
+		}
+
+		err = db.Transaction(func(tx *gorm.DB) error {
+			for i := range items {
+				if err := reserveArticleSlug(tx, &items[i]); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// reserveArticleSlug sets the slug of the article to a unique slug of its title.
+//
+// The slug of the title gets the first free numeric suffix from 2 if other articles
+// have it, a former slug of the article is taken back. The slug is written in the
+// transaction, along the slugs of the article.
+// article.ErrSlugUnavailable is returned if other articles keep taking the slug first.
+func reserveArticleSlug(tx *gorm.DB, item *article.Article) error {
+	base := article.NewSlug(item.Title)
+
+	for attempt := 0; attempt < slugAttempts; attempt++ {
+		// the slugs are lowercase letters, digits and hyphens, LIKE needs no escaping
+		var taken []article.ArticleSlug
+		err := tx.Select("article_id", "slug").Where("slug = ? OR slug LIKE ?", base, base+"-%").Find(&taken).Error
+		if err != nil {
+			return err
+		}
+
+		owners := make(map[string]int, len(taken))
+		for _, t := range taken {
+			owners[t.Slug] = t.ArticleID
+		}
+
+		slug := base
+		for n := 2; owners[slug] != 0 && owners[slug] != item.ID; n++ {
+			slug = fmt.Sprintf("%s-%d", base, n)
+		}
+
+		if owners[slug] == 0 {
+			created := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(article.NewArticleSlug(item.ID, slug))
+			if created.Error != nil {
+				return created.Error
+			}
+			// another article took the slug in between
+			if created.RowsAffected == 0 {
+				continue
+			}
+		}
+
+		item.Slug = slug
+		return tx.Unscoped().Model(&article.Article{ID: item.ID}).Update("slug", slug).Error
+	}
+
+	return article.ErrSlugUnavailable
+}
@@ -0,0 +1,480 @@
+package articleimpl
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/undercode99/article_service/internal/app/article"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ArticleTaxonomyRepository struct {
+	db *gorm.DB
+}
+
+func NewArticleTaxonomyRepository(db *gorm.DB) article.ArticleTaxonomyRepository {
+	return &ArticleTaxonomyRepository{
+		db: db,
+	}
+}
+
+// GetTags returns every tag sorted by name.
+func (r *ArticleTaxonomyRepository) GetTags() ([]article.Tag, error) {
+	tags := []article.Tag{}
+	if err := r.db.Order("name").Find(&tags).Error; err != nil {
+		return nil, err
+	}
+
+	return tags, nil
+}
+
+// GetTag returns a tag by its ID.
+//
+// gorm.ErrRecordNotFound is returned if no tag with the given ID exists.
+func (r *ArticleTaxonomyRepository) GetTag(id int) (*article.Tag, error) {
+	var tag article.Tag
+	if err := r.db.First(&tag, id).Error; err != nil {
+		return nil, err
+	}
+
+	return &tag, nil
+}
+
+// CreateTag creates a tag.
+//
+// article.ErrTagExists is returned if a tag already has the name.
+func (r *ArticleTaxonomyRepository) CreateTag(tag *article.Tag) error {
+	created := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(tag)
+	if created.Error != nil {
+		return created.Error
+	}
+
+	if created.RowsAffected == 0 {
+		return article.ErrTagExists
+	}
+	return nil
+}
+
+// UpdateTag renames a tag.
+//
+// It returns the IDs of the live articles of the tag, their versions are incremented
+// and their documents replaced through the outbox.
+// gorm.ErrRecordNotFound is returned if no tag with the given ID exists, and
+// article.ErrTagExists if another tag has the name, the tags are then merged instead.
+func (r *ArticleTaxonomyRepository) UpdateTag(tag *article.Tag) ([]int, error) {
+	var ids []int
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&article.Tag{}).Where("name = ? AND id <> ?", tag.Name, tag.ID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return article.ErrTagExists
+		}
+
+		updated := tx.Model(&article.Tag{ID: tag.ID}).Update("name", tag.Name)
+		if updated.Error != nil {
+			return updated.Error
+		}
+		if updated.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		var err error
+		if ids, err = tagArticleIDs(tx, tag.ID); err != nil {
+			return err
+		}
+		return touchArticles(tx, ids, fmt.Sprintf("Tag renamed to %s", tag.Name))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+// MergeTag moves the articles of a tag to another tag and deletes the tag.
+//
+// It returns the IDs of the live articles of the merged tag, their versions are incremented
+// and their documents replaced through the outbox.
+// gorm.ErrRecordNotFound is returned if the merged tag no longer exists.
+func (r *ArticleTaxonomyRepository) MergeTag(tag *article.Tag, into *article.Tag) ([]int, error) {
+	var ids []int
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if ids, err = tagArticleIDs(tx, tag.ID); err != nil {
+			return err
+		}
+
+		// the articles already having both tags keep their link to the other tag
+		err = tx.Exec(
+			"INSERT INTO article_tags (article_id, tag_id) SELECT article_id, ? FROM article_tags WHERE tag_id = ? ON CONFLICT DO NOTHING",
+			into.ID, tag.ID,
+		).Error
+		if err != nil {
+			return err
+		}
+
+		if err := deleteTag(tx, tag.ID); err != nil {
+			return err
+		}
+		return touchArticles(tx, ids, fmt.Sprintf("Tag %s merged into %s", tag.Name, into.Name))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+// DeleteTag deletes a tag and removes it from its articles.
+//
+// It returns the IDs of the live articles of the tag, their versions are incremented
+// and their documents replaced through the outbox.
+// gorm.ErrRecordNotFound is returned if no tag with the given ID exists.
+func (r *ArticleTaxonomyRepository) DeleteTag(id int) ([]int, error) {
+	var ids []int
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if ids, err = tagArticleIDs(tx, id); err != nil {
+			return err
+		}
+
+		if err := deleteTag(tx, id); err != nil {
+			return err
+		}
+		return touchArticles(tx, ids, "Tag deleted")
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+// deleteTag deletes a tag and its links to the articles.
+//
+// gorm.ErrRecordNotFound is returned if no tag with the given ID exists.
+func deleteTag(tx *gorm.DB, id int) error {
+	if err := tx.Where("tag_id = ?", id).Delete(&article.ArticleTag{}).Error; err != nil {
+		return err
+	}
+
+	deleted := tx.Delete(&article.Tag{}, id)
+	if deleted.Error != nil {
+		return deleted.Error
+	}
+	if deleted.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// tagArticleIDs returns the IDs of the live articles of a tag.
+func tagArticleIDs(tx *gorm.DB, tagID int) ([]int, error) {
+	var ids []int
+	err := tx.Model(&article.Article{}).
+		Where("id IN (?)", tx.Model(&article.ArticleTag{}).Select("article_id").Where("tag_id = ?", tagID)).
+		Pluck("id", &ids).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+// GetCategories returns every category sorted by path.
+func (r *ArticleTaxonomyRepository) GetCategories() ([]article.Category, error) {
+	categories := []article.Category{}
+	if err := r.db.Order("path").Find(&categories).Error; err != nil {
+		return nil, err
+	}
+
+	return categories, nil
+}
+
+// GetCategory returns a category by its ID.
+//
+// gorm.ErrRecordNotFound is returned if no category with the given ID exists.
+func (r *ArticleTaxonomyRepository) GetCategory(id int) (*article.Category, error) {
+	var category article.Category
+	if err := r.db.First(&category, id).Error; err != nil {
+		return nil, err
+	}
+
+	return &category, nil
+}
+
+// CreateCategory creates a category.
+//
+// article.ErrCategoryExists is returned if a category already has the path.
+func (r *ArticleTaxonomyRepository) CreateCategory(category *article.Category) error {
+	created := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(category)
+	if created.Error != nil {
+		return created.Error
+	}
+
+	if created.RowsAffected == 0 {
+		return article.ErrCategoryExists
+	}
+	return nil
+}
+
+// UpdateCategory saves the name, parent and path of a category.
+//
+// The paths of the subcategories follow the new path of the category. It returns the IDs
+// of the live articles of the category and its subcategories if the path changed, their
+// versions are incremented and their documents replaced through the outbox.
+// gorm.ErrRecordNotFound is returned if no category with the given ID exists, and
+// article.ErrCategoryExists if another category has the path.
+func (r *ArticleTaxonomyRepository) UpdateCategory(category *article.Category, previousPath string) ([]int, error) {
+	var ids []int
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&article.Category{}).Where("path = ? AND id <> ?", category.Path, category.ID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return article.ErrCategoryExists
+		}
+
+		updated := tx.Model(&article.Category{ID: category.ID}).Updates(map[string]interface{}{
+			"name":      category.Name,
+			"parent_id": category.ParentID,
+			"path":      category.Path,
+			"updated":   category.Updated,
+		})
+		if updated.Error != nil {
+			return updated.Error
+		}
+		if updated.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		// a new name with the same slug changes no path
+		if category.Path == previousPath {
+			return nil
+		}
+
+		// the paths are slugs separated by slashes, LIKE needs no escaping
+		err := tx.Model(&article.Category{}).
+			Where("path LIKE ?", previousPath+"/%").
+			Update("path", gorm.Expr("? || substr(path, ?)", category.Path, len(previousPath)+1)).Error
+		if err != nil {
+			return err
+		}
+
+		if ids, err = categoryArticleIDs(tx, category.Path); err != nil {
+			return err
+		}
+		return touchArticles(tx, ids, fmt.Sprintf("Category moved to %s", category.Path))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+// DeleteCategory deletes a category without subcategories nor articles.
+//
+// The deleted articles of the category count until they are purged.
+// gorm.ErrRecordNotFound is returned if no category with the given ID exists, and
+// article.ErrCategoryNotEmpty if it has subcategories or articles.
+func (r *ArticleTaxonomyRepository) DeleteCategory(id int) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var children, articles int64
+		if err := tx.Model(&article.Category{}).Where("parent_id = ?", id).Count(&children).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Model(&article.Article{}).Where("category_id = ?", id).Count(&articles).Error; err != nil {
+			return err
+		}
+		if children > 0 || articles > 0 {
+			return article.ErrCategoryNotEmpty
+		}
+
+		deleted := tx.Delete(&article.Category{}, id)
+		if deleted.Error != nil {
+			return deleted.Error
+		}
+		if deleted.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+}
+
+// categoryArticleIDs returns the IDs of the live articles of the category with the path and of its subcategories.
+func categoryArticleIDs(tx *gorm.DB, path string) ([]int, error) {
+	var ids []int
+	err := tx.Model(&article.Article{}).
+		Where("category_id IN (?)", categoryIDsQuery(tx, path)).
+		Pluck("id", &ids).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+// categoryIDsQuery returns the subquery of the IDs of the category with the path and of its subcategories.
+func categoryIDsQuery(tx *gorm.DB, path string) *gorm.DB {
+	return tx.Model(&article.Category{}).Select("id").Where("path = ? OR path LIKE ?", path, path+"/%")
+}
+
+// touchArticles saves a change of the tags or category of the articles like an update.
+//
+// Their version is incremented with a revision of their unchanged title and body noting
+// the change, so their ETags change and a rebuild of the index catches up with them,
+// and their documents are replaced through the outbox.
+func touchArticles(tx *gorm.DB, ids []int, note string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	err := tx.Model(&article.Article{}).Where("id IN ?", ids).Updates(map[string]interface{}{
+		"updated": now,
+		"version": gorm.Expr("version + 1"),
+	}).Error
+	if err != nil {
+		return err
+	}
+
+	if err := createArticleRevisions(tx, ids, article.TaxonomyEditor, note, now); err != nil {
+		return err
+	}
+	return createArticleOutboxes(tx, ids, article.OutboxActionUpdated)
+}
+
+// createArticleOutboxes writes an outbox record with the action for each of the articles.
+func createArticleOutboxes(tx *gorm.DB, ids []int, action string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	outboxes := make([]*article.ArticleOutbox, len(ids))
+	for i, id := range ids {
+		outboxes[i] = article.NewArticleOutbox(id, action)
+	}
+	return tx.Create(outboxes).Error
+}
+
+// createArticleTags links the article to its tags, the missing tags are created.
+func createArticleTags(tx *gorm.DB, item *article.Article) error {
+	if len(item.Tags) == 0 {
+		return nil
+	}
+
+	tags := make([]*article.Tag, len(item.Tags))
+	for i, name := range item.Tags {
+		tags[i] = article.NewTag(name)
+	}
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(tags).Error; err != nil {
+		return err
+	}
+
+	// the existing tags are not returned by the insert, every ID is read back
+	var tagIDs []int
+	if err := tx.Model(&article.Tag{}).Where("name IN ?", item.Tags).Pluck("id", &tagIDs).Error; err != nil {
+		return err
+	}
+
+	links := make([]article.ArticleTag, len(tagIDs))
+	for i, tagID := range tagIDs {
+		links[i] = article.ArticleTag{ArticleID: item.ID, TagID: tagID}
+	}
+	return tx.Create(&links).Error
+}
+
+// replaceArticleTags links the article to its tags in place of its previous tags.
+func replaceArticleTags(tx *gorm.DB, item *article.Article) error {
+	if err := tx.Where("article_id = ?", item.ID).Delete(&article.ArticleTag{}).Error; err != nil {
+		return err
+	}
+
+	return createArticleTags(tx, item)
+}
+
+// setArticleCategory sets the path of the category of the article.
+//
+// The category is locked until the end of the transaction, so it is not deleted
+// while the article is filed under it.
+// article.ErrCategoryNotFound is returned if the category does not exist.
+func setArticleCategory(tx *gorm.DB, item *article.Article) error {
+	item.Category = ""
+	if item.CategoryID == nil {
+		return nil
+	}
+
+	var paths []string
+	err := tx.Model(&article.Category{}).
+		Clauses(clause.Locking{Strength: "SHARE"}).
+		Where("id = ?", *item.CategoryID).
+		Pluck("path", &paths).Error
+	if err != nil {
+		return err
+	}
+
+	if len(paths) == 0 {
+		return article.ErrCategoryNotFound
+	}
+	item.Category = paths[0]
+	return nil
+}
+
+// loadArticleTaxonomy sets the tags and the path of the category of the articles.
+func loadArticleTaxonomy(db *gorm.DB, items []article.Article) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	ids := make([]int, len(items))
+	var categoryIDs []int
+	for i := range items {
+		ids[i] = items[i].ID
+		if items[i].CategoryID != nil {
+			categoryIDs = append(categoryIDs, *items[i].CategoryID)
+		}
+	}
+
+	var links []struct {
+		ArticleID int
+		Name      string
+	}
+	err := db.Model(&article.ArticleTag{}).
+		Select("article_tags.article_id, tags.name").
+		Joins("JOIN tags ON tags.id = article_tags.tag_id").
+		Where("article_tags.article_id IN ?", ids).
+		Order("tags.name").
+		Scan(&links).Error
+	if err != nil {
+		return err
+	}
+
+	tags := make(map[int][]string, len(items))
+	for _, link := range links {
+		tags[link.ArticleID] = append(tags[link.ArticleID], link.Name)
+	}
+
+	paths := make(map[int]string, len(categoryIDs))
+	if len(categoryIDs) > 0 {
+		var categories []article.Category
+		if err := db.Select("id", "path").Where("id IN ?", categoryIDs).Find(&categories).Error; err != nil {
+			return err
+		}
+		for _, category := range categories {
+			paths[category.ID] = category.Path
+		}
+	}
+
+	for i := range items {
+		items[i].Tags = tags[items[i].ID]
+		if items[i].CategoryID != nil {
+			items[i].Category = paths[*items[i].CategoryID]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,209 @@
+package articleimpl_test
+
+import (
+	"database/sql/driver"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/undercode99/article_service/internal/app/article"
+	"github.com/undercode99/article_service/internal/app/article/articleimpl"
+	"gorm.io/gorm"
+)
+
+// expectArticlesTouched expects the versions of the articles to be incremented with a revision noting the change.
+func expectArticlesTouched(mock sqlmock.Sqlmock, note string, ids ...driver.Value) {
+	mock.ExpectExec("UPDATE \"articles\" SET \"updated\"=(.+),\"version\"=version \\+ 1 WHERE id IN (.+)").
+		WithArgs(append([]driver.Value{sqlmock.AnyArg()}, ids...)...).
+		WillReturnResult(sqlmock.NewResult(0, int64(len(ids))))
+	mock.ExpectExec("INSERT INTO article_revisions (.+) SELECT id, version, title, body, (.+) FROM articles WHERE id IN (.+)").
+		WithArgs(append([]driver.Value{article.TaxonomyEditor, note, sqlmock.AnyArg()}, ids...)...).
+		WillReturnResult(sqlmock.NewResult(0, int64(len(ids))))
+}
+
+// TestArticleTaxonomyRepository_CreateTag tests a tag name cannot be taken twice.
+func TestArticleTaxonomyRepository_CreateTag(t *testing.T) {
+	db, mock := dbMockConnection()
+	repo := articleimpl.NewArticleTaxonomyRepository(db)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT INTO \"tags\" (.+) ON CONFLICT DO NOTHING RETURNING \"id\"").
+		WithArgs("golang", sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectCommit()
+
+	err := repo.CreateTag(article.NewTag("Golang"))
+	assert.Equal(t, article.ErrTagExists, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestArticleTaxonomyRepository_UpdateTag tests the UpdateTag function.
+//
+// 1. Test the live articles of the renamed tag get a new version and are re-indexed.
+// 2. Test a tag cannot be renamed to the name of another tag.
+func TestArticleTaxonomyRepository_UpdateTag(t *testing.T) {
+	t.Run("Tag renamed", func(t *testing.T) {
+		db, mock := dbMockConnection()
+		repo := articleimpl.NewArticleTaxonomyRepository(db)
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT count\\(\\*\\) FROM \"tags\" WHERE name = (.+) AND id <> (.+)").
+			WithArgs("go", 1).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+		mock.ExpectExec("UPDATE \"tags\" SET \"name\"=(.+) WHERE \"id\" = (.+)").
+			WithArgs("go", 1).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery("SELECT \"id\" FROM \"articles\" WHERE id IN \\(SELECT \"article_id\" FROM \"article_tags\" WHERE tag_id = (.+)\\) AND \"articles\".\"deleted_at\" IS NULL").
+			WithArgs(1).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3).AddRow(4))
+		expectArticlesTouched(mock, "Tag renamed to go", 3, 4)
+		mock.ExpectQuery("INSERT INTO \"article_outboxes\" (.+)").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1).AddRow(2))
+		mock.ExpectCommit()
+
+		ids, err := repo.UpdateTag(&article.Tag{ID: 1, Name: "go"})
+		assert.NoError(t, err)
+		assert.Equal(t, []int{3, 4}, ids)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Name taken", func(t *testing.T) {
+		db, mock := dbMockConnection()
+		repo := articleimpl.NewArticleTaxonomyRepository(db)
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT count\\(\\*\\) FROM \"tags\" WHERE name = (.+) AND id <> (.+)").
+			WithArgs("rust", 1).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+		mock.ExpectRollback()
+
+		_, err := repo.UpdateTag(&article.Tag{ID: 1, Name: "rust"})
+		assert.Equal(t, article.ErrTagExists, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+// TestArticleTaxonomyRepository_MergeTag tests the articles of the merged tag are moved to the other tag,
+// get a new version and are re-indexed.
+func TestArticleTaxonomyRepository_MergeTag(t *testing.T) {
+	db, mock := dbMockConnection()
+	repo := articleimpl.NewArticleTaxonomyRepository(db)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT \"id\" FROM \"articles\" WHERE id IN \\(SELECT \"article_id\" FROM \"article_tags\" WHERE tag_id = (.+)\\)").
+		WithArgs(2).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(5))
+	mock.ExpectExec("INSERT INTO article_tags \\(article_id, tag_id\\) SELECT article_id, (.+) FROM article_tags WHERE tag_id = (.+) ON CONFLICT DO NOTHING").
+		WithArgs(1, 2).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DELETE FROM \"article_tags\" WHERE tag_id = (.+)").
+		WithArgs(2).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DELETE FROM \"tags\" WHERE \"tags\".\"id\" = (.+)").
+		WithArgs(2).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	expectArticlesTouched(mock, "Tag go merged into golang", 5)
+	expectOutboxInsert(mock, 5, article.OutboxActionUpdated)
+	mock.ExpectCommit()
+
+	ids, err := repo.MergeTag(&article.Tag{ID: 2, Name: "go"}, &article.Tag{ID: 1, Name: "golang"})
+	assert.NoError(t, err)
+	assert.Equal(t, []int{5}, ids)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestArticleTaxonomyRepository_DeleteTag tests a missing tag is not found.
+func TestArticleTaxonomyRepository_DeleteTag(t *testing.T) {
+	db, mock := dbMockConnection()
+	repo := articleimpl.NewArticleTaxonomyRepository(db)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT \"id\" FROM \"articles\" WHERE id IN \\(SELECT \"article_id\" FROM \"article_tags\" WHERE tag_id = (.+)\\)").
+		WithArgs(9).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectExec("DELETE FROM \"article_tags\" WHERE tag_id = (.+)").
+		WithArgs(9).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("DELETE FROM \"tags\" WHERE \"tags\".\"id\" = (.+)").
+		WithArgs(9).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	_, err := repo.DeleteTag(9)
+	assert.Equal(t, gorm.ErrRecordNotFound, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestArticleTaxonomyRepository_UpdateCategory tests the UpdateCategory function.
+//
+// 1. Test the subcategories follow a moved category and the articles of the subtree are re-indexed.
+// 2. Test a new name with the same slug changes no path.
+func TestArticleTaxonomyRepository_UpdateCategory(t *testing.T) {
+	parentID := 1
+
+	t.Run("Category moved", func(t *testing.T) {
+		db, mock := dbMockConnection()
+		repo := articleimpl.NewArticleTaxonomyRepository(db)
+		category := &article.Category{ID: 2, ParentID: &parentID, Name: "Golang", Path: "tech/golang"}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT count\\(\\*\\) FROM \"categories\" WHERE path = (.+) AND id <> (.+)").
+			WithArgs("tech/golang", 2).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+		mock.ExpectExec("UPDATE \"categories\" SET \"name\"=(.+),\"parent_id\"=(.+),\"path\"=(.+),\"updated\"=(.+) WHERE \"id\" = (.+)").
+			WithArgs("Golang", &parentID, "tech/golang", sqlmock.AnyArg(), 2).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec("UPDATE \"categories\" SET \"path\"=(.+) \\|\\| substr\\(path, (.+)\\) WHERE path LIKE (.+)").
+			WithArgs("tech/golang", 7, "golang/%").
+			WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectQuery("SELECT \"id\" FROM \"articles\" WHERE category_id IN \\(SELECT \"id\" FROM \"categories\" WHERE path = (.+) OR path LIKE (.+)\\) AND \"articles\".\"deleted_at\" IS NULL").
+			WithArgs("tech/golang", "tech/golang/%").
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(8))
+		expectArticlesTouched(mock, "Category moved to tech/golang", 8)
+		expectOutboxInsert(mock, 8, article.OutboxActionUpdated)
+		mock.ExpectCommit()
+
+		ids, err := repo.UpdateCategory(category, "golang")
+		assert.NoError(t, err)
+		assert.Equal(t, []int{8}, ids)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Path unchanged", func(t *testing.T) {
+		db, mock := dbMockConnection()
+		repo := articleimpl.NewArticleTaxonomyRepository(db)
+		category := &article.Category{ID: 2, ParentID: &parentID, Name: "GoLang", Path: "tech/golang"}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT count\\(\\*\\) FROM \"categories\" WHERE path = (.+) AND id <> (.+)").
+			WithArgs("tech/golang", 2).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+		mock.ExpectExec("UPDATE \"categories\" SET (.+) WHERE \"id\" = (.+)").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		ids, err := repo.UpdateCategory(category, "tech/golang")
+		assert.NoError(t, err)
+		assert.Empty(t, ids)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+// TestArticleTaxonomyRepository_DeleteCategory tests a category with articles, deleted ones included, is kept.
+func TestArticleTaxonomyRepository_DeleteCategory(t *testing.T) {
+	db, mock := dbMockConnection()
+	repo := articleimpl.NewArticleTaxonomyRepository(db)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT count\\(\\*\\) FROM \"categories\" WHERE parent_id = (.+)").
+		WithArgs(2).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectQuery("SELECT count\\(\\*\\) FROM \"articles\" WHERE category_id = (.+)$").
+		WithArgs(2).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectRollback()
+
+	err := repo.DeleteCategory(2)
+	assert.Equal(t, article.ErrCategoryNotEmpty, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
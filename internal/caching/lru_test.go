@@ -0,0 +1,56 @@
+package caching_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/undercode99/article_service/internal/caching"
+)
+
+// TestLRU tests the LRU cache.
+//
+// 1. Test the least recently used entry is dropped once the cache is full.
+// 2. Test the entries expire after the TTL.
+// 3. Test an entry is dropped by Delete.
+func TestLRU(t *testing.T) {
+	now := time.Date(2023, 7, 1, 0, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+
+	t.Run("Least recently used dropped", func(t *testing.T) {
+		cache := caching.NewLRUWithClock[int, string](2, time.Minute, clock)
+		cache.Set(1, "one")
+		cache.Set(2, "two")
+
+		// use 1 so 2 is the least recently used
+		_, ok := cache.Get(1)
+		assert.True(t, ok)
+		cache.Set(3, "three")
+
+		_, ok = cache.Get(2)
+		assert.False(t, ok)
+		value, ok := cache.Get(1)
+		assert.True(t, ok)
+		assert.Equal(t, "one", value)
+		assert.Equal(t, 2, cache.Len())
+	})
+
+	t.Run("Entry expired", func(t *testing.T) {
+		cache := caching.NewLRUWithClock[int, string](2, time.Minute, clock)
+		cache.Set(1, "one")
+
+		now = now.Add(time.Minute)
+		_, ok := cache.Get(1)
+		assert.False(t, ok)
+		assert.Equal(t, 0, cache.Len())
+	})
+
+	t.Run("Entry deleted", func(t *testing.T) {
+		cache := caching.NewLRUWithClock[int, string](2, time.Minute, clock)
+		cache.Set(1, "one")
+		cache.Delete(1)
+
+		_, ok := cache.Get(1)
+		assert.False(t, ok)
+	})
+}
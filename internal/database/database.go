@@ -23,6 +23,19 @@ func NewDatabase(cfg *config.Config) *gorm.DB {
 	return db
 }
 
+// MigrateDatabase migrates the tables of the articles, their tags and the category tree.
+//
+// The articles written before the revisions were recorded get a first revision
+// of their current content, so their changes can be reverted. The articles written
+// before the slugs are given one by articleimpl.MigrateArticleSlugs.
 func MigrateDatabase(db *gorm.DB) error {
-	return db.AutoMigrate(&article.Article{})
+	err := db.AutoMigrate(&article.Article{}, &article.ArticleOutbox{}, &article.ArticleRevision{}, &article.ArticleSlug{},
+		&article.Tag{}, &article.ArticleTag{}, &article.Category{})
+	if err != nil {
+		return err
+	}
+
+	return db.Exec(`INSERT INTO article_revisions (article_id, revision, title, body, editor, note, created)
+		SELECT id, version, title, body, '', 'Recorded before the revision history', updated FROM articles
+		WHERE NOT EXISTS (SELECT 1 FROM article_revisions WHERE article_revisions.article_id = articles.id)`).Error
 }
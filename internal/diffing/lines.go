@@ -0,0 +1,168 @@
+package diffing
+
+import "strings"
+
+// Op is the operation of a line of a diff.
+type Op string
+
+const (
+	OpEqual  Op = "equal"
+	OpInsert Op = "insert"
+	OpDelete Op = "delete"
+)
+
+// maxEditDistance is the number of edits myers searches for at most.
+//
+// Its trace grows with the square of the edits, beyond the limit the lines
+// that differ are replaced as a whole instead.
+const maxEditDistance = 1000
+
+// Line is a line of a diff, kept, inserted or deleted.
+type Line struct {
+	Op   Op     `json:"op"`
+	Text string `json:"text"`
+}
+
+// Lines returns the line-level diff turning the text a into the text b.
+//
+// The diff is a shortest edit script found by Myers' algorithm, unless the texts
+// differ by more than maxEditDistance lines: the differing lines are then deleted
+// and inserted as a block between the common prefix and suffix.
+// Line endings are \n or \r\n.
+func Lines(a, b string) []Line {
+	return diff(splitLines(a), splitLines(b))
+}
+
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSuffix(line, "\r")
+	}
+	return lines
+}
+
+func diff(a, b []string) []Line {
+	// the common prefix and suffix are kept as they are, only the middle is searched
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	lines := make([]Line, 0, len(a)+len(b))
+	for _, line := range a[:prefix] {
+		lines = append(lines, Line{Op: OpEqual, Text: line})
+	}
+	lines = append(lines, myers(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	for _, line := range a[len(a)-suffix:] {
+		lines = append(lines, Line{Op: OpEqual, Text: line})
+	}
+	return lines
+}
+
+// myers returns a shortest edit script turning a into b.
+//
+// For each number of edits d, v holds the furthest x reached on each diagonal k = x - y.
+// The part of v read by each round is kept to walk the edits back from the end.
+// Past maxEditDistance edits the search stops and a is replaced by b.
+func myers(a, b []string) []Line {
+	n, m := len(a), len(b)
+	if n == 0 && m == 0 {
+		return nil
+	}
+
+	offset := n + m + 1
+	v := make([]int, 2*offset+1)
+	var trace [][]int
+
+	for d := 0; d <= n+m; d++ {
+		if d > maxEditDistance {
+			return replace(a, b)
+		}
+
+		// the round reads the diagonals -d-1 to d+1
+		trace = append(trace, append([]int(nil), v[offset-d-1:offset+d+2]...))
+
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				// down from the diagonal above, an insertion
+				x = v[offset+k+1]
+			} else {
+				// right from the diagonal below, a deletion
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+
+			if x >= n && y >= m {
+				return backtrack(a, b, trace)
+			}
+		}
+	}
+	return nil
+}
+
+// replace returns the edit script deleting every line of a, then inserting every line of b.
+func replace(a, b []string) []Line {
+	lines := make([]Line, 0, len(a)+len(b))
+	for _, line := range a {
+		lines = append(lines, Line{Op: OpDelete, Text: line})
+	}
+	for _, line := range b {
+		lines = append(lines, Line{Op: OpInsert, Text: line})
+	}
+	return lines
+}
+
+// backtrack walks the edits of the rounds back from the end of a and b.
+func backtrack(a, b []string, trace [][]int) []Line {
+	var lines []Line
+	x, y := len(a), len(b)
+
+	for d := len(trace) - 1; d > 0; d-- {
+		round := trace[d]
+		at := func(k int) int { return round[k+d+1] }
+
+		k := x - y
+		prevK := k - 1
+		if k == -d || (k != d && at(k-1) < at(k+1)) {
+			prevK = k + 1
+		}
+		prevX := at(prevK)
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			lines = append(lines, Line{Op: OpEqual, Text: a[x-1]})
+			x--
+			y--
+		}
+		if x == prevX {
+			lines = append(lines, Line{Op: OpInsert, Text: b[y-1]})
+		} else {
+			lines = append(lines, Line{Op: OpDelete, Text: a[x-1]})
+		}
+		x, y = prevX, prevY
+	}
+	for x > 0 && y > 0 {
+		lines = append(lines, Line{Op: OpEqual, Text: a[x-1]})
+		x--
+		y--
+	}
+
+	for i, j := 0, len(lines)-1; i < j; i, j = i+1, j-1 {
+		lines[i], lines[j] = lines[j], lines[i]
+	}
+	return lines
+}
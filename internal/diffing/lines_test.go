@@ -0,0 +1,114 @@
+package diffing_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/undercode99/article_service/internal/diffing"
+)
+
+// TestLines tests the Lines function.
+//
+// It checks the diffs of a few edits, and that every diff turns the first text into the second
+// with the fewest inserted and deleted lines.
+func TestLines(t *testing.T) {
+	tests := []struct {
+		name  string
+		a, b  string
+		want  []diffing.Line
+		edits int
+	}{
+		{
+			name: "Equal",
+			a:    "one\ntwo",
+			b:    "one\ntwo",
+			want: []diffing.Line{{Op: diffing.OpEqual, Text: "one"}, {Op: diffing.OpEqual, Text: "two"}},
+		},
+		{
+			name: "Empty",
+			a:    "",
+			b:    "",
+			want: []diffing.Line{},
+		},
+		{
+			name: "Added",
+			a:    "",
+			b:    "one\ntwo",
+			want: []diffing.Line{{Op: diffing.OpInsert, Text: "one"}, {Op: diffing.OpInsert, Text: "two"}},
+		},
+		{
+			name: "Line replaced",
+			a:    "one\ntwo\nthree",
+			b:    "one\r\n2\r\nthree",
+			want: []diffing.Line{
+				{Op: diffing.OpEqual, Text: "one"},
+				{Op: diffing.OpDelete, Text: "two"},
+				{Op: diffing.OpInsert, Text: "2"},
+				{Op: diffing.OpEqual, Text: "three"},
+			},
+		},
+		{
+			name:  "Lines moved",
+			a:     "a\nb\nc\na\nb\nb\na",
+			b:     "c\nb\na\nb\na\nc",
+			edits: 5,
+		},
+		{
+			name:  "Everything replaced",
+			a:     "one\ntwo\nthree",
+			b:     "four\nfive",
+			edits: 5,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines := diffing.Lines(tt.a, tt.b)
+			if tt.want != nil {
+				assert.Equal(t, tt.want, append([]diffing.Line{}, lines...))
+			}
+
+			var before, after []string
+			edits := 0
+			for _, line := range lines {
+				if line.Op != diffing.OpInsert {
+					before = append(before, line.Text)
+				}
+				if line.Op != diffing.OpDelete {
+					after = append(after, line.Text)
+				}
+				if line.Op != diffing.OpEqual {
+					edits++
+				}
+			}
+			assert.Equal(t, strings.ReplaceAll(tt.a, "\r", ""), strings.Join(before, "\n"))
+			assert.Equal(t, strings.ReplaceAll(tt.b, "\r", ""), strings.Join(after, "\n"))
+			if tt.want == nil {
+				assert.Equal(t, tt.edits, edits)
+			}
+		})
+	}
+}
+
+// TestLines_Replaced tests the diff of texts differing by too many lines for Myers' algorithm.
+//
+// It checks the differing lines are deleted and inserted as a block between the common lines.
+func TestLines_Replaced(t *testing.T) {
+	var a, b []string
+	for i := 0; i < 1500; i++ {
+		a = append(a, fmt.Sprintf("a%d", i))
+		b = append(b, fmt.Sprintf("b%d", i))
+	}
+
+	lines := diffing.Lines("first\n"+strings.Join(a, "\n")+"\nlast", "first\n"+strings.Join(b, "\n")+"\nlast")
+
+	assert.Len(t, lines, 3002)
+	assert.Equal(t, diffing.Line{Op: diffing.OpEqual, Text: "first"}, lines[0])
+	assert.Equal(t, diffing.Line{Op: diffing.OpDelete, Text: "a0"}, lines[1])
+	assert.Equal(t, diffing.Line{Op: diffing.OpDelete, Text: "a1499"}, lines[1500])
+	assert.Equal(t, diffing.Line{Op: diffing.OpInsert, Text: "b0"}, lines[1501])
+	assert.Equal(t, diffing.Line{Op: diffing.OpInsert, Text: "b1499"}, lines[3000])
+	assert.Equal(t, diffing.Line{Op: diffing.OpEqual, Text: "last"}, lines[3001])
+}
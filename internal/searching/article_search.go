@@ -0,0 +1,374 @@
+package searching
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/elastic/go-elasticsearch/v8/typedapi/core/search"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types/enums/calendarinterval"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types/enums/highlighterencoder"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types/enums/operator"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types/enums/sortorder"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types/enums/textquerytype"
+	"github.com/undercode99/article_service/internal/app/article"
+)
+
+// relatedSameAuthorBoost is the boost of the articles of the same author among the related articles.
+const relatedSameAuthorBoost = 2.0
+
+// highlightFragments is the maximum number of highlighted fragments returned per article body.
+const highlightFragments = 3
+
+// createdFacetIntervals are the calendar intervals of the created facet.
+var createdFacetIntervals = map[string]calendarinterval.CalendarInterval{
+	article.FacetIntervalDay:   calendarinterval.Day,
+	article.FacetIntervalMonth: calendarinterval.Month,
+	article.FacetIntervalYear:  calendarinterval.Year,
+}
+
+// createdFacetFormats are the formats of the bucket keys of the created facet per interval.
+var createdFacetFormats = map[string]string{
+	article.FacetIntervalDay:   "yyyy-MM-dd",
+	article.FacetIntervalMonth: "yyyy-MM",
+	article.FacetIntervalYear:  "yyyy",
+}
+
+// NewArticleSearchRequest turns an article query into the search request of the article index.
+//
+// The search terms restrict and score the articles, the status, authors, created
+// range, tags and category only restrict them. Highlighting, the summary view and the facets are added when
+// requested. In cursor mode the articles are sorted by (created, id) without an
+// offset, the point in time and search_after are left to the caller.
+//
+// article.ErrInvalidCreatedRange is returned if the created range cannot be parsed.
+func NewArticleSearchRequest(qry *article.ArticleQuery) (*search.Request, error) {
+	query, err := articleQuery(qry)
+	if err != nil {
+		return nil, err
+	}
+
+	req := &search.Request{
+		Query: query,
+		Sort:  articleSort(qry),
+		Size:  intPtr(qry.GetLimit()),
+		// count every match, by default the total stops at 10000
+		TrackTotalHits: true,
+	}
+
+	if !qry.UseCursor {
+		req.From = intPtr((qry.GetPage() - 1) * qry.GetLimit())
+	}
+
+	if qry.IsHighlight() {
+		req.Highlight = articleHighlight(qry)
+	}
+
+	if qry.IsSummary() {
+		req.Source_ = types.SourceFilter{Excludes: []string{"body"}}
+	}
+
+	// aggregations run over the matching articles, so they follow the search and filters
+	if aggs := articleAggregations(qry); len(aggs) > 0 {
+		req.Aggregations = aggs
+	}
+
+	return req, nil
+}
+
+// articleQuery returns the bool query of the search terms and filters of the article query.
+func articleQuery(qry *article.ArticleQuery) (*types.Query, error) {
+	from, to, err := qry.GetCreatedRange()
+	if err != nil {
+		return nil, err
+	}
+
+	boolQuery := &types.BoolQuery{
+		Filter: []types.Query{statusClause(qry.GetStatus())},
+	}
+
+	if qry.Search != "" {
+		boolQuery.Must = append(boolQuery.Must, types.Query{MultiMatch: searchClause(qry.Search, qry.GetMatchMode())})
+	}
+
+	if authors := qry.GetAuthors(); len(authors) > 0 {
+		boolQuery.Filter = append(boolQuery.Filter, authorsClause(authors))
+	}
+
+	if from != nil || to != nil {
+		created := types.DateRangeQuery{}
+		if from != nil {
+			created.Gte = timePtr(*from)
+		}
+		if to != nil {
+			created.Lt = timePtr(*to)
+		}
+		boolQuery.Filter = append(boolQuery.Filter, types.Query{
+			Range: map[string]types.RangeQuery{"created": created},
+		})
+	}
+
+	// an article must have every tag
+	for _, tag := range qry.GetTags() {
+		boolQuery.Filter = append(boolQuery.Filter, types.Query{
+			Term: map[string]types.TermQuery{"tags": {Value: tag}},
+		})
+	}
+
+	if category := qry.GetCategory(); category != "" {
+		boolQuery.Filter = append(boolQuery.Filter, categoryClause(category))
+	}
+
+	if excluded := qry.GetExcludeAuthors(); len(excluded) > 0 {
+		boolQuery.MustNot = append(boolQuery.MustNot, authorsClause(excluded))
+	}
+
+	return &types.Query{Bool: boolQuery}, nil
+}
+
+// searchClause returns the query matching the search terms in the title and body.
+//
+// MatchModeAll requires every term in the title or body, MatchModePhrase requires
+// the terms in order and MatchModeAny requires at least one of the terms.
+func searchClause(search, matchMode string) *types.MultiMatchQuery {
+	multiMatch := &types.MultiMatchQuery{
+		Query:  search,
+		Fields: []string{"title", "body"},
+	}
+
+	switch matchMode {
+	case article.MatchModeAll:
+		multiMatch.Type = &textquerytype.Crossfields
+		multiMatch.Operator = &operator.And
+	case article.MatchModePhrase:
+		multiMatch.Type = &textquerytype.Phrase
+	default:
+		multiMatch.Type = &textquerytype.Bestfields
+		multiMatch.Operator = &operator.Or
+	}
+
+	return multiMatch
+}
+
+// statusClause returns the query matching the articles in the given status.
+//
+// The documents indexed before the lifecycle have no status, they are published
+// articles until the index is rebuilt.
+func statusClause(status string) types.Query {
+	term := types.Query{Term: map[string]types.TermQuery{"status": {Value: status}}}
+	if status != article.StatusPublished {
+		return term
+	}
+
+	return types.Query{Bool: &types.BoolQuery{
+		Should: []types.Query{
+			term,
+			{Bool: &types.BoolQuery{MustNot: []types.Query{{Exists: &types.ExistsQuery{Field: "status"}}}}},
+		},
+		MinimumShouldMatch: 1,
+	}}
+}
+
+// authorsClause returns the query matching the articles of any of the given authors.
+func authorsClause(authors []string) types.Query {
+	return types.Query{
+		Terms: &types.TermsQuery{
+			TermsQuery: map[string]types.TermsQueryField{"author": authors},
+		},
+	}
+}
+
+// categoryClause returns the query matching the articles of the category with the path and of its subcategories.
+func categoryClause(path string) types.Query {
+	return types.Query{Bool: &types.BoolQuery{
+		Should: []types.Query{
+			{Term: map[string]types.TermQuery{"category": {Value: path}}},
+			{Prefix: map[string]types.PrefixQuery{"category": {Value: path + "/"}}},
+		},
+		MinimumShouldMatch: 1,
+	}}
+}
+
+// articleSort returns the sort of the articles by creation time.
+//
+// In cursor mode the ID breaks ties between articles created in the same
+// millisecond, so search_after never skips or repeats an article.
+func articleSort(qry *article.ArticleQuery) []types.SortCombinations {
+	order := sortorder.Asc
+	if qry.SortNewest {
+		order = sortorder.Desc
+	}
+
+	sort := []types.SortCombinations{
+		types.SortOptions{SortOptions: map[string]types.FieldSort{"created": {Order: &order}}},
+	}
+	if qry.UseCursor {
+		sort = append(sort, types.SortOptions{SortOptions: map[string]types.FieldSort{"id": {Order: &order}}})
+	}
+
+	return sort
+}
+
+// articleHighlight returns the highlight of the search terms in the title and body.
+//
+// The whole title is returned, the body is cut into fragments.
+func articleHighlight(qry *article.ArticleQuery) *types.Highlight {
+	preTag, postTag := qry.GetHighlightTags()
+
+	return &types.Highlight{
+		// the source text is escaped so the fragments are safe to render as HTML
+		Encoder:           &highlighterencoder.Html,
+		PreTags:           []string{preTag},
+		PostTags:          []string{postTag},
+		FragmentSize:      intPtr(qry.GetFragmentSize()),
+		NumberOfFragments: intPtr(highlightFragments),
+		Fields: map[string]types.HighlightField{
+			"title": {NumberOfFragments: intPtr(0)},
+			"body":  {},
+		},
+	}
+}
+
+// articleAggregations returns the aggregations of the requested facets.
+func articleAggregations(qry *article.ArticleQuery) map[string]types.Aggregations {
+	aggs := map[string]types.Aggregations{}
+
+	if qry.AuthorFacet {
+		aggs["authors"] = types.Aggregations{
+			Terms: &types.TermsAggregation{
+				Field: stringPtr("author"),
+				Size:  intPtr(qry.GetAuthorFacetSize()),
+			},
+		}
+	}
+
+	if qry.TagFacet {
+		aggs["tags"] = types.Aggregations{
+			Terms: &types.TermsAggregation{
+				Field: stringPtr("tags"),
+				Size:  intPtr(qry.GetTagFacetSize()),
+			},
+		}
+	}
+
+	if interval, ok := createdFacetIntervals[qry.CreatedFacet]; ok {
+		aggs["created"] = types.Aggregations{
+			DateHistogram: &types.DateHistogramAggregation{
+				Field:            stringPtr("created"),
+				CalendarInterval: &interval,
+				Format:           stringPtr(createdFacetFormats[qry.CreatedFacet]),
+				MinDocCount:      intPtr(1),
+			},
+		}
+	}
+
+	return aggs
+}
+
+func intPtr(i int) *int {
+	return &i
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
+
+func timePtr(t time.Time) *string {
+	s := t.Format(time.RFC3339Nano)
+	return &s
+}
+
+// NewArticleSuggestRequest turns a suggest query into the search request of the article index.
+//
+// The titles starting with the prefix are matched on the search_as_you_type
+// subfield of the title and only their ID and title are returned. The authors
+// starting with the prefix are collected over the whole index by a global
+// aggregation, so each author is suggested once. Only the published articles are suggested.
+func NewArticleSuggestRequest(qry *article.ArticleSuggestQuery) *search.Request {
+	published := statusClause(article.StatusPublished)
+
+	return &search.Request{
+		Query: &types.Query{Bool: &types.BoolQuery{
+			Must:   []types.Query{{MultiMatch: prefixClause(qry.Q, "title.suggest")}},
+			Filter: []types.Query{published},
+		}},
+		Size: intPtr(qry.GetLimit()),
+		Source_: types.SourceFilter{
+			Includes: []string{"id", "title", "slug"},
+		},
+		TrackTotalHits: false,
+		Aggregations: map[string]types.Aggregations{
+			"authors": {
+				Global: &types.GlobalAggregation{},
+				Aggregations: map[string]types.Aggregations{
+					"matching": {
+						// the global aggregation ignores the query, the status is filtered again
+						Filter: &types.Query{Bool: &types.BoolQuery{
+							Must:   []types.Query{{MultiMatch: prefixClause(qry.Q, "author.suggest")}},
+							Filter: []types.Query{published},
+						}},
+						Aggregations: map[string]types.Aggregations{
+							"names": {
+								Terms: &types.TermsAggregation{
+									Field: stringPtr("author"),
+									Size:  intPtr(qry.GetLimit()),
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// prefixClause returns the query matching the prefix on a search_as_you_type field.
+//
+// The last term of the prefix is matched as a prefix, the others as whole terms.
+func prefixClause(prefix, field string) *types.MultiMatchQuery {
+	return &types.MultiMatchQuery{
+		Query:  prefix,
+		Type:   &textquerytype.Boolprefix,
+		Fields: []string{field, field + "._2gram", field + "._3gram"},
+	}
+}
+
+// NewArticleRelatedRequest turns a related query into the search request of the article index.
+//
+// The articles sharing the most significant terms of the title and body of the
+// published articles are returned without their body, the source article itself is
+// left out. With SameAuthor the articles of the author of the source are boosted.
+func NewArticleRelatedRequest(qry *article.ArticleRelatedQuery, source *article.Article) *search.Request {
+	id := strconv.Itoa(source.ID)
+
+	boolQuery := &types.BoolQuery{
+		Must: []types.Query{{
+			MoreLikeThis: &types.MoreLikeThisQuery{
+				Fields: []string{"title", "body"},
+				Like: []types.Like{types.LikeDocument{
+					Index_: stringPtr(article.IndexName),
+					Id_:    &id,
+				}},
+				// the defaults ignore the terms seen less than twice in the source
+				// or in fewer than five articles, too strict for short articles
+				MinTermFreq: intPtr(1),
+				MinDocFreq:  intPtr(1),
+			},
+		}},
+		Filter:  []types.Query{statusClause(article.StatusPublished)},
+		MustNot: []types.Query{{Ids: &types.IdsQuery{Values: []string{id}}}},
+	}
+
+	if qry.SameAuthor {
+		boost := float32(relatedSameAuthorBoost)
+		boolQuery.Should = []types.Query{{
+			Term: map[string]types.TermQuery{"author": {Value: source.Author, Boost: &boost}},
+		}}
+	}
+
+	return &search.Request{
+		Query:   &types.Query{Bool: boolQuery},
+		Size:    intPtr(qry.GetLimit()),
+		Source_: types.SourceFilter{Excludes: []string{"body"}},
+	}
+}
@@ -0,0 +1,144 @@
+package searching_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/undercode99/article_service/internal/app/article"
+	"github.com/undercode99/article_service/internal/searching"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+// TestNewArticleSearchRequest tests the NewArticleSearchRequest function.
+//
+// The request of each article query is compared with its golden file in
+// testdata/article_search, run the tests with -update to rewrite them.
+func TestNewArticleSearchRequest(t *testing.T) {
+	tests := []struct {
+		name  string
+		query *article.ArticleQuery
+	}{
+		{"default", &article.ArticleQuery{}},
+		{"page", &article.ArticleQuery{Page: 3, Limit: 20}},
+		{"sort_newest", &article.ArticleQuery{SortNewest: true}},
+		{"search_any", &article.ArticleQuery{Search: "golang generics"}},
+		{"search_all", &article.ArticleQuery{Search: "golang generics", MatchMode: article.MatchModeAll}},
+		{"search_phrase", &article.ArticleQuery{Search: "golang generics", MatchMode: article.MatchModePhrase}},
+		{"authors", &article.ArticleQuery{Author: "John Doe", Authors: []string{"Jane Doe"}}},
+		{"exclude_authors", &article.ArticleQuery{ExcludeAuthors: []string{"Jim Doe", "Jill Doe"}}},
+		{"created_range_dates", &article.ArticleQuery{CreatedFrom: "2023-07-01", CreatedTo: "2023-09-30"}},
+		{"created_range_times", &article.ArticleQuery{CreatedFrom: "2023-07-01T08:00:00+07:00", CreatedTo: "2023-07-01T17:00:00+07:00"}},
+		{"cursor", &article.ArticleQuery{UseCursor: true, Limit: 50, SortNewest: true}},
+		{"highlight", &article.ArticleQuery{Search: "golang", Highlight: true, FragmentSize: 80, PreTag: "<mark>", PostTag: "</mark>"}},
+		{"highlight_without_search", &article.ArticleQuery{Highlight: true}},
+		{"summary", &article.ArticleQuery{View: article.ArticleViewSummary}},
+		{"status_draft", &article.ArticleQuery{Author: "John Doe", Status: article.StatusDraft}},
+		{"facets", &article.ArticleQuery{AuthorFacet: true, AuthorFacetSize: 5, CreatedFacet: article.FacetIntervalDay}},
+		{"taxonomy", &article.ArticleQuery{Tags: []string{"Golang", "web  development"}, Category: "Tech/", TagFacet: true}},
+		{"combined", &article.ArticleQuery{
+			Search:         "golang",
+			MatchMode:      article.MatchModeAll,
+			Authors:        []string{"John Doe", "Jane Doe", "Jim Doe"},
+			ExcludeAuthors: []string{"Jill Doe"},
+			CreatedFrom:    "2023-07-01",
+			CreatedTo:      "2023-09-30",
+			SortNewest:     true,
+			Highlight:      true,
+			View:           article.ArticleViewSummary,
+			AuthorFacet:    true,
+			CreatedFacet:   article.FacetIntervalMonth,
+		}},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := searching.NewArticleSearchRequest(tt.query)
+			assert.NoError(t, err)
+
+			actual, err := json.Marshal(req)
+			assert.NoError(t, err)
+
+			golden := filepath.Join("testdata", "article_search", tt.name+".json")
+			if *update {
+				writeGolden(t, golden, req)
+			}
+
+			expected, err := os.ReadFile(golden)
+			assert.NoError(t, err)
+			assert.JSONEq(t, string(expected), string(actual))
+		})
+	}
+}
+
+// TestNewArticleSearchRequest_InvalidCreatedRange tests that an invalid created range is rejected.
+func TestNewArticleSearchRequest_InvalidCreatedRange(t *testing.T) {
+	req, err := searching.NewArticleSearchRequest(&article.ArticleQuery{CreatedFrom: "last week"})
+	assert.Equal(t, article.ErrInvalidCreatedRange, err)
+	assert.Nil(t, req)
+}
+
+// TestNewArticleSuggestRequest tests the NewArticleSuggestRequest function against its golden file.
+func TestNewArticleSuggestRequest(t *testing.T) {
+	req := searching.NewArticleSuggestRequest(&article.ArticleSuggestQuery{Q: "go gen", Limit: 8})
+
+	actual, err := json.Marshal(req)
+	assert.NoError(t, err)
+
+	golden := filepath.Join("testdata", "article_search", "suggest.json")
+	if *update {
+		writeGolden(t, golden, req)
+	}
+
+	expected, err := os.ReadFile(golden)
+	assert.NoError(t, err)
+	assert.JSONEq(t, string(expected), string(actual))
+}
+
+// writeGolden writes the indented JSON of the value to the golden file.
+func writeGolden(t *testing.T, golden string, value interface{}) {
+	var out bytes.Buffer
+	enc := json.NewEncoder(&out)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	assert.NoError(t, enc.Encode(value))
+	assert.NoError(t, os.WriteFile(golden, out.Bytes(), 0o644))
+}
+
+// TestNewArticleRelatedRequest tests the NewArticleRelatedRequest function against its golden files.
+func TestNewArticleRelatedRequest(t *testing.T) {
+	source := &article.Article{ID: 42, Author: "John Doe", Title: "Golang generics"}
+
+	tests := []struct {
+		name  string
+		query *article.ArticleRelatedQuery
+	}{
+		{"related", &article.ArticleRelatedQuery{ID: 42}},
+		{"related_same_author", &article.ArticleRelatedQuery{ID: 42, Limit: 3, SameAuthor: true}},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			req := searching.NewArticleRelatedRequest(tt.query, source)
+
+			actual, err := json.Marshal(req)
+			assert.NoError(t, err)
+
+			golden := filepath.Join("testdata", "article_search", tt.name+".json")
+			if *update {
+				writeGolden(t, golden, req)
+			}
+
+			expected, err := os.ReadFile(golden)
+			assert.NoError(t, err)
+			assert.JSONEq(t, string(expected), string(actual))
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package searching
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrElasticUnavailable is wrapped by the errors of requests Elasticsearch could not answer,
+	// as opposed to the requests it rejected.
+	ErrElasticUnavailable = errors.New("elasticsearch unavailable")
+)
+
+// CircuitBreaker stops sending requests to Elasticsearch once it keeps failing.
+//
+// The breaker opens after threshold consecutive failures and rejects requests
+// until the cooldown has passed. A single trial request is then let through,
+// its success closes the breaker and its failure opens it again.
+type CircuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+
+	mu       sync.Mutex
+	failures int
+	openedAt time.Time
+	trial    bool
+}
+
+// NewCircuitBreaker returns a closed circuit breaker.
+func NewCircuitBreaker(threshold int, cooldown time.Duration) *CircuitBreaker {
+	return NewCircuitBreakerWithClock(threshold, cooldown, time.Now)
+}
+
+// NewCircuitBreakerWithClock returns a closed circuit breaker reading the time from now.
+func NewCircuitBreakerWithClock(threshold int, cooldown time.Duration, now func() time.Time) *CircuitBreaker {
+	if threshold < 1 {
+		threshold = 1
+	}
+	return &CircuitBreaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		now:       now,
+	}
+}
+
+// Allow reports whether a request may be sent, every allowed request must be
+// followed by a call to Success, Failure or Release.
+func (b *CircuitBreaker) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.failures < b.threshold {
+		return true
+	}
+
+	// open, only one trial request once the cooldown has passed
+	if b.trial || b.now().Sub(b.openedAt) < b.cooldown {
+		return false
+	}
+	b.trial = true
+	return true
+}
+
+// Success records a request answered by Elasticsearch and closes the breaker.
+func (b *CircuitBreaker) Success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures = 0
+	b.trial = false
+}
+
+// Failure records a request Elasticsearch could not answer, opening the breaker
+// once the threshold is reached.
+func (b *CircuitBreaker) Failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	b.trial = false
+	if b.failures >= b.threshold {
+		b.openedAt = b.now()
+	}
+}
+
+// Release records a request abandoned by the caller before Elasticsearch answered,
+// it is neither a success nor a failure and only frees the trial slot.
+func (b *CircuitBreaker) Release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.trial = false
+}
+
+// IsOpen reports whether requests are currently rejected.
+func (b *CircuitBreaker) IsOpen() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.failures >= b.threshold
+}
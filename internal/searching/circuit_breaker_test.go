@@ -0,0 +1,59 @@
+package searching_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/undercode99/article_service/internal/searching"
+)
+
+// TestCircuitBreaker tests the transitions of the CircuitBreaker.
+//
+// The breaker opens after the threshold of consecutive failures, lets a single trial
+// request through after the cooldown and closes again when the trial succeeds.
+func TestCircuitBreaker(t *testing.T) {
+	now := time.Date(2023, 7, 1, 8, 0, 0, 0, time.UTC)
+	breaker := searching.NewCircuitBreakerWithClock(2, 30*time.Second, func() time.Time { return now })
+
+	assert.True(t, breaker.Allow())
+	breaker.Failure()
+	assert.True(t, breaker.Allow())
+	breaker.Success()
+
+	// the failures must be consecutive
+	breaker.Failure()
+	assert.False(t, breaker.IsOpen())
+	breaker.Failure()
+	assert.True(t, breaker.IsOpen())
+	assert.False(t, breaker.Allow())
+
+	// a failed trial opens the breaker for another cooldown
+	now = now.Add(30 * time.Second)
+	assert.True(t, breaker.Allow())
+	assert.False(t, breaker.Allow())
+	breaker.Failure()
+	assert.False(t, breaker.Allow())
+
+	// a successful trial closes the breaker
+	now = now.Add(30 * time.Second)
+	assert.True(t, breaker.Allow())
+	breaker.Success()
+	assert.False(t, breaker.IsOpen())
+	assert.True(t, breaker.Allow())
+}
+
+// TestCircuitBreaker_Release tests a released trial keeps the breaker open and lets another trial through.
+func TestCircuitBreaker_Release(t *testing.T) {
+	now := time.Date(2023, 7, 1, 8, 0, 0, 0, time.UTC)
+	breaker := searching.NewCircuitBreakerWithClock(1, 30*time.Second, func() time.Time { return now })
+
+	breaker.Failure()
+	now = now.Add(30 * time.Second)
+	assert.True(t, breaker.Allow())
+	assert.False(t, breaker.Allow())
+
+	breaker.Release()
+	assert.True(t, breaker.IsOpen())
+	assert.True(t, breaker.Allow())
+}
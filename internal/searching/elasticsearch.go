@@ -1,18 +1,38 @@
 package searching
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/elastic/go-elasticsearch/v8"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/indices/create"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/indices/updatealiases"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types"
 	"github.com/undercode99/article_service/config"
 )
 
+var (
+	ErrIndexMappingMismatch = errors.New("index mapping mismatch")
+)
+
 // NewElasticClient creates a new Elasticsearch client.
 //
 // It takes a context and a config as parameters.
-// Returns a pointer to the elasticsearch.TypedClient.
+// Returns a pointer to the elasticsearch.TypedClient, nil if the articles are
+// searched in Postgres so Elasticsearch does not have to be running.
+// An unreachable Elasticsearch is logged but does not prevent the client from being created.
 func NewElasticClient(ctx context.Context, cfg *config.Config) *elasticsearch.TypedClient {
+	if cfg.SearchBackendIsPostgres() {
+		log.Println("Search backend is postgres, Elasticsearch is not used")
+		return nil
+	}
 
 	// create a new client
 	client, err := elasticsearch.NewTypedClient(elasticsearch.Config{
@@ -23,42 +43,210 @@ func NewElasticClient(ctx context.Context, cfg *config.Config) *elasticsearch.Ty
 		log.Fatalf("Error creating the client: %s", err)
 	}
 
+	// the client connects on each request, an unreachable cluster does not stop
+	// the service, the searches fall back to Postgres until it is reachable
 	res, err := client.Ping().Do(ctx)
-	if err != nil {
-		log.Fatal(err)
-	}
-	if !res {
-		log.Fatal("Elasticsearch is not reachable")
+	if err != nil || !res {
+		log.Printf("WARNING: Elasticsearch is not reachable: %v", err)
 	}
 
 	return client
 
 }
 
-// CreateIndexElastic creates an index in Elasticsearch.
+// CreateIndexElastic creates the index served under the given alias in Elasticsearch.
+//
+// If neither an alias nor an index with the given name exists, a new versioned
+// index is created with the given mapping and the alias is pointed at it.
 //
 // ctx: the context to use for the request.
 // client: the Elasticsearch client.
-// index: the name of the index to create.
+// alias: the name of the alias the index is served under.
+// mapping: the mapping the index is created with.
 // Returns an error if there was a problem creating the index.
-func CreateIndexElastic(ctx context.Context, client *elasticsearch.TypedClient, index string) error {
-	indexExists, err := client.Indices.Exists(index).Do(ctx)
+func CreateIndexElastic(ctx context.Context, client *elasticsearch.TypedClient, alias string, mapping *types.TypeMapping) error {
+	// exists is true for both an alias and a concrete index with the given name
+	indexExists, err := client.Indices.Exists(alias).Do(ctx)
 	if err != nil {
 		log.Printf("Error checking if index exists: %v", err)
 		return err
 	}
 
 	if indexExists {
-		log.Printf("Index %s already exists", index)
+		log.Printf("Index %s already exists", alias)
 		return nil
 	}
 
-	_, err = client.Indices.Create(index).Do(ctx)
+	index, err := CreateVersionedIndex(ctx, client, alias, mapping)
+	if err != nil {
+		return err
+	}
+
+	_, err = SwapAlias(ctx, client, alias, index)
 	if err != nil {
-		log.Printf("Error creating index: %v", err)
 		return err
 	}
 
+	log.Printf("Index %s created successfully", alias)
+	return nil
+}
+
+// NewIndexVersionName returns the name of a new versioned index for the given alias.
+//
+// The version is the current UTC time, so versions sort in creation order.
+func NewIndexVersionName(alias string) string {
+	return fmt.Sprintf("%s_%s", alias, time.Now().UTC().Format("20060102150405"))
+}
+
+// CreateVersionedIndex creates a new versioned index for the given alias with the given mapping.
+//
+// The alias is not changed, use SwapAlias once the index is ready to be served.
+// Returns the name of the created index.
+func CreateVersionedIndex(ctx context.Context, client *elasticsearch.TypedClient, alias string, mapping *types.TypeMapping) (string, error) {
+	index := NewIndexVersionName(alias)
+
+	_, err := client.Indices.Create(index).Request(&create.Request{Mappings: mapping}).Do(ctx)
+	if err != nil {
+		log.Printf("Error creating index: %v", err)
+		return "", err
+	}
+
 	log.Printf("Index %s created successfully", index)
+	return index, nil
+}
+
+// CheckIndexMapping checks that the indices served under the given alias were created with the given mapping.
+//
+// The mappings are compared by their _meta field, which holds the mapping version.
+// ErrIndexMappingMismatch is returned, wrapped with the details, if an index
+// was created with another version of the mapping.
+func CheckIndexMapping(ctx context.Context, client *elasticsearch.TypedClient, alias string, mapping *types.TypeMapping) error {
+	res, err := client.Indices.GetMapping().Index(alias).Do(ctx)
+	if err != nil {
+		return err
+	}
+
+	expected, err := json.Marshal(mapping.Meta_)
+	if err != nil {
+		return err
+	}
+
+	indices := make([]string, 0, len(res))
+	for index := range res {
+		indices = append(indices, index)
+	}
+	sort.Strings(indices)
+
+	for _, index := range indices {
+		meta := res[index].Mappings.Meta_
+		if meta == nil {
+			meta = types.Metadata{}
+		}
+
+		actual, err := json.Marshal(meta)
+		if err != nil {
+			return err
+		}
+
+		// json.Marshal sorts map keys, so equal metadata encodes equally
+		if !bytes.Equal(actual, expected) {
+			return fmt.Errorf("%w: index %s has mapping %s, expected %s", ErrIndexMappingMismatch, index, actual, expected)
+		}
+	}
+
+	return nil
+}
+
+// GetAliasIndices returns the names of the indices the given alias points at.
+//
+// An empty slice is returned if the alias does not exist.
+func GetAliasIndices(ctx context.Context, client *elasticsearch.TypedClient, alias string) ([]string, error) {
+	aliasExists, err := client.Indices.ExistsAlias(alias).Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if !aliasExists {
+		return []string{}, nil
+	}
+
+	res, err := client.Indices.GetAlias().Name(alias).Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	indices := make([]string, 0, len(res))
+	for index := range res {
+		indices = append(indices, index)
+	}
+	sort.Strings(indices)
+
+	return indices, nil
+}
+
+// SwapAlias atomically points the given alias at the given index.
+//
+// The alias is removed from every index it pointed at before in the same request.
+// A concrete index occupying the alias name, left from before indices were
+// versioned, is deleted in the same request as well.
+// Returns the names of the indices the alias pointed at before.
+func SwapAlias(ctx context.Context, client *elasticsearch.TypedClient, alias string, index string) ([]string, error) {
+	previous, err := GetAliasIndices(ctx, client, alias)
+	if err != nil {
+		return nil, err
+	}
+
+	actions := []types.IndicesAction{}
+	for _, previousIndex := range previous {
+		if previousIndex == index {
+			continue
+		}
+		previousIndex := previousIndex
+		actions = append(actions, types.IndicesAction{
+			Remove: &types.RemoveAction{Index: &previousIndex, Alias: &alias},
+		})
+	}
+
+	if len(previous) == 0 {
+		legacyExists, err := client.Indices.Exists(alias).Do(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		if legacyExists {
+			log.Printf("Replacing legacy index %s with alias", alias)
+			actions = append(actions, types.IndicesAction{
+				RemoveIndex: &types.RemoveIndexAction{Index: &alias},
+			})
+		}
+	}
+
+	actions = append(actions, types.IndicesAction{
+		Add: &types.AddAction{Index: &index, Alias: &alias},
+	})
+
+	_, err = client.Indices.UpdateAliases().Request(&updatealiases.Request{Actions: actions}).Do(ctx)
+	if err != nil {
+		log.Printf("Error swapping alias: %v", err)
+		return nil, err
+	}
+
+	log.Printf("Alias %s points at index %s", alias, index)
+	return previous, nil
+}
+
+// DeleteIndices deletes the given indices.
+func DeleteIndices(ctx context.Context, client *elasticsearch.TypedClient, indices ...string) error {
+	if len(indices) == 0 {
+		return nil
+	}
+
+	_, err := client.Indices.Delete(strings.Join(indices, ",")).Do(ctx)
+	if err != nil {
+		log.Printf("Error deleting indices: %v", err)
+		return err
+	}
+
+	log.Printf("Indices %s deleted successfully", strings.Join(indices, ", "))
 	return nil
 }
@@ -1,11 +1,160 @@
 package searching_test
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strings"
 	"testing"
+
+	"github.com/elastic/go-elasticsearch/v8"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/undercode99/article_service/internal/searching"
 )
 
+type mockTransport struct {
+	handler func(req *http.Request) (int, string)
+}
+
+func (t *mockTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	status, body := t.handler(req)
+	return &http.Response{
+		StatusCode: status,
+		Body:       ioutil.NopCloser(strings.NewReader(body)),
+		Header:     http.Header{"X-Elastic-Product": []string{"Elasticsearch"}, "Content-Type": []string{"application/json"}},
+	}, nil
+}
+
+func elasticMockClient(handler func(req *http.Request) (int, string)) *elasticsearch.TypedClient {
+	client, err := elasticsearch.NewTypedClient(elasticsearch.Config{
+		Transport: &mockTransport{handler: handler},
+	})
+	if err != nil {
+		panic(err)
+	}
+	return client
+}
+
+func testMapping(version string) *types.TypeMapping {
+	return &types.TypeMapping{
+		Meta_:      types.Metadata{"version": json.RawMessage(version)},
+		Properties: map[string]types.Property{"created": types.NewDateProperty()},
+	}
+}
+
+// TestCreateIndexElastic tests the CreateIndexElastic function.
+//
+// 1. Test nothing is created if the alias already exists.
+// 2. Test a versioned index is created and the alias pointed at it if nothing exists.
 func TestCreateIndexElastic(t *testing.T) {
-	// TODO: Implement test cases for CreateIndexElastic function
+	ctx := context.Background()
+
+	t.Run("Alias exists", func(t *testing.T) {
+		client := elasticMockClient(func(req *http.Request) (int, string) {
+			if req.Method == http.MethodHead && req.URL.Path == "/articles" {
+				return http.StatusOK, ``
+			}
+			t.Errorf("unexpected request %s %s", req.Method, req.URL.Path)
+			return http.StatusNotFound, `{}`
+		})
+
+		err := searching.CreateIndexElastic(ctx, client, "articles", testMapping("1"))
+		assert.NoError(t, err)
+	})
+
+	t.Run("Nothing exists", func(t *testing.T) {
+		var createdIndex, createBody, aliasesBody string
+		client := elasticMockClient(func(req *http.Request) (int, string) {
+			switch {
+			case req.Method == http.MethodHead:
+				return http.StatusNotFound, ``
+			case req.Method == http.MethodPut:
+				createdIndex = strings.TrimPrefix(req.URL.Path, "/")
+				body, _ := ioutil.ReadAll(req.Body)
+				createBody = string(body)
+				return http.StatusOK, `{"acknowledged":true}`
+			case req.Method == http.MethodPost && req.URL.Path == "/_aliases":
+				body, _ := ioutil.ReadAll(req.Body)
+				aliasesBody = string(body)
+				return http.StatusOK, `{"acknowledged":true}`
+			}
+			t.Errorf("unexpected request %s %s", req.Method, req.URL.Path)
+			return http.StatusNotFound, `{}`
+		})
+
+		err := searching.CreateIndexElastic(ctx, client, "articles", testMapping("1"))
+		assert.NoError(t, err)
+		assert.True(t, strings.HasPrefix(createdIndex, "articles_"))
+		assert.JSONEq(t, `{"mappings":{"_meta":{"version":1},"properties":{"created":{"type":"date"}}}}`, createBody)
+		assert.Equal(t, `{"actions":[{"add":{"alias":"articles","index":"`+createdIndex+`"}}]}`, aliasesBody)
+	})
+}
+
+// TestSwapAlias tests the SwapAlias function.
+//
+// It checks that the alias is removed from the previous index and added to the new one in a single request.
+func TestSwapAlias(t *testing.T) {
+	var aliasesBody string
+	client := elasticMockClient(func(req *http.Request) (int, string) {
+		switch {
+		case req.Method == http.MethodHead && req.URL.Path == "/_alias/articles":
+			return http.StatusOK, ``
+		case req.Method == http.MethodGet && req.URL.Path == "/_alias/articles":
+			return http.StatusOK, `{"articles_1":{"aliases":{"articles":{}}}}`
+		case req.Method == http.MethodPost && req.URL.Path == "/_aliases":
+			body, _ := ioutil.ReadAll(req.Body)
+			aliasesBody = string(body)
+			return http.StatusOK, `{"acknowledged":true}`
+		}
+		t.Errorf("unexpected request %s %s", req.Method, req.URL.Path)
+		return http.StatusNotFound, `{}`
+	})
+
+	previous, err := searching.SwapAlias(context.Background(), client, "articles", "articles_2")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"articles_1"}, previous)
+	assert.Equal(t, `{"actions":[{"remove":{"alias":"articles","index":"articles_1"}},{"add":{"alias":"articles","index":"articles_2"}}]}`, aliasesBody)
+}
+
+// TestCheckIndexMapping tests the CheckIndexMapping function.
+//
+// 1. Test no error is returned if every index has the expected mapping version.
+// 2. Test ErrIndexMappingMismatch is returned if an index has another version or no version at all.
+func TestCheckIndexMapping(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		response string
+		mismatch bool
+	}{
+		{"Same version", `{"articles_1":{"mappings":{"_meta":{"version":2}}}}`, false},
+		{"Other version", `{"articles_1":{"mappings":{"_meta":{"version":1}}}}`, true},
+		{"No version", `{"articles":{"mappings":{"properties":{"author":{"type":"text"}}}}}`, true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			client := elasticMockClient(func(req *http.Request) (int, string) {
+				if req.Method == http.MethodGet && req.URL.Path == "/articles/_mapping" {
+					return http.StatusOK, tt.response
+				}
+				t.Errorf("unexpected request %s %s", req.Method, req.URL.Path)
+				return http.StatusNotFound, `{}`
+			})
+
+			err := searching.CheckIndexMapping(ctx, client, "articles", testMapping("2"))
+			if tt.mismatch {
+				assert.True(t, errors.Is(err, searching.ErrIndexMappingMismatch))
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
 }
 
 func TestNewElasticClient(t *testing.T) {
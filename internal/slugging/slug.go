@@ -0,0 +1,90 @@
+package slugging
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// transliterations are the ASCII spellings of the lowercase letters that do not
+// decompose into an ASCII letter and accents. They are looked up before and after
+// the decomposition, so a letter like й is not read as и with an accent.
+var transliterations = map[rune]string{
+	// Latin
+	'ß': "ss", 'æ': "ae", 'œ': "oe", 'ø': "o", 'ł': "l", 'đ': "d", 'ð': "d", 'þ': "th", 'ı': "i", 'ħ': "h", 'ŋ': "ng",
+	// Cyrillic
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'ґ': "g", 'д': "d", 'е': "e", 'ё': "e", 'є': "ye", 'ж': "zh", 'з': "z",
+	'и': "i", 'і': "i", 'ї': "yi", 'й': "y", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o", 'п': "p", 'р': "r",
+	'с': "s", 'т': "t", 'у': "u", 'ф': "f", 'х': "kh", 'ц': "ts", 'ч': "ch", 'ш': "sh", 'щ': "shch", 'ъ': "",
+	'ы': "y", 'ь': "", 'э': "e", 'ю': "yu", 'я': "ya",
+	// Greek
+	'α': "a", 'β': "v", 'γ': "g", 'δ': "d", 'ε': "e", 'ζ': "z", 'η': "i", 'θ': "th", 'ι': "i", 'κ': "k", 'λ': "l",
+	'μ': "m", 'ν': "n", 'ξ': "x", 'ο': "o", 'π': "p", 'ρ': "r", 'σ': "s", 'ς': "s", 'τ': "t", 'υ': "y", 'φ': "f",
+	'χ': "ch", 'ψ': "ps", 'ω': "o",
+}
+
+// Slugify returns the URL-safe slug of the text, its lowercase ASCII letters and digits
+// with the words separated by single hyphens.
+//
+// Accented letters lose their accents and the other letters of the Latin, Cyrillic and
+// Greek scripts are transliterated, apostrophes are dropped and any other character
+// separates words. The slug is cut to maxLength bytes at a word boundary if possible,
+// it is empty if the text has nothing to transliterate.
+func Slugify(text string, maxLength int) string {
+	var b strings.Builder
+	separate := false
+
+	write := func(s string) {
+		if s == "" {
+			return
+		}
+		if separate && b.Len() > 0 {
+			b.WriteByte('-')
+		}
+		separate = false
+		b.WriteString(s)
+	}
+
+	for _, r := range text {
+		r = unicode.ToLower(r)
+		if r == '\'' || r == '’' {
+			continue
+		}
+		if ascii, ok := transliterations[r]; ok {
+			write(ascii)
+			continue
+		}
+
+		// the compatibility decomposition splits the accents off the letters
+		// and turns the letter-like symbols into plain letters and digits
+		for _, d := range norm.NFKD.String(string(r)) {
+			d = unicode.ToLower(d)
+			ascii, ok := transliterations[d]
+			switch {
+			case d >= 'a' && d <= 'z', d >= '0' && d <= '9':
+				write(string(d))
+			case ok:
+				write(ascii)
+			case unicode.Is(unicode.Mn, d):
+			default:
+				separate = true
+			}
+		}
+	}
+
+	return truncate(b.String(), maxLength)
+}
+
+// truncate cuts the slug to maxLength bytes, at the last hyphen if there is one.
+func truncate(slug string, maxLength int) string {
+	if maxLength <= 0 || len(slug) <= maxLength {
+		return slug
+	}
+
+	slug = slug[:maxLength]
+	if i := strings.LastIndexByte(slug, '-'); i > 0 {
+		return slug[:i]
+	}
+	return strings.TrimSuffix(slug, "-")
+}
@@ -0,0 +1,40 @@
+package slugging_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/undercode99/article_service/internal/slugging"
+)
+
+func TestSlugify(t *testing.T) {
+	tests := []struct {
+		name      string
+		text      string
+		maxLength int
+		expected  string
+	}{
+		{name: "Words", text: "Hello, World!", expected: "hello-world"},
+		{name: "Separators collapsed", text: "  Go -- 1.21 ~ released  ", expected: "go-1-21-released"},
+		{name: "Apostrophes dropped", text: "Don't panic, it’s Go", expected: "dont-panic-its-go"},
+		{name: "Accents removed", text: "Crème brûlée à São Paulo", expected: "creme-brulee-a-sao-paulo"},
+		{name: "Combining accents", text: "Café olé", expected: "cafe-ole"},
+		{name: "Latin letters", text: "Straße Øresund Łódź", expected: "strasse-oresund-lodz"},
+		{name: "Cyrillic", text: "Привет, мир", expected: "privet-mir"},
+		{name: "Greek", text: "Καλημέρα κόσμε", expected: "kalimera-kosme"},
+		{name: "Compatibility forms", text: "Ｇｏ² ﬁles", expected: "go2-files"},
+		{name: "Nothing to transliterate", text: "你好 !!", expected: ""},
+		{name: "Cut at a word", text: "the quick brown fox", maxLength: 14, expected: "the-quick"},
+		{name: "Cut in a word", text: "supercalifragilistic", maxLength: 5, expected: "super"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			maxLength := tt.maxLength
+			if maxLength == 0 {
+				maxLength = 80
+			}
+			assert.Equal(t, tt.expected, slugging.Slugify(tt.text, maxLength))
+		})
+	}
+}